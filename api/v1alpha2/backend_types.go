@@ -0,0 +1,124 @@
+package v1alpha2
+
+import (
+	"github.com/fluxcd/pkg/apis/meta"
+)
+
+// S3BackendSpec configures the Terraform "s3" backend.
+type S3BackendSpec struct {
+	// Bucket is the name of the S3 bucket holding the state.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Bucket string `json:"bucket"`
+
+	// Key is the path of the state file in the bucket.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Key string `json:"key"`
+
+	// Region of the bucket.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Region string `json:"region"`
+
+	// Endpoint is a custom S3 endpoint, e.g. for S3-compatible object stores.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// UsePathStyle enables path-style URLs for the objects.
+	// +optional
+	UsePathStyle bool `json:"usePathStyle,omitempty"`
+
+	// DynamoDBTable is the name of the DynamoDB table used for state locking.
+	// +optional
+	DynamoDBTable string `json:"dynamodbTable,omitempty"`
+
+	// Encrypt enables server side encryption of the state file.
+	// +optional
+	Encrypt bool `json:"encrypt,omitempty"`
+
+	// RoleARN is the ARN of the IAM role to assume.
+	// +optional
+	RoleARN string `json:"roleArn,omitempty"`
+
+	// CredentialsSecretRef is the reference to a Secret in the same namespace as the
+	// Terraform object, with the "access_key", "secret_key" and optionally "token" keys.
+	// When not specified, the credentials of the runner environment are used.
+	// +optional
+	CredentialsSecretRef *meta.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+}
+
+// GCSBackendSpec configures the Terraform "gcs" backend.
+type GCSBackendSpec struct {
+	// Bucket is the name of the GCS bucket holding the state.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Bucket string `json:"bucket"`
+
+	// Prefix of the state files in the bucket.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// CredentialsSecretRef is the reference to a Secret in the same namespace as the
+	// Terraform object, with either the "credentials" key, holding a service account key in JSON,
+	// or the "access_token" key.
+	// When not specified, the credentials of the runner environment are used.
+	// +optional
+	CredentialsSecretRef *meta.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+}
+
+// AzureRMBackendSpec configures the Terraform "azurerm" backend.
+type AzureRMBackendSpec struct {
+	// StorageAccountName is the name of the storage account holding the state.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	StorageAccountName string `json:"storageAccountName"`
+
+	// ContainerName is the name of the blob container holding the state.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	ContainerName string `json:"containerName"`
+
+	// Key is the name of the state blob.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Key string `json:"key"`
+
+	// ResourceGroupName is the name of the resource group of the storage account.
+	// +optional
+	ResourceGroupName string `json:"resourceGroupName,omitempty"`
+
+	// SubscriptionID of the storage account.
+	// +optional
+	SubscriptionID string `json:"subscriptionId,omitempty"`
+
+	// TenantID used to authenticate with a service principal.
+	// +optional
+	TenantID string `json:"tenantId,omitempty"`
+
+	// ClientID used to authenticate with a service principal.
+	// +optional
+	ClientID string `json:"clientId,omitempty"`
+
+	// UseAzureADAuth enables Azure AD authentication to the storage account.
+	// +optional
+	UseAzureADAuth bool `json:"useAzureADAuth,omitempty"`
+
+	// CredentialsSecretRef is the reference to a Secret in the same namespace as the
+	// Terraform object, with one of the "access_key", "sas_token" or "client_secret" keys.
+	// When not specified, the credentials of the runner environment are used.
+	// +optional
+	CredentialsSecretRef *meta.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+}
+
+// PGBackendSpec configures the Terraform "pg" backend.
+type PGBackendSpec struct {
+	// SchemaName is the name of the schema holding the state.
+	// +optional
+	SchemaName string `json:"schemaName,omitempty"`
+
+	// ConnectionSecretRef is the reference to a Secret in the same namespace as the
+	// Terraform object, with the "conn_str" key, holding the PostgreSQL connection string.
+	// +required
+	ConnectionSecretRef meta.LocalObjectReference `json:"connectionSecretRef"`
+}
@@ -0,0 +1,109 @@
+package v1alpha2
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DiffAnnotation requests a diff, holding a JSON encoded DiffRequest. The configuration of the
+	// request is planned with the vars, backend and workspace of the object. The plan is neither
+	// saved nor applied.
+	DiffAnnotation = "diff.tf-controller/request"
+
+	// DiffRequestedByAnnotation holds the user who requested the diff. The admission policy
+	// shipped with the controller only accepts a new request from the user it names.
+	DiffRequestedByAnnotation = "diff.tf-controller/requested-by"
+
+	// DiffLabel labels the Secret holding the configuration of a diff with the name of the Terraform object.
+	DiffLabel = "infra.contrib.fluxcd.io/diff"
+
+	// DiffArtifactDataKey is the key of the tar.gz packaged configuration in the Secret of a diff request.
+	DiffArtifactDataKey = "artifact.tar.gz"
+
+	// DiffOutputDataKey is the key of the human readable plan of the last diff
+	// in the Secret named by DiffStatus.OutputSecretName.
+	DiffOutputDataKey = "plan"
+)
+
+// DiffRequest is a diff requested with the DiffAnnotation.
+type DiffRequest struct {
+	// ID identifies the request. A new ID requests a new diff.
+	ID string `json:"id"`
+
+	// ArtifactSecretName is the name of the Secret holding the packaged configuration to plan,
+	// in the namespace of the object.
+	ArtifactSecretName string `json:"artifactSecretName"`
+}
+
+// DiffStatus records the result of the last diff.
+type DiffStatus struct {
+	// ID is the id of the handled request.
+	ID string `json:"id"`
+
+	// RequestedBy is the user who requested the diff.
+	// +optional
+	RequestedBy string `json:"requestedBy,omitempty"`
+
+	// Succeeded is true when the configuration was planned without error.
+	Succeeded bool `json:"succeeded"`
+
+	// HasChanges is true when the plan of the configuration has changes.
+	// +optional
+	HasChanges bool `json:"hasChanges,omitempty"`
+
+	// Message is a human readable result of the diff.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// OutputSecretName is the name of the Secret holding the human readable plan.
+	// +optional
+	OutputSecretName string `json:"outputSecretName,omitempty"`
+
+	// HandledAt is the time the diff completed.
+	// +optional
+	HandledAt *metav1.Time `json:"handledAt,omitempty"`
+
+	// RejectedRequest is the value of the diff annotation when it could not be parsed,
+	// or lacked an id or an artifactSecretName. The same value is not reported again.
+	// +optional
+	RejectedRequest string `json:"rejectedRequest,omitempty"`
+}
+
+// DiffArtifactSecretName returns the name of the Secret holding the configuration of a diff
+// of a Terraform object.
+func DiffArtifactSecretName(name string, id string) string {
+	return fmt.Sprintf("tfdiff-%s-%s", name, id)
+}
+
+// DiffOutputSecretName returns the name of the Secret holding the human readable plan of
+// the last diff of a Terraform object.
+func DiffOutputSecretName(name string) string {
+	return fmt.Sprintf("tfdiff-output-%s", name)
+}
+
+// GetDiffRequest returns the diff requested with the DiffAnnotation,
+// or nil if there is none, or it has already been handled.
+func (in Terraform) GetDiffRequest() (*DiffRequest, error) {
+	value, ok := in.GetAnnotations()[DiffAnnotation]
+	if !ok || value == "" {
+		return nil, nil
+	}
+	if in.Status.Diff != nil && in.Status.Diff.RejectedRequest == value {
+		return nil, nil
+	}
+
+	req := &DiffRequest{}
+	if err := json.Unmarshal([]byte(value), req); err != nil {
+		return nil, fmt.Errorf("unable to parse the %s annotation: %w", DiffAnnotation, err)
+	}
+	if in.Status.Diff != nil && in.Status.Diff.ID == req.ID {
+		return nil, nil
+	}
+	if req.ID == "" || req.ArtifactSecretName == "" {
+		return nil, fmt.Errorf("the %s annotation requires an id and an artifactSecretName", DiffAnnotation)
+	}
+	return req, nil
+}
@@ -0,0 +1,70 @@
+package v1alpha2
+
+const (
+	// DriftActionIgnore does not report the drift.
+	DriftActionIgnore = "Ignore"
+	// DriftActionAlert reports the drift, without correcting it.
+	DriftActionAlert = "Alert"
+	// DriftActionCorrect reports the drift, and corrects it when plans are applied automatically.
+	DriftActionCorrect = "Correct"
+)
+
+// DriftPolicySpec decides what to do with the drift of each resource found by drift detection.
+type DriftPolicySpec struct {
+	// Rules are matched in order against the changes of the drift detection plan.
+	// Each changed attribute of a resource takes the action of the first matching rule,
+	// and a resource takes the strongest action of its attributes: Correct, then Alert, then Ignore.
+	// +optional
+	Rules []DriftRule `json:"rules,omitempty"`
+
+	// Default is the action of the drift not matched by any rule. Defaults to Correct.
+	// +kubebuilder:validation:Enum=Ignore;Alert;Correct
+	// +kubebuilder:default:=Correct
+	// +optional
+	Default string `json:"default,omitempty"`
+}
+
+// DriftRule sets the action of the drift of matching resources.
+type DriftRule struct {
+	// Addresses are globs of resource addresses, where * matches any sequence of characters,
+	// e.g. aws_autoscaling_group.* or module.network.*.
+	// +kubebuilder:validation:MinItems=1
+	// +required
+	Addresses []string `json:"addresses"`
+
+	// Attributes limit the rule to the changes of these top level attributes, e.g. desired_capacity or tags.
+	// When empty, the rule matches any change of the resource, including its creation or deletion.
+	// +optional
+	Attributes []string `json:"attributes,omitempty"`
+
+	// Action is the action of the matched drift.
+	// +kubebuilder:validation:Enum=Ignore;Alert;Correct
+	// +required
+	Action string `json:"action"`
+}
+
+// GetDefault returns the action of the drift not matched by any rule.
+func (in *DriftPolicySpec) GetDefault() string {
+	if in == nil || in.Default == "" {
+		return DriftActionCorrect
+	}
+	return in.Default
+}
+
+// DriftStatus records the drifted resources of the last drift detection, by action of the drift policy.
+type DriftStatus struct {
+	// Revision is the source revision the drift was detected at.
+	Revision string `json:"revision,omitempty"`
+
+	// Correct are the addresses of the drifted resources to correct.
+	// +optional
+	Correct []string `json:"correct,omitempty"`
+
+	// Alert are the addresses of the drifted resources only reported.
+	// +optional
+	Alert []string `json:"alert,omitempty"`
+
+	// Ignore are the addresses of the drifted resources ignored.
+	// +optional
+	Ignore []string `json:"ignore,omitempty"`
+}
@@ -0,0 +1,64 @@
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DriftReportDataKey is the key of the drift report in its ConfigMap.
+	DriftReportDataKey = "report.json"
+
+	// DriftReportMaxSize bounds the size of a drift report, well below the 1MiB limit of ConfigMaps.
+	DriftReportMaxSize = 512 * 1024
+
+	// DriftReportMaxValueLength bounds the length of each value in a drift report.
+	DriftReportMaxValueLength = 1024
+
+	// DriftReportSensitiveValue replaces the sensitive values in a drift report.
+	DriftReportSensitiveValue = "(sensitive value)"
+
+	// DriftReportUnknownValue replaces the values only known after apply in a drift report.
+	DriftReportUnknownValue = "(known after apply)"
+)
+
+// DriftReport is the structured report of the drift found by drift detection.
+type DriftReport struct {
+	// Revision is the source revision the drift was detected at.
+	Revision string `json:"revision"`
+
+	// DetectedAt is when the drift was detected.
+	DetectedAt metav1.Time `json:"detectedAt"`
+
+	// Resources are the drifted resources.
+	Resources []DriftedResource `json:"resources"`
+
+	// Truncated is true when resources or values were left out to bound the size of the report.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// DriftedResource is a drifted resource and the changes to correct it.
+type DriftedResource struct {
+	// Address is the address of the resource.
+	Address string `json:"address"`
+
+	// Actions are the actions of the plan to correct the drift, e.g. update, or delete and create.
+	Actions []string `json:"actions"`
+
+	// PolicyAction is the action of the drift policy for the resource: Ignore, Alert or Correct.
+	PolicyAction string `json:"policyAction,omitempty"`
+
+	// Attributes are the changed top level attributes.
+	Attributes []DriftedAttribute `json:"attributes,omitempty"`
+}
+
+// DriftedAttribute is a changed attribute, with its values encoded in JSON.
+type DriftedAttribute struct {
+	Name   string `json:"name"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// DriftReportConfigMapName returns the name of the ConfigMap holding the drift report of a Terraform object.
+func DriftReportConfigMapName(name string) string {
+	return "tfdrift-report-" + name
+}
@@ -0,0 +1,63 @@
+package v1alpha2
+
+// ImportSpec declares an existing resource to import into the state.
+type ImportSpec struct {
+	// Address is the resource address to import to, e.g. aws_s3_bucket.bucket.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Address string `json:"address"`
+
+	// ID is the provider specific ID of the resource to import.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	ID string `json:"id"`
+}
+
+// MoveSpec declares a resource moved to another address.
+type MoveSpec struct {
+	// From is the previous address of the resource or module.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	From string `json:"from"`
+
+	// To is the new address of the resource or module.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	To string `json:"to"`
+}
+
+// PendingImports returns the imports of the spec that have not been applied yet.
+func (in Terraform) PendingImports() []ImportSpec {
+	var pending []ImportSpec
+	for _, i := range in.Spec.Imports {
+		applied := false
+		for _, a := range in.Status.AppliedImports {
+			if a == i {
+				applied = true
+				break
+			}
+		}
+		if !applied {
+			pending = append(pending, i)
+		}
+	}
+	return pending
+}
+
+// PendingMoves returns the moves of the spec that have not been applied yet.
+func (in Terraform) PendingMoves() []MoveSpec {
+	var pending []MoveSpec
+	for _, m := range in.Spec.Moves {
+		applied := false
+		for _, a := range in.Status.AppliedMoves {
+			if a == m {
+				applied = true
+				break
+			}
+		}
+		if !applied {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
@@ -0,0 +1,120 @@
+package v1alpha2
+
+import (
+	"github.com/fluxcd/pkg/apis/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ApprovalPolicyConfigMapName is the name of the ConfigMap holding the approval policy
+	// of all the Terraform objects in its namespace.
+	ApprovalPolicyConfigMapName = "tf-approval-policy"
+	// ApprovalPolicyRequiredApprovalsKey is the key of the number of required approvals in the approval policy ConfigMap.
+	ApprovalPolicyRequiredApprovalsKey = "requiredApprovals"
+	// ApprovalPolicyApproversKey is the key of the comma separated approvers in the approval policy ConfigMap.
+	ApprovalPolicyApproversKey = "approvers"
+	// ApprovalPolicyApproverGroupsKey is the key of the comma separated approver groups in the approval policy ConfigMap.
+	ApprovalPolicyApproverGroupsKey = "approverGroups"
+)
+
+// ApprovalPolicySpec requires a plan to be approved by a number of distinct approvers,
+// with PlanApproval objects, before it is applied.
+type ApprovalPolicySpec struct {
+	// RequiredApprovals is the number of distinct approvers required to apply a plan.
+	// +kubebuilder:validation:Minimum=1
+	// +required
+	RequiredApprovals int `json:"requiredApprovals"`
+
+	// Approvers are the names of the users allowed to approve a plan.
+	// +optional
+	Approvers []string `json:"approvers,omitempty"`
+
+	// ApproverGroups are the groups whose members are allowed to approve a plan.
+	// When both approvers and approverGroups are empty, anybody allowed to create
+	// PlanApproval objects in the namespace may approve.
+	// +optional
+	ApproverGroups []string `json:"approverGroups,omitempty"`
+}
+
+// PlanApprovalSpec is the approval of a plan by a user.
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="a plan approval is immutable"
+type PlanApprovalSpec struct {
+	// TerraformRef is the Terraform object of the plan, in the namespace of the approval.
+	// +required
+	TerraformRef meta.LocalObjectReference `json:"terraformRef"`
+
+	// PlanID is the id of the approved plan, e.g. plan-main-b8e362c206.
+	// Only the plan with this exact id is approved.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	PlanID string `json:"planId"`
+
+	// Approver is the name of the user approving the plan. The plan approval admission policy
+	// rejects the approvals whose approver is not the user creating them.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Approver string `json:"approver"`
+
+	// Groups are the groups of the approver, matched against the approver groups of the approval policy.
+	// The plan approval admission policy rejects the groups the user creating the approval is not a member of.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=tfapproval
+// +kubebuilder:printcolumn:name="Terraform",type="string",JSONPath=".spec.terraformRef.name",description=""
+// +kubebuilder:printcolumn:name="Plan",type="string",JSONPath=".spec.planId",description=""
+// +kubebuilder:printcolumn:name="Approver",type="string",JSONPath=".spec.approver",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// PlanApproval is the Schema for the planapprovals API
+type PlanApproval struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PlanApprovalSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PlanApprovalList contains a list of PlanApproval
+type PlanApprovalList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PlanApproval `json:"items"`
+}
+
+// ApprovalStatus records the approvals of a plan.
+type ApprovalStatus struct {
+	// PlanID is the id of the approved plan.
+	PlanID string `json:"planId"`
+
+	// RequiredApprovals is the number of distinct approvers required to apply the plan.
+	RequiredApprovals int `json:"requiredApprovals"`
+
+	// Approvals are the approvals of the plan by the allowed approvers, in order.
+	// +optional
+	Approvals []PlanApprovalRecord `json:"approvals,omitempty"`
+}
+
+// PlanApprovalRecord is the approval of a plan by an approver.
+type PlanApprovalRecord struct {
+	// Approver is the name of the user who approved the plan.
+	Approver string `json:"approver"`
+
+	// ApprovedAt is when the plan was approved.
+	ApprovedAt metav1.Time `json:"approvedAt"`
+
+	// PlanApprovalName is the name of the PlanApproval object.
+	PlanApprovalName string `json:"planApprovalName"`
+}
+
+// Approved returns true if the plan is approved by the required number of approvers.
+func (in *ApprovalStatus) Approved() bool {
+	return in != nil && len(in.Approvals) >= in.RequiredApprovals
+}
+
+func init() {
+	SchemeBuilder.Register(&PlanApproval{}, &PlanApprovalList{})
+}
@@ -110,10 +110,12 @@ type HealthCheck struct {
 	// +required
 	Name string `json:"name"`
 
-	// Type of the health check, valid values are ('tcp', 'http').
-	// If tcp is specified, address is required.
+	// Type of the health check, valid values are ('tcp', 'http', 'dns', 'grpc', 'tls', 'kubernetes').
+	// If tcp, grpc or tls is specified, address is required.
 	// If http is specified, url is required.
-	// +kubebuilder:validation:Enum=tcp;http
+	// If dns is specified, address is the host name to resolve.
+	// If kubernetes is specified, kubernetesObject is required.
+	// +kubebuilder:validation:Enum=tcp;http;dns;grpc;tls;kubernetes
 	// +required
 	Type string `json:"type"`
 
@@ -135,6 +137,144 @@ type HealthCheck struct {
 	// +kubebuilder:default="20s"
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// HTTP holds the expectations of an http health check.
+	// When not specified, a single request is sent and any 2xx or 3xx status code is accepted.
+	// +optional
+	HTTP *HTTPHealthCheck `json:"http,omitempty"`
+
+	// DNS holds the expectations of a dns health check.
+	// +optional
+	DNS *DNSHealthCheck `json:"dns,omitempty"`
+
+	// GRPC holds the settings of a grpc health check.
+	// +optional
+	GRPC *GRPCHealthCheck `json:"grpc,omitempty"`
+
+	// TLS holds the expectations of a tls health check.
+	// +optional
+	TLS *TLSHealthCheck `json:"tls,omitempty"`
+
+	// KubernetesObject is the object checked by a kubernetes health check.
+	// +optional
+	KubernetesObject *KubernetesObjectHealthCheck `json:"kubernetesObject,omitempty"`
+}
+
+// HTTPHealthCheck holds the expectations on the response of an http health check.
+// The request is repeated every interval until successThreshold consecutive
+// responses meet all the expectations, or the timeout of the health check elapses.
+type HTTPHealthCheck struct {
+	// ExpectedStatusCodes is the list of accepted status codes.
+	// Defaults to any 2xx or 3xx status code.
+	// +optional
+	ExpectedStatusCodes []int `json:"expectedStatusCodes,omitempty"`
+
+	// ExpectedHeaders maps the names of required response headers to regular expressions
+	// their value must match. An empty expression only requires the header to be present.
+	// +optional
+	ExpectedHeaders map[string]string `json:"expectedHeaders,omitempty"`
+
+	// BodyRegex is a regular expression the response body must match.
+	// +optional
+	BodyRegex string `json:"bodyRegex,omitempty"`
+
+	// BodyJSONPath is a JSONPath expression evaluated on the JSON response body (e.g. {.status}).
+	// It must return a non-empty result.
+	// +optional
+	BodyJSONPath string `json:"bodyJSONPath,omitempty"`
+
+	// BodyJSONPathValue is the value BodyJSONPath must return.
+	// +optional
+	BodyJSONPathValue string `json:"bodyJSONPathValue,omitempty"`
+
+	// SuccessThreshold is the number of consecutive successful responses required. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	SuccessThreshold int `json:"successThreshold,omitempty"`
+
+	// Interval between two requests. Defaults to 2s.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+func (in HTTPHealthCheck) GetSuccessThreshold() int {
+	if in.SuccessThreshold > 0 {
+		return in.SuccessThreshold
+	}
+	return 1
+}
+
+func (in HTTPHealthCheck) GetInterval() time.Duration {
+	if in.Interval != nil {
+		return in.Interval.Duration
+	}
+	return 2 * time.Second
+}
+
+// DNSHealthCheck checks that a host name resolves, optionally to the expected records.
+type DNSHealthCheck struct {
+	// RecordType is the type of the records to look up.
+	// +kubebuilder:validation:Enum=A;AAAA;CNAME;TXT
+	// +kubebuilder:default=A
+	// +optional
+	RecordType string `json:"recordType,omitempty"`
+
+	// ExpectedRecords must all be found in the lookup result.
+	// Go template can be used to reference values from the terraform output.
+	// When empty, any record is accepted.
+	// +optional
+	ExpectedRecords []string `json:"expectedRecords,omitempty"`
+}
+
+// GRPCHealthCheck checks a server implementing the gRPC health checking protocol.
+type GRPCHealthCheck struct {
+	// Service is the name of the service to check. When empty, the overall server health is checked.
+	// +optional
+	Service string `json:"service,omitempty"`
+
+	// TLS enables TLS when connecting to the server.
+	// +optional
+	TLS bool `json:"tls,omitempty"`
+}
+
+// TLSHealthCheck checks the certificate served on an address.
+type TLSHealthCheck struct {
+	// ServerName is used to verify the host name of the certificate.
+	// Defaults to the host of the address.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// MinValidity is the minimum remaining validity of the certificate.
+	// The check fails when the certificate expires sooner.
+	// +optional
+	MinValidity *metav1.Duration `json:"minValidity,omitempty"`
+}
+
+// KubernetesObjectHealthCheck checks the readiness of an object of the cluster
+// the controller runs in, for example a custom resource created by the Terraform module.
+type KubernetesObjectHealthCheck struct {
+	// API version of the object.
+	// +required
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the object.
+	// +required
+	Kind string `json:"kind"`
+
+	// Name of the object.
+	// Go template can be used to reference values from the terraform output.
+	// +required
+	Name string `json:"name"`
+
+	// Namespace of the object, defaults to the namespace of the Terraform object.
+	// Go template can be used to reference values from the terraform output.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// ConditionType is the type of the status condition that must be True.
+	// +kubebuilder:default=Ready
+	// +optional
+	ConditionType string `json:"conditionType,omitempty"`
 }
 
 type RunnerPodTemplate struct {
@@ -230,6 +370,10 @@ func (in HealthCheck) GetTimeout() time.Duration {
 }
 
 const (
-	HealthCheckTypeTCP     = "tcp"
-	HealthCheckTypeHttpGet = "http"
+	HealthCheckTypeTCP        = "tcp"
+	HealthCheckTypeHttpGet    = "http"
+	HealthCheckTypeDNS        = "dns"
+	HealthCheckTypeGRPC       = "grpc"
+	HealthCheckTypeTLS        = "tls"
+	HealthCheckTypeKubernetes = "kubernetes"
 )
@@ -0,0 +1,61 @@
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ChangeFreezeReasonKey is the key of the reason of a change freeze in the freeze ConfigMap.
+	ChangeFreezeReasonKey = "reason"
+	// ChangeFreezeUntilKey is the key of the RFC3339 end of a change freeze in the freeze ConfigMap.
+	// Without it, the freeze lasts until the ConfigMap is deleted.
+	ChangeFreezeUntilKey = "until"
+)
+
+// ScheduleSpec restricts when plans are applied automatically. Plans and drift detection
+// keep running at every interval, but auto-applies are held outside the maintenance windows
+// and during blackout periods, until the next window opens.
+type ScheduleSpec struct {
+	// Windows are the maintenance windows in which plans are applied automatically.
+	// When empty, plans are applied at any time outside the blackout periods.
+	// +optional
+	Windows []MaintenanceWindow `json:"windows,omitempty"`
+
+	// Blackouts are periods in which no plan is applied automatically,
+	// even inside a maintenance window.
+	// +optional
+	Blackouts []BlackoutPeriod `json:"blackouts,omitempty"`
+
+	// TimeZone is the IANA time zone of the cron expressions of the windows. Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// MaintenanceWindow is a recurring period in which plans are applied automatically.
+type MaintenanceWindow struct {
+	// Cron is a five field cron expression of the times the window opens, e.g. "0 22 * * 1-5".
+	// +kubebuilder:validation:Pattern=`^[0-9*,/-]+ +[0-9*,/-]+ +[0-9*,/?-]+ +[0-9A-Za-z*,/-]+ +[0-9A-Za-z*,/?-]+$`
+	// +required
+	Cron string `json:"cron"`
+
+	// Duration is how long the window stays open.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +required
+	Duration metav1.Duration `json:"duration"`
+}
+
+// BlackoutPeriod is a period in which no plan is applied automatically.
+type BlackoutPeriod struct {
+	// Start is the beginning of the period.
+	// +required
+	Start metav1.Time `json:"start"`
+
+	// End is the end of the period.
+	// +required
+	End metav1.Time `json:"end"`
+
+	// Reason is a human readable reason of the blackout, reported in the conditions.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
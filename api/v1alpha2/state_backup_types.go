@@ -0,0 +1,83 @@
+package v1alpha2
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// StateBackupLabel labels the state snapshots with their Terraform object,
+	// see StateBackupLabelValue.
+	StateBackupLabel = "infra.contrib.fluxcd.io/state-backup"
+	// StateBackupTriggerLabel labels the state snapshots with the operation they were taken before.
+	StateBackupTriggerLabel = "infra.contrib.fluxcd.io/state-backup-trigger"
+
+	// StateBackupNameAnnotation holds the name of the Terraform object of a state snapshot,
+	// as the StateBackupLabel only holds a truncated name for the long names.
+	StateBackupNameAnnotation      = "infra.contrib.fluxcd.io/state-backup-name"
+	StateBackupWorkspaceAnnotation = "infra.contrib.fluxcd.io/workspace"
+	StateBackupRevisionAnnotation  = "infra.contrib.fluxcd.io/revision"
+	StateBackupCreatedAtAnnotation = "infra.contrib.fluxcd.io/created-at"
+	StateBackupSerialAnnotation    = "infra.contrib.fluxcd.io/serial"
+
+	// StateBackupDataKey is the key of the gzipped state in the state snapshot Secrets.
+	StateBackupDataKey = "tfstate"
+
+	StateBackupTriggerApply        = "apply"
+	StateBackupTriggerMigrateState = "migrate-state"
+	StateBackupTriggerRestore      = "restore"
+
+	StateBackupStoreSecret = "Secret"
+
+	DefaultStateBackupRetention = 10
+)
+
+// StateBackupSpec configures the snapshots of the state taken before every apply.
+type StateBackupSpec struct {
+	// Retention is the number of snapshots to keep. The oldest snapshots are deleted first.
+	// Snapshots taken before a state migration are not counted.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default:=10
+	// +optional
+	Retention int `json:"retention,omitempty"`
+
+	// Store is where the snapshots are kept. Secret stores each snapshot
+	// in a Secret in the namespace of the Terraform object.
+	// +kubebuilder:validation:Enum=Secret
+	// +kubebuilder:default:=Secret
+	// +optional
+	Store string `json:"store,omitempty"`
+}
+
+// GetRetention returns the number of snapshots to keep.
+func (in *StateBackupSpec) GetRetention() int {
+	if in == nil || in.Retention <= 0 {
+		return DefaultStateBackupRetention
+	}
+	return in.Retention
+}
+
+// GetStore returns the store of the snapshots.
+func (in *StateBackupSpec) GetStore() string {
+	if in == nil || in.Store == "" {
+		return StateBackupStoreSecret
+	}
+	return in.Store
+}
+
+// StateBackupLabelValue returns the value of the StateBackupLabel for the given Terraform object name.
+// A name longer than a label value is truncated and suffixed with a hash of the full name.
+func StateBackupLabelValue(name string) string {
+	if len(validation.IsValidLabelValue(name)) == 0 {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	hash := hex.EncodeToString(sum[:])[:10]
+	prefix := name[:min(len(name), validation.LabelValueMaxLength-len(hash)-1)]
+	return strings.TrimRight(prefix, "-.") + "-" + hash
+}
@@ -0,0 +1,22 @@
+package v1alpha2
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestStateBackupLabelValue(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(StateBackupLabelValue("my-resource")).To(Equal("my-resource"))
+
+	long := strings.Repeat("a", 60) + ".b-" + strings.Repeat("c", 100)
+	value := StateBackupLabelValue(long)
+	g.Expect(validation.IsValidLabelValue(value)).To(BeEmpty())
+	g.Expect(value).To(HavePrefix(strings.Repeat("a", 52) + "-"))
+	g.Expect(StateBackupLabelValue(long)).To(Equal(value))
+	g.Expect(StateBackupLabelValue(long + "d")).ToNot(Equal(value))
+}
@@ -0,0 +1,155 @@
+package v1alpha2
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// StateOperationAnnotation requests a state operation, holding a JSON encoded StateOperationRequest.
+	// The operation is executed by the runner against the configured backend and workspace.
+	StateOperationAnnotation = "state-operation.tf-controller/request"
+
+	// StateOperationRequestedByAnnotation holds the user who requested the state operation. The admission
+	// policy shipped with the controller only accepts a new request from the user it names.
+	StateOperationRequestedByAnnotation = "state-operation.tf-controller/requested-by"
+
+	StateOperationList   = "list"
+	StateOperationShow   = "show"
+	StateOperationMv     = "mv"
+	StateOperationRm     = "rm"
+	StateOperationImport = "import"
+
+	// StateOperationOutputDataKey is the key of the output of the last state operation
+	// in the Secret named by StateOperationStatus.OutputSecretName.
+	StateOperationOutputDataKey = "output"
+)
+
+// StateOperationRequest is a state operation requested with the StateOperationAnnotation.
+type StateOperationRequest struct {
+	// ID identifies the request. A new ID requests a new operation.
+	ID string `json:"id"`
+
+	// Operation is one of list, show, mv, rm or import.
+	Operation string `json:"operation"`
+
+	// Args are the arguments of the operation, as they would be given to terraform.
+	// +optional
+	Args []string `json:"args,omitempty"`
+}
+
+// Validate checks the operation and the number of its arguments. The arguments are addresses
+// and ids, never flags, as they are given to terraform as is.
+func (in StateOperationRequest) Validate() error {
+	if in.ID == "" {
+		return fmt.Errorf("state operation request has no id")
+	}
+	for _, arg := range in.Args {
+		if strings.HasPrefix(arg, "-") {
+			return fmt.Errorf("invalid argument %q, state operations do not accept flags", arg)
+		}
+	}
+
+	switch in.Operation {
+	case StateOperationList:
+		return nil
+	case StateOperationShow:
+		if len(in.Args) != 1 {
+			return fmt.Errorf("state show expects exactly one address, got %d arguments", len(in.Args))
+		}
+	case StateOperationMv:
+		if len(in.Args) != 2 {
+			return fmt.Errorf("state mv expects a source and a destination address, got %d arguments", len(in.Args))
+		}
+	case StateOperationRm:
+		if len(in.Args) == 0 {
+			return fmt.Errorf("state rm expects at least one address")
+		}
+	case StateOperationImport:
+		if len(in.Args) != 2 {
+			return fmt.Errorf("import expects an address and an id, got %d arguments", len(in.Args))
+		}
+	default:
+		return fmt.Errorf("unknown state operation %q", in.Operation)
+	}
+	return nil
+}
+
+// String returns the operation as it would be typed on the terraform command line.
+func (in StateOperationRequest) String() string {
+	cmd := "state " + in.Operation
+	if in.Operation == StateOperationImport {
+		cmd = in.Operation
+	}
+	for _, arg := range in.Args {
+		cmd = cmd + " " + arg
+	}
+	return cmd
+}
+
+// StateOperationStatus records the result of the last state operation.
+type StateOperationStatus struct {
+	// ID is the id of the handled request.
+	ID string `json:"id"`
+
+	// Operation is the handled operation.
+	Operation string `json:"operation"`
+
+	// Args are the arguments of the handled operation.
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// RequestedBy is the user who requested the operation.
+	// +optional
+	RequestedBy string `json:"requestedBy,omitempty"`
+
+	// Succeeded is true when the operation completed without error.
+	Succeeded bool `json:"succeeded"`
+
+	// Message is a human readable result of the operation.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// OutputSecretName is the name of the Secret holding the output of the operation.
+	// +optional
+	OutputSecretName string `json:"outputSecretName,omitempty"`
+
+	// HandledAt is the time the operation completed.
+	// +optional
+	HandledAt *metav1.Time `json:"handledAt,omitempty"`
+
+	// RejectedRequest is the value of the state operation annotation when it could not
+	// be parsed. The same value is not reported again.
+	// +optional
+	RejectedRequest string `json:"rejectedRequest,omitempty"`
+}
+
+// StateOperationOutputSecretName returns the name of the Secret holding the output of
+// the last state operation of a Terraform object.
+func StateOperationOutputSecretName(name string) string {
+	return fmt.Sprintf("tfstate-operation-%s", name)
+}
+
+// GetStateOperationRequest returns the state operation requested with the StateOperationAnnotation,
+// or nil if there is none, or it has already been handled.
+func (in Terraform) GetStateOperationRequest() (*StateOperationRequest, error) {
+	value, ok := in.GetAnnotations()[StateOperationAnnotation]
+	if !ok || value == "" {
+		return nil, nil
+	}
+	if in.Status.StateOperation != nil && in.Status.StateOperation.RejectedRequest == value {
+		return nil, nil
+	}
+
+	req := &StateOperationRequest{}
+	if err := json.Unmarshal([]byte(value), req); err != nil {
+		return nil, fmt.Errorf("unable to parse the %s annotation: %w", StateOperationAnnotation, err)
+	}
+	if in.Status.StateOperation != nil && in.Status.StateOperation.ID == req.ID {
+		return nil, nil
+	}
+	return req, nil
+}
@@ -47,6 +47,19 @@ const (
 	BucketIndexKey          = ".metadata.bucket"
 	OCIRepositoryIndexKey   = ".metadata.ociRepository"
 	BreakTheGlassAnnotation = "break-the-glass.tf-controller/requestedAt"
+
+	// CascadeDestroyAnnotation requests the deletion of an object together with
+	// all of its dependants. Dependants are destroyed first, leaves before their
+	// dependencies, and the annotation is propagated down the dependency graph.
+	CascadeDestroyAnnotation = "cascade-destroy.tf-controller/requestedAt"
+
+	// MigrateStateAnnotation requests the migration of the state from the backend recorded
+	// in the status to the backend of the spec. A new value requests a new migration.
+	MigrateStateAnnotation = "migrate-state.tf-controller/requestedAt"
+
+	// SkipStateMigrationAnnotation acknowledges a backend change without migrating the state,
+	// so that the new backend is initialized as is. A new value acknowledges a new change.
+	SkipStateMigrationAnnotation = "skip-state-migration.tf-controller/requestedAt"
 )
 
 type ReadInputsFromSecretSpec struct {
@@ -97,6 +110,20 @@ type TerraformSpec struct {
 	// +optional
 	ApprovePlan string `json:"approvePlan,omitempty"`
 
+	// ApprovalPolicy requires the plans to be approved by a number of distinct approvers,
+	// with PlanApproval objects, before they are applied. It comes on top of the approval
+	// of approvePlan, and of the approval policy of the namespace.
+	// +optional
+	ApprovalPolicy *ApprovalPolicySpec `json:"approvalPolicy,omitempty"`
+
+	// PlanTTL is how long a pending plan waits for its approval. Once expired, the plan is discarded
+	// and planned again, and the approvals of the expired plan are rejected.
+	// Plans approved, or applied automatically, do not expire. Defaults to no expiry.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	PlanTTL *metav1.Duration `json:"planTTL,omitempty"`
+
 	// Destroy produces a destroy plan. Applying the plan will destroy all resources.
 	// +optional
 	Destroy bool `json:"destroy,omitempty"`
@@ -195,6 +222,17 @@ type TerraformSpec struct {
 	// +optional
 	DisableDriftDetection bool `json:"disableDriftDetection,omitempty"`
 
+	// The interval at which to detect drift, when it should be less frequent than the reconciliation,
+	// e.g. to check new revisions every minute but refresh the resources hourly.
+	// Defaults to detecting drift at every reconciliation.
+	// +optional
+	DriftDetectionInterval *metav1.Duration `json:"driftDetectionInterval,omitempty"`
+
+	// DriftPolicy decides, per resource address and attribute, whether detected drift
+	// is ignored, only reported, or corrected. Without it, all drift is corrected.
+	// +optional
+	DriftPolicy *DriftPolicySpec `json:"driftPolicy,omitempty"`
+
 	// +optional
 	// PushSpec *PushSpec `json:"pushSpec,omitempty"`
 
@@ -244,6 +282,27 @@ type TerraformSpec struct {
 	// +optional
 	TFState *TFStateSpec `json:"tfstate,omitempty"`
 
+	// StateBackup enables a snapshot of the state before every apply,
+	// keeping a limited history of snapshots to restore from.
+	// +optional
+	StateBackup *StateBackupSpec `json:"stateBackup,omitempty"`
+
+	// Schedule restricts the automatic applies to maintenance windows, outside blackout periods.
+	// Plans and drift detection keep running at every interval.
+	// +optional
+	Schedule *ScheduleSpec `json:"schedule,omitempty"`
+
+	// Imports declare existing resources to import into the state. They are rendered as
+	// import blocks in the working directory, and appear in the plan like any other change.
+	// Imports are no longer rendered once applied. Requires Terraform 1.5 or later.
+	// +optional
+	Imports []ImportSpec `json:"imports,omitempty"`
+
+	// Moves declare resources moved to another address. They are rendered as moved blocks
+	// in the working directory, and are no longer rendered once applied.
+	// +optional
+	Moves []MoveSpec `json:"moves,omitempty"`
+
 	// Targets specify the resource, module or collection of resources to target.
 	// +optional
 	Targets []string `json:"targets,omitempty"`
@@ -328,7 +387,7 @@ type CloudWorkspacesSpec struct {
 }
 
 type Webhook struct {
-	// +kubebuilder:validation:Enum=post-planning
+	// +kubebuilder:validation:Enum=post-planning;pre-apply;post-apply
 	// +kubebuilder:default:=post-planning
 	// +required
 	Stage string `json:"stage"`
@@ -350,12 +409,51 @@ type Webhook struct {
 
 	// +required
 	TestExpression string `json:"testExpression,omitempty"`
+
+	// SecretRef is the reference to a Secret, in the same namespace as the Terraform object,
+	// holding the credentials used to call the webhook.
+	// The "hmacKey" key is used to sign the payload with HMAC-SHA256, the signature is sent
+	// in the X-Signature-256 header. The "token" key is sent as a bearer token.
+	// The "tls.crt", "tls.key" and "ca.crt" keys are used for mTLS.
+	// +optional
+	SecretRef *meta.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// Timeout of each call to the webhook. Defaults to 30s.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Retries is the number of times a failed call is retried.
+	// Calls are retried on connection errors, 429 and 5xx responses, until the timeout
+	// has elapsed since the first call. Defaults to 0.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	Retries int `json:"retries,omitempty"`
+
+	// RetryInterval is the interval before the first retry, it is doubled after each retry.
+	// Defaults to 1s.
+	// +optional
+	RetryInterval *metav1.Duration `json:"retryInterval,omitempty"`
 }
 
 func (w Webhook) IsEnabled() bool {
 	return w.Enabled == nil || *w.Enabled
 }
 
+func (w Webhook) GetTimeout() time.Duration {
+	if w.Timeout != nil {
+		return w.Timeout.Duration
+	}
+	return 30 * time.Second
+}
+
+func (w Webhook) GetRetryInterval() time.Duration {
+	if w.RetryInterval != nil {
+		return w.RetryInterval.Duration
+	}
+	return time.Second
+}
+
 type PlanStatus struct {
 	// +optional
 	LastApplied string `json:"lastApplied,omitempty"`
@@ -403,6 +501,10 @@ type TerraformStatus struct {
 	// +optional
 	LastDriftDetectedAt *metav1.Time `json:"lastDriftDetectedAt,omitempty"`
 
+	// LastDriftDetectionAt is the time when drift detection last ran, whether or not drift was detected
+	// +optional
+	LastDriftDetectionAt *metav1.Time `json:"lastDriftDetectionAt,omitempty"`
+
 	// LastAppliedByDriftDetectionAt is the time when the last drift was detected and
 	// terraform apply was performed as a result
 	// +optional
@@ -425,6 +527,70 @@ type TerraformStatus struct {
 	// failures since the last success or update.
 	// +optional
 	ReconciliationFailures int64 `json:"reconciliationFailures,omitempty"`
+
+	// Backend is the backend the state was last initialized with.
+	// +optional
+	Backend *BackendStatus `json:"backend,omitempty"`
+
+	// LastHandledMigrateStateAt holds the value of the most recent
+	// migrate state or skip state migration request handled by the controller.
+	// +optional
+	LastHandledMigrateStateAt string `json:"lastHandledMigrateStateAt,omitempty"`
+
+	// StateOperation is the result of the last state operation
+	// requested with the state operation annotation.
+	// +optional
+	StateOperation *StateOperationStatus `json:"stateOperation,omitempty"`
+
+	// Diff is the result of the last diff requested with the diff annotation.
+	// +optional
+	Diff *DiffStatus `json:"diff,omitempty"`
+
+	// AppliedImports are the imports of the spec that have been applied.
+	// +optional
+	AppliedImports []ImportSpec `json:"appliedImports,omitempty"`
+
+	// AppliedMoves are the moves of the spec that have been applied.
+	// +optional
+	AppliedMoves []MoveSpec `json:"appliedMoves,omitempty"`
+
+	// Drift is the drift found by the last drift detection, classified by the drift policy.
+	// +optional
+	Drift *DriftStatus `json:"drift,omitempty"`
+
+	// DriftReportConfigMapName is the name of the ConfigMap holding the report of the drift
+	// found by the last drift detection, empty if there was no drift.
+	// +optional
+	DriftReportConfigMapName string `json:"driftReportConfigMapName,omitempty"`
+
+	// Approval records the approvals of the pending plan, when an approval policy applies.
+	// +optional
+	Approval *ApprovalStatus `json:"approval,omitempty"`
+
+	// ExpiredPlan is the last pending plan discarded after the plan TTL.
+	// +optional
+	ExpiredPlan *ExpiredPlanStatus `json:"expiredPlan,omitempty"`
+}
+
+// ExpiredPlanStatus records a pending plan discarded after the plan TTL.
+type ExpiredPlanStatus struct {
+	// PlanID is the id of the expired plan.
+	PlanID string `json:"planId"`
+
+	// GeneratedAt is when the expired plan was generated.
+	// +optional
+	GeneratedAt *metav1.Time `json:"generatedAt,omitempty"`
+
+	// ExpiredAt is when the plan expired.
+	ExpiredAt metav1.Time `json:"expiredAt"`
+}
+
+// BackendStatus records the backend configuration the state was last initialized with.
+type BackendStatus struct {
+	// Config is the backend configuration of the spec.
+	// When nil, the default kubernetes backend was used.
+	// +optional
+	Config *BackendConfigSpec `json:"config,omitempty"`
 }
 
 // LockStatus defines the observed state of a Terraform State Lock
@@ -485,6 +651,29 @@ type BackendConfigSpec struct {
 
 	// +optional
 	Labels map[string]string `json:"labels,omitempty"`
+
+	// S3 configures the s3 backend instead of the kubernetes backend.
+	// +optional
+	S3 *S3BackendSpec `json:"s3,omitempty"`
+
+	// GCS configures the gcs backend instead of the kubernetes backend.
+	// +optional
+	GCS *GCSBackendSpec `json:"gcs,omitempty"`
+
+	// AzureRM configures the azurerm backend instead of the kubernetes backend.
+	// +optional
+	AzureRM *AzureRMBackendSpec `json:"azurerm,omitempty"`
+
+	// PG configures the pg backend instead of the kubernetes backend.
+	// +optional
+	PG *PGBackendSpec `json:"pg,omitempty"`
+
+	// Sharded stores the state gzipped and split across multiple Secrets, for the states
+	// too large for a single Secret of the kubernetes backend. The state is served to Terraform
+	// by the runner, as an http backend. The workspace and the secretSuffix name the Secrets,
+	// as with the kubernetes backend.
+	// +optional
+	Sharded bool `json:"sharded,omitempty"`
 }
 
 // TFStateSpec allows the user to set ForceUnlock
@@ -528,6 +717,23 @@ type TFStateSpec struct {
 	// +optional
 	// +kubebuilder:default:string="0s"
 	LockTimeout metav1.Duration `json:"lockTimeout,omitempty"`
+
+	// SizeWarningPercent is the size of the state Secret of the kubernetes backend, in percent of
+	// the size limit of a Secret, above which a warning is reported after an apply. Defaults to 80.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	SizeWarningPercent int `json:"sizeWarningPercent,omitempty"`
+}
+
+// GetSizeWarningPercent returns the size of the state above which a warning is reported,
+// in percent of the size limit of a Secret.
+func (in *TFStateSpec) GetSizeWarningPercent() int {
+	if in == nil || in.SizeWarningPercent <= 0 {
+		return DefaultStateSizeWarningPercent
+	}
+	return in.SizeWarningPercent
 }
 
 type ForceUnlockEnum string
@@ -553,23 +759,47 @@ const (
 	ApprovePlanAutoValue      = "auto"
 	ApprovePlanDisableValue   = "disable"
 	DefaultWorkspaceName      = "default"
+	// MaxStateSecretSize is the size limit of the data of a Secret.
+	MaxStateSecretSize             = 1024 * 1024
+	DefaultStateSizeWarningPercent = 80
 )
 
 // The potential reasons that are associated with condition types
 const (
 	AccessDeniedReason              = "AccessDenied"
+	ApprovalPolicyInvalidReason     = "ApprovalPolicyInvalid"
+	ApprovalQuorumReachedReason     = "ApprovalQuorumReached"
+	AwaitingApprovalsReason         = "AwaitingApprovals"
 	ArtifactFailedReason            = "ArtifactFailed"
+	BlackoutPeriodReason            = "BlackoutPeriod"
+	CascadeDestroyInProgressReason  = "CascadeDestroyInProgress"
+	ChangeFreezeReason              = "ChangeFreeze"
+	OutsideMaintenanceWindowReason  = "OutsideMaintenanceWindow"
+	PlanExpiredReason               = "PlanExpired"
+	ScheduleInvalidReason           = "ScheduleInvalid"
+	WithinMaintenanceWindowReason   = "WithinMaintenanceWindow"
 	RetryLimitReachedReason         = "RetryLimitReached"
+	StateBackupFailedReason         = "StateBackupFailed"
+	StateMigrationFailedReason      = "StateMigrationFailed"
+	StateMigrationRequiredReason    = "StateMigrationRequired"
+	StateMigrationSkippedReason     = "StateMigrationSkipped"
+	StateMigrationSucceededReason   = "StateMigrationSucceeded"
+	StateSizeNearLimitReason        = "StateSizeNearLimit"
+	StateSizeWithinLimitReason      = "StateSizeWithinLimit"
 	DeletionBlockedByDependants     = "DeletionBlockedByDependantsReason"
 	DependencyNotReadyReason        = "DependencyNotReady"
 	DriftDetectedReason             = "DriftDetected"
 	DriftDetectionFailedReason      = "DriftDetectionFailed"
+	DriftIgnoredReason              = "DriftIgnored"
 	HealthChecksFailedReason        = "HealthChecksFailed"
 	NoDriftReason                   = "NoDrift"
 	OutputsWritingFailedReason      = "OutputsWritingFailed"
 	PlannedNoChangesReason          = "TerraformPlannedNoChanges"
 	PlannedWithChangesReason        = "TerraformPlannedWithChanges"
+	PostApplyWebhookFailedReason    = "PostApplyWebhookFailed"
+	PostApplyWebhookSucceededReason = "PostApplyWebhookSucceeded"
 	PostPlanningWebhookFailedReason = "PostPlanningWebhookFailed"
+	PreApplyWebhookFailedReason     = "PreApplyWebhookFailed"
 	TFExecApplyFailedReason         = "TFExecApplyFailed"
 	TFExecApplySucceedReason        = "TerraformAppliedSucceed"
 	TFExecForceUnlockReason         = "ForceUnlock"
@@ -585,16 +815,23 @@ const (
 
 // These constants are the Condition Types that the Terraform Resource works with
 const (
-	ConditionTypeApply       = "Apply"
-	ConditionTypeHealthCheck = "HealthCheck"
-	ConditionTypeOutput      = "Output"
-	ConditionTypePlan        = "Plan"
-	ConditionTypeStateLocked = "StateLocked"
+	ConditionTypeApply            = "Apply"
+	ConditionTypeApproval         = "Approval"
+	ConditionTypeHealthCheck      = "HealthCheck"
+	ConditionTypeOutput           = "Output"
+	ConditionTypePlan             = "Plan"
+	ConditionTypePostApplyWebhook = "PostApplyWebhook"
+	ConditionTypeSchedule         = "Schedule"
+	ConditionTypeStateLocked      = "StateLocked"
+	ConditionTypeStateMigration   = "StateMigration"
+	ConditionTypeStateSize        = "StateSize"
 )
 
 // Webhook stages
 const (
 	PostPlanningWebhook = "post-planning"
+	PreApplyWebhook     = "pre-apply"
+	PostApplyWebhook    = "post-apply"
 )
 
 const (
@@ -680,6 +917,12 @@ func TerraformApplied(terraform Terraform, revision string, message string, isDe
 		terraform.Status.Inventory = &ResourceInventory{Entries: entries}
 	}
 
+	terraform.Status.Drift = nil
+	if !isDestroyApply {
+		terraform.Status.AppliedImports = terraform.Spec.Imports
+		terraform.Status.AppliedMoves = terraform.Spec.Moves
+	}
+
 	SetTerraformReadiness(&terraform, metav1.ConditionUnknown, TFExecApplySucceedReason, message+": "+revision, revision)
 	return terraform
 }
@@ -705,6 +948,170 @@ func TerraformPostPlanningWebhookFailed(terraform Terraform, revision string, me
 	return terraform
 }
 
+// TerraformStateMigrationSucceeded records a successful state migration.
+func TerraformStateMigrationSucceeded(terraform Terraform, requestedAt string, message string) Terraform {
+	newCondition := metav1.Condition{
+		Type:    ConditionTypeStateMigration,
+		Status:  metav1.ConditionTrue,
+		Reason:  StateMigrationSucceededReason,
+		Message: trimString(message, MaxConditionMessageLength),
+	}
+	apimeta.SetStatusCondition(terraform.GetStatusConditions(), newCondition)
+	terraform.Status.LastHandledMigrateStateAt = requestedAt
+	return terraform
+}
+
+// TerraformStateMigrationFailed records a failed state migration. The request is marked as handled,
+// so the migration is attempted again only when the annotation is updated.
+func TerraformStateMigrationFailed(terraform Terraform, requestedAt string, message string) Terraform {
+	newCondition := metav1.Condition{
+		Type:    ConditionTypeStateMigration,
+		Status:  metav1.ConditionFalse,
+		Reason:  StateMigrationFailedReason,
+		Message: trimString(message, MaxConditionMessageLength),
+	}
+	apimeta.SetStatusCondition(terraform.GetStatusConditions(), newCondition)
+	terraform.Status.LastHandledMigrateStateAt = requestedAt
+	return terraform
+}
+
+// TerraformStateMigrationRequired records a backend change waiting for the state to be migrated,
+// or for the migration to be skipped.
+func TerraformStateMigrationRequired(terraform Terraform, message string) Terraform {
+	newCondition := metav1.Condition{
+		Type:    ConditionTypeStateMigration,
+		Status:  metav1.ConditionFalse,
+		Reason:  StateMigrationRequiredReason,
+		Message: trimString(message, MaxConditionMessageLength),
+	}
+	apimeta.SetStatusCondition(terraform.GetStatusConditions(), newCondition)
+	return terraform
+}
+
+// TerraformStateMigrationSkipped records a backend change acknowledged without migrating the state.
+func TerraformStateMigrationSkipped(terraform Terraform, requestedAt string, message string) Terraform {
+	newCondition := metav1.Condition{
+		Type:    ConditionTypeStateMigration,
+		Status:  metav1.ConditionTrue,
+		Reason:  StateMigrationSkippedReason,
+		Message: trimString(message, MaxConditionMessageLength),
+	}
+	apimeta.SetStatusCondition(terraform.GetStatusConditions(), newCondition)
+	terraform.Status.LastHandledMigrateStateAt = requestedAt
+	return terraform
+}
+
+// TerraformApplyHeld records a plan held by the schedule or a change freeze. The plan stays pending,
+// and is applied by a later reconciliation once the hold is lifted.
+func TerraformApplyHeld(terraform Terraform, revision string, reason string, message string) Terraform {
+	newCondition := metav1.Condition{
+		Type:    ConditionTypeSchedule,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: trimString(message, MaxConditionMessageLength),
+	}
+	apimeta.SetStatusCondition(terraform.GetStatusConditions(), newCondition)
+
+	SetTerraformReadiness(&terraform, metav1.ConditionUnknown, reason, message, revision)
+	return terraform
+}
+
+// TerraformApplyAllowed records that the schedule allows the pending plan to be applied.
+func TerraformApplyAllowed(terraform Terraform, message string) Terraform {
+	newCondition := metav1.Condition{
+		Type:    ConditionTypeSchedule,
+		Status:  metav1.ConditionTrue,
+		Reason:  WithinMaintenanceWindowReason,
+		Message: trimString(message, MaxConditionMessageLength),
+	}
+	apimeta.SetStatusCondition(terraform.GetStatusConditions(), newCondition)
+	return terraform
+}
+
+// TerraformApprovals records whether the pending plan is approved by the required number of approvers.
+func TerraformApprovals(terraform Terraform, status metav1.ConditionStatus, reason string, message string) Terraform {
+	newCondition := metav1.Condition{
+		Type:    ConditionTypeApproval,
+		Status:  status,
+		Reason:  reason,
+		Message: trimString(message, MaxConditionMessageLength),
+	}
+	apimeta.SetStatusCondition(terraform.GetStatusConditions(), newCondition)
+	return terraform
+}
+
+// TerraformPlanExpired discards the pending plan, after the plan TTL.
+func TerraformPlanExpired(terraform Terraform, revision string, message string) Terraform {
+	terraform.Status.ExpiredPlan = &ExpiredPlanStatus{
+		PlanID:      terraform.Status.Plan.Pending,
+		GeneratedAt: terraform.Status.LastPlanAt,
+		ExpiredAt:   metav1.Now(),
+	}
+	terraform.Status.Plan.Pending = ""
+
+	newCondition := metav1.Condition{
+		Type:    ConditionTypePlan,
+		Status:  metav1.ConditionFalse,
+		Reason:  PlanExpiredReason,
+		Message: trimString(message, MaxConditionMessageLength),
+	}
+	apimeta.SetStatusCondition(terraform.GetStatusConditions(), newCondition)
+	SetTerraformReadiness(&terraform, metav1.ConditionUnknown, PlanExpiredReason, message, revision)
+	return terraform
+}
+
+// TerraformStateSize records the size of the state, and whether it is near the size limit of a Secret.
+func TerraformStateSize(terraform Terraform, status metav1.ConditionStatus, reason string, message string) Terraform {
+	newCondition := metav1.Condition{
+		Type:    ConditionTypeStateSize,
+		Status:  status,
+		Reason:  reason,
+		Message: trimString(message, MaxConditionMessageLength),
+	}
+	apimeta.SetStatusCondition(terraform.GetStatusConditions(), newCondition)
+	return terraform
+}
+
+// TerraformPreApplyWebhookFailed marks the apply as rejected by a pre-apply webhook.
+// The pending plan is kept, so it is applied once the webhooks accept it.
+func TerraformPreApplyWebhookFailed(terraform Terraform, revision string, message string) Terraform {
+	newCondition := metav1.Condition{
+		Type:    ConditionTypeApply,
+		Status:  metav1.ConditionFalse,
+		Reason:  PreApplyWebhookFailedReason,
+		Message: trimString(message, MaxConditionMessageLength),
+	}
+	apimeta.SetStatusCondition(terraform.GetStatusConditions(), newCondition)
+
+	SetTerraformReadiness(&terraform, metav1.ConditionFalse, PreApplyWebhookFailedReason, message, revision)
+	return terraform
+}
+
+// TerraformPostApplyWebhookFailed records that the post-apply webhooks failed, or rejected the result
+// of a successful apply. The readiness of the object is left untouched, as the apply itself succeeded.
+func TerraformPostApplyWebhookFailed(terraform Terraform, message string) Terraform {
+	newCondition := metav1.Condition{
+		Type:    ConditionTypePostApplyWebhook,
+		Status:  metav1.ConditionFalse,
+		Reason:  PostApplyWebhookFailedReason,
+		Message: trimString(message, MaxConditionMessageLength),
+	}
+	apimeta.SetStatusCondition(terraform.GetStatusConditions(), newCondition)
+	return terraform
+}
+
+// TerraformPostApplyWebhookSucceeded records that the post-apply webhooks accepted the result of an apply.
+func TerraformPostApplyWebhookSucceeded(terraform Terraform, message string) Terraform {
+	newCondition := metav1.Condition{
+		Type:    ConditionTypePostApplyWebhook,
+		Status:  metav1.ConditionTrue,
+		Reason:  PostApplyWebhookSucceededReason,
+		Message: trimString(message, MaxConditionMessageLength),
+	}
+	apimeta.SetStatusCondition(terraform.GetStatusConditions(), newCondition)
+	return terraform
+}
+
 func TerraformPlannedWithChanges(terraform Terraform, revision string, forceOrAutoApply bool, message string) Terraform {
 	planId := planid.GetPlanID(revision)
 	approveMessage := planid.GetApproveMessage(planId, message)
@@ -801,13 +1208,18 @@ func TerraformAppliedFailResetPlanAndNotReady(terraform Terraform, revision, rea
 }
 
 func TerraformDriftDetected(terraform Terraform, revision, reason, message string) Terraform {
-	terraform.Status.LastDriftDetectedAt = &metav1.Time{Time: time.Now()}
+	now := metav1.Now()
+	terraform.Status.LastDriftDetectedAt = &now
+	terraform.Status.LastDriftDetectionAt = &now
 
 	SetTerraformReadiness(&terraform, metav1.ConditionFalse, reason, trimString(message, MaxConditionMessageLength), revision)
 	return terraform
 }
 
 func TerraformNoDrift(terraform Terraform, revision, reason, message string) Terraform {
+	now := metav1.Now()
+	terraform.Status.LastDriftDetectionAt = &now
+
 	SetTerraformReadiness(&terraform, metav1.ConditionTrue, reason, message+": "+revision, revision)
 	return terraform
 }
@@ -896,6 +1308,37 @@ func TerraformResetRetry(terraform Terraform) Terraform {
 	return terraform
 }
 
+// NextDriftDetectionAt returns when drift detection is due next, following the last drift
+// detection by the drift detection interval. It returns the zero time if drift detection
+// is due at every reconciliation, or has not run yet.
+func (in Terraform) NextDriftDetectionAt() time.Time {
+	if in.Spec.DriftDetectionInterval == nil || in.Spec.DriftDetectionInterval.Duration <= 0 {
+		return time.Time{}
+	}
+
+	var last time.Time
+	if in.Status.LastDriftDetectionAt != nil {
+		last = in.Status.LastDriftDetectionAt.Time
+	}
+	if in.Status.LastDriftDetectedAt != nil && in.Status.LastDriftDetectedAt.After(last) {
+		last = in.Status.LastDriftDetectedAt.Time
+	}
+	if last.IsZero() {
+		return time.Time{}
+	}
+	return last.Add(in.Spec.DriftDetectionInterval.Duration)
+}
+
+// PlanExpiresAt returns when the pending plan expires, following its generation by the plan TTL.
+// It returns the zero time if there is no pending plan, or plans do not expire.
+func (in Terraform) PlanExpiresAt() time.Time {
+	if in.Spec.PlanTTL == nil || in.Spec.PlanTTL.Duration <= 0 ||
+		in.Status.Plan.Pending == "" || in.Status.LastPlanAt == nil {
+		return time.Time{}
+	}
+	return in.Status.LastPlanAt.Add(in.Spec.PlanTTL.Duration)
+}
+
 // HasDrift returns true if drift has been detected since the last successful apply
 func (in Terraform) HasDrift() bool {
 	for _, condition := range in.Status.Conditions {
@@ -948,6 +1391,27 @@ func (in Terraform) GetConditions() []metav1.Condition {
 	return in.Status.Conditions
 }
 
+// IsMigrateStateRequested returns true if the object has been annotated to migrate its state,
+// and the request has not been handled yet.
+func (in Terraform) IsMigrateStateRequested() bool {
+	requestedAt, ok := in.GetAnnotations()[MigrateStateAnnotation]
+	return ok && requestedAt != in.Status.LastHandledMigrateStateAt
+}
+
+// IsSkipStateMigrationRequested returns true if the object has been annotated to use a new backend
+// without migrating its state, and the request has not been handled yet.
+func (in Terraform) IsSkipStateMigrationRequested() bool {
+	requestedAt, ok := in.GetAnnotations()[SkipStateMigrationAnnotation]
+	return ok && requestedAt != in.Status.LastHandledMigrateStateAt
+}
+
+// IsCascadeDestroyRequested returns true if the object has been annotated
+// to be destroyed together with all of its dependants.
+func (in Terraform) IsCascadeDestroyRequested() bool {
+	_, ok := in.GetAnnotations()[CascadeDestroyAnnotation]
+	return ok
+}
+
 func (in *Terraform) WorkspaceName() string {
 	if in.Spec.Workspace != "" {
 		return in.Spec.Workspace
@@ -28,6 +28,73 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApprovalPolicySpec) DeepCopyInto(out *ApprovalPolicySpec) {
+	*out = *in
+	if in.Approvers != nil {
+		in, out := &in.Approvers, &out.Approvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ApproverGroups != nil {
+		in, out := &in.ApproverGroups, &out.ApproverGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApprovalPolicySpec.
+func (in *ApprovalPolicySpec) DeepCopy() *ApprovalPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApprovalPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApprovalStatus) DeepCopyInto(out *ApprovalStatus) {
+	*out = *in
+	if in.Approvals != nil {
+		in, out := &in.Approvals, &out.Approvals
+		*out = make([]PlanApprovalRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApprovalStatus.
+func (in *ApprovalStatus) DeepCopy() *ApprovalStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApprovalStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureRMBackendSpec) DeepCopyInto(out *AzureRMBackendSpec) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(meta.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureRMBackendSpec.
+func (in *AzureRMBackendSpec) DeepCopy() *AzureRMBackendSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureRMBackendSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackendConfigSpec) DeepCopyInto(out *BackendConfigSpec) {
 	*out = *in
@@ -38,155 +105,690 @@ func (in *BackendConfigSpec) DeepCopyInto(out *BackendConfigSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3BackendSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		*out = new(GCSBackendSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AzureRM != nil {
+		in, out := &in.AzureRM, &out.AzureRM
+		*out = new(AzureRMBackendSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PG != nil {
+		in, out := &in.PG, &out.PG
+		*out = new(PGBackendSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendConfigSpec.
+func (in *BackendConfigSpec) DeepCopy() *BackendConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendConfigsReference) DeepCopyInto(out *BackendConfigsReference) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendConfigsReference.
+func (in *BackendConfigsReference) DeepCopy() *BackendConfigsReference {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendConfigsReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendStatus) DeepCopyInto(out *BackendStatus) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(BackendConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendStatus.
+func (in *BackendStatus) DeepCopy() *BackendStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlackoutPeriod) DeepCopyInto(out *BlackoutPeriod) {
+	*out = *in
+	in.Start.DeepCopyInto(&out.Start)
+	in.End.DeepCopyInto(&out.End)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlackoutPeriod.
+func (in *BlackoutPeriod) DeepCopy() *BlackoutPeriod {
+	if in == nil {
+		return nil
+	}
+	out := new(BlackoutPeriod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BranchPlanner) DeepCopyInto(out *BranchPlanner) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BranchPlanner.
+func (in *BranchPlanner) DeepCopy() *BranchPlanner {
+	if in == nil {
+		return nil
+	}
+	out := new(BranchPlanner)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudSpec) DeepCopyInto(out *CloudSpec) {
+	*out = *in
+	if in.Workspaces != nil {
+		in, out := &in.Workspaces, &out.Workspaces
+		*out = new(CloudWorkspacesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudSpec.
+func (in *CloudSpec) DeepCopy() *CloudSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudWorkspacesSpec) DeepCopyInto(out *CloudWorkspacesSpec) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudWorkspacesSpec.
+func (in *CloudWorkspacesSpec) DeepCopy() *CloudWorkspacesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudWorkspacesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrossNamespaceSourceReference) DeepCopyInto(out *CrossNamespaceSourceReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrossNamespaceSourceReference.
+func (in *CrossNamespaceSourceReference) DeepCopy() *CrossNamespaceSourceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CrossNamespaceSourceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSHealthCheck) DeepCopyInto(out *DNSHealthCheck) {
+	*out = *in
+	if in.ExpectedRecords != nil {
+		in, out := &in.ExpectedRecords, &out.ExpectedRecords
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSHealthCheck.
+func (in *DNSHealthCheck) DeepCopy() *DNSHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiffRequest) DeepCopyInto(out *DiffRequest) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiffRequest.
+func (in *DiffRequest) DeepCopy() *DiffRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(DiffRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiffStatus) DeepCopyInto(out *DiffStatus) {
+	*out = *in
+	if in.HandledAt != nil {
+		in, out := &in.HandledAt, &out.HandledAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiffStatus.
+func (in *DiffStatus) DeepCopy() *DiffStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DiffStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftPolicySpec) DeepCopyInto(out *DriftPolicySpec) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]DriftRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftPolicySpec.
+func (in *DriftPolicySpec) DeepCopy() *DriftPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftReport) DeepCopyInto(out *DriftReport) {
+	*out = *in
+	in.DetectedAt.DeepCopyInto(&out.DetectedAt)
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]DriftedResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftReport.
+func (in *DriftReport) DeepCopy() *DriftReport {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftRule) DeepCopyInto(out *DriftRule) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftRule.
+func (in *DriftRule) DeepCopy() *DriftRule {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftStatus) DeepCopyInto(out *DriftStatus) {
+	*out = *in
+	if in.Correct != nil {
+		in, out := &in.Correct, &out.Correct
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Alert != nil {
+		in, out := &in.Alert, &out.Alert
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ignore != nil {
+		in, out := &in.Ignore, &out.Ignore
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftStatus.
+func (in *DriftStatus) DeepCopy() *DriftStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftedAttribute) DeepCopyInto(out *DriftedAttribute) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftedAttribute.
+func (in *DriftedAttribute) DeepCopy() *DriftedAttribute {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftedAttribute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftedResource) DeepCopyInto(out *DriftedResource) {
+	*out = *in
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make([]DriftedAttribute, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftedResource.
+func (in *DriftedResource) DeepCopy() *DriftedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpiredPlanStatus) DeepCopyInto(out *ExpiredPlanStatus) {
+	*out = *in
+	if in.GeneratedAt != nil {
+		in, out := &in.GeneratedAt, &out.GeneratedAt
+		*out = (*in).DeepCopy()
+	}
+	in.ExpiredAt.DeepCopyInto(&out.ExpiredAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpiredPlanStatus.
+func (in *ExpiredPlanStatus) DeepCopy() *ExpiredPlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpiredPlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileMapping) DeepCopyInto(out *FileMapping) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileMapping.
+func (in *FileMapping) DeepCopy() *FileMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(FileMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GCSBackendSpec) DeepCopyInto(out *GCSBackendSpec) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(meta.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCSBackendSpec.
+func (in *GCSBackendSpec) DeepCopy() *GCSBackendSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GCSBackendSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCHealthCheck) DeepCopyInto(out *GRPCHealthCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCHealthCheck.
+func (in *GRPCHealthCheck) DeepCopy() *GRPCHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(GRPCHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPHealthCheck) DeepCopyInto(out *HTTPHealthCheck) {
+	*out = *in
+	if in.ExpectedStatusCodes != nil {
+		in, out := &in.ExpectedStatusCodes, &out.ExpectedStatusCodes
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpectedHeaders != nil {
+		in, out := &in.ExpectedHeaders, &out.ExpectedHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPHealthCheck.
+func (in *HTTPHealthCheck) DeepCopy() *HTTPHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthCheck) DeepCopyInto(out *HealthCheck) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPHealthCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSHealthCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GRPC != nil {
+		in, out := &in.GRPC, &out.GRPC
+		*out = new(GRPCHealthCheck)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TLSHealthCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KubernetesObject != nil {
+		in, out := &in.KubernetesObject, &out.KubernetesObject
+		*out = new(KubernetesObjectHealthCheck)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendConfigSpec.
-func (in *BackendConfigSpec) DeepCopy() *BackendConfigSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheck.
+func (in *HealthCheck) DeepCopy() *HealthCheck {
 	if in == nil {
 		return nil
 	}
-	out := new(BackendConfigSpec)
+	out := new(HealthCheck)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BackendConfigsReference) DeepCopyInto(out *BackendConfigsReference) {
+func (in *ImportSpec) DeepCopyInto(out *ImportSpec) {
 	*out = *in
-	if in.Keys != nil {
-		in, out := &in.Keys, &out.Keys
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendConfigsReference.
-func (in *BackendConfigsReference) DeepCopy() *BackendConfigsReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportSpec.
+func (in *ImportSpec) DeepCopy() *ImportSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(BackendConfigsReference)
+	out := new(ImportSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BranchPlanner) DeepCopyInto(out *BranchPlanner) {
+func (in *KubernetesObjectHealthCheck) DeepCopyInto(out *KubernetesObjectHealthCheck) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BranchPlanner.
-func (in *BranchPlanner) DeepCopy() *BranchPlanner {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesObjectHealthCheck.
+func (in *KubernetesObjectHealthCheck) DeepCopy() *KubernetesObjectHealthCheck {
 	if in == nil {
 		return nil
 	}
-	out := new(BranchPlanner)
+	out := new(KubernetesObjectHealthCheck)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudSpec) DeepCopyInto(out *CloudSpec) {
+func (in *LockStatus) DeepCopyInto(out *LockStatus) {
 	*out = *in
-	if in.Workspaces != nil {
-		in, out := &in.Workspaces, &out.Workspaces
-		*out = new(CloudWorkspacesSpec)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudSpec.
-func (in *CloudSpec) DeepCopy() *CloudSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LockStatus.
+func (in *LockStatus) DeepCopy() *LockStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudSpec)
+	out := new(LockStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudWorkspacesSpec) DeepCopyInto(out *CloudWorkspacesSpec) {
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
 	*out = *in
-	if in.Tags != nil {
-		in, out := &in.Tags, &out.Tags
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
 	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudWorkspacesSpec.
-func (in *CloudWorkspacesSpec) DeepCopy() *CloudWorkspacesSpec {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MoveSpec) DeepCopyInto(out *MoveSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MoveSpec.
+func (in *MoveSpec) DeepCopy() *MoveSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudWorkspacesSpec)
+	out := new(MoveSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CrossNamespaceSourceReference) DeepCopyInto(out *CrossNamespaceSourceReference) {
+func (in *PGBackendSpec) DeepCopyInto(out *PGBackendSpec) {
 	*out = *in
+	out.ConnectionSecretRef = in.ConnectionSecretRef
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrossNamespaceSourceReference.
-func (in *CrossNamespaceSourceReference) DeepCopy() *CrossNamespaceSourceReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackendSpec.
+func (in *PGBackendSpec) DeepCopy() *PGBackendSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(CrossNamespaceSourceReference)
+	out := new(PGBackendSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FileMapping) DeepCopyInto(out *FileMapping) {
+func (in *PlanApproval) DeepCopyInto(out *PlanApproval) {
 	*out = *in
-	out.SecretRef = in.SecretRef
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileMapping.
-func (in *FileMapping) DeepCopy() *FileMapping {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanApproval.
+func (in *PlanApproval) DeepCopy() *PlanApproval {
 	if in == nil {
 		return nil
 	}
-	out := new(FileMapping)
+	out := new(PlanApproval)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PlanApproval) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HealthCheck) DeepCopyInto(out *HealthCheck) {
+func (in *PlanApprovalList) DeepCopyInto(out *PlanApprovalList) {
 	*out = *in
-	if in.Timeout != nil {
-		in, out := &in.Timeout, &out.Timeout
-		*out = new(v1.Duration)
-		**out = **in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PlanApproval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheck.
-func (in *HealthCheck) DeepCopy() *HealthCheck {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanApprovalList.
+func (in *PlanApprovalList) DeepCopy() *PlanApprovalList {
 	if in == nil {
 		return nil
 	}
-	out := new(HealthCheck)
+	out := new(PlanApprovalList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PlanApprovalList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LockStatus) DeepCopyInto(out *LockStatus) {
+func (in *PlanApprovalRecord) DeepCopyInto(out *PlanApprovalRecord) {
 	*out = *in
+	in.ApprovedAt.DeepCopyInto(&out.ApprovedAt)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LockStatus.
-func (in *LockStatus) DeepCopy() *LockStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanApprovalRecord.
+func (in *PlanApprovalRecord) DeepCopy() *PlanApprovalRecord {
 	if in == nil {
 		return nil
 	}
-	out := new(LockStatus)
+	out := new(PlanApprovalRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlanApprovalSpec) DeepCopyInto(out *PlanApprovalSpec) {
+	*out = *in
+	out.TerraformRef = in.TerraformRef
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanApprovalSpec.
+func (in *PlanApprovalSpec) DeepCopy() *PlanApprovalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PlanApprovalSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -403,6 +1005,112 @@ func (in *RunnerPodTemplate) DeepCopy() *RunnerPodTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3BackendSpec) DeepCopyInto(out *S3BackendSpec) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(meta.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3BackendSpec.
+func (in *S3BackendSpec) DeepCopy() *S3BackendSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(S3BackendSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleSpec) DeepCopyInto(out *ScheduleSpec) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Blackouts != nil {
+		in, out := &in.Blackouts, &out.Blackouts
+		*out = make([]BlackoutPeriod, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleSpec.
+func (in *ScheduleSpec) DeepCopy() *ScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateBackupSpec) DeepCopyInto(out *StateBackupSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateBackupSpec.
+func (in *StateBackupSpec) DeepCopy() *StateBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StateBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateOperationRequest) DeepCopyInto(out *StateOperationRequest) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateOperationRequest.
+func (in *StateOperationRequest) DeepCopy() *StateOperationRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(StateOperationRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateOperationStatus) DeepCopyInto(out *StateOperationStatus) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HandledAt != nil {
+		in, out := &in.HandledAt, &out.HandledAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateOperationStatus.
+func (in *StateOperationStatus) DeepCopy() *StateOperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StateOperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TFStateSpec) DeepCopyInto(out *TFStateSpec) {
 	*out = *in
@@ -419,6 +1127,26 @@ func (in *TFStateSpec) DeepCopy() *TFStateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSHealthCheck) DeepCopyInto(out *TLSHealthCheck) {
+	*out = *in
+	if in.MinValidity != nil {
+		in, out := &in.MinValidity, &out.MinValidity
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSHealthCheck.
+func (in *TLSHealthCheck) DeepCopy() *TLSHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Terraform) DeepCopyInto(out *Terraform) {
 	*out = *in
@@ -481,6 +1209,16 @@ func (in *TerraformList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TerraformSpec) DeepCopyInto(out *TerraformSpec) {
 	*out = *in
+	if in.ApprovalPolicy != nil {
+		in, out := &in.ApprovalPolicy, &out.ApprovalPolicy
+		*out = new(ApprovalPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PlanTTL != nil {
+		in, out := &in.PlanTTL, &out.PlanTTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	if in.BackendConfig != nil {
 		in, out := &in.BackendConfig, &out.BackendConfig
 		*out = new(BackendConfigSpec)
@@ -549,6 +1287,16 @@ func (in *TerraformSpec) DeepCopyInto(out *TerraformSpec) {
 		*out = new(WriteOutputsToSecretSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DriftDetectionInterval != nil {
+		in, out := &in.DriftDetectionInterval, &out.DriftDetectionInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DriftPolicy != nil {
+		in, out := &in.DriftPolicy, &out.DriftPolicy
+		*out = new(DriftPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.CliConfigSecretRef != nil {
 		in, out := &in.CliConfigSecretRef, &out.CliConfigSecretRef
 		*out = new(corev1.SecretReference)
@@ -577,6 +1325,26 @@ func (in *TerraformSpec) DeepCopyInto(out *TerraformSpec) {
 		*out = new(TFStateSpec)
 		**out = **in
 	}
+	if in.StateBackup != nil {
+		in, out := &in.StateBackup, &out.StateBackup
+		*out = new(StateBackupSpec)
+		**out = **in
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(ScheduleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Imports != nil {
+		in, out := &in.Imports, &out.Imports
+		*out = make([]ImportSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Moves != nil {
+		in, out := &in.Moves, &out.Moves
+		*out = make([]MoveSpec, len(*in))
+		copy(*out, *in)
+	}
 	if in.Targets != nil {
 		in, out := &in.Targets, &out.Targets
 		*out = make([]string, len(*in))
@@ -640,6 +1408,10 @@ func (in *TerraformStatus) DeepCopyInto(out *TerraformStatus) {
 		in, out := &in.LastDriftDetectedAt, &out.LastDriftDetectedAt
 		*out = (*in).DeepCopy()
 	}
+	if in.LastDriftDetectionAt != nil {
+		in, out := &in.LastDriftDetectionAt, &out.LastDriftDetectionAt
+		*out = (*in).DeepCopy()
+	}
 	if in.LastAppliedByDriftDetectionAt != nil {
 		in, out := &in.LastAppliedByDriftDetectionAt, &out.LastAppliedByDriftDetectionAt
 		*out = (*in).DeepCopy()
@@ -656,6 +1428,46 @@ func (in *TerraformStatus) DeepCopyInto(out *TerraformStatus) {
 		(*in).DeepCopyInto(*out)
 	}
 	out.Lock = in.Lock
+	if in.Backend != nil {
+		in, out := &in.Backend, &out.Backend
+		*out = new(BackendStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StateOperation != nil {
+		in, out := &in.StateOperation, &out.StateOperation
+		*out = new(StateOperationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = new(DiffStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AppliedImports != nil {
+		in, out := &in.AppliedImports, &out.AppliedImports
+		*out = make([]ImportSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.AppliedMoves != nil {
+		in, out := &in.AppliedMoves, &out.AppliedMoves
+		*out = make([]MoveSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = new(DriftStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(ApprovalStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExpiredPlan != nil {
+		in, out := &in.ExpiredPlan, &out.ExpiredPlan
+		*out = new(ExpiredPlanStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TerraformStatus.
@@ -721,6 +1533,21 @@ func (in *Webhook) DeepCopyInto(out *Webhook) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(meta.LocalObjectReference)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RetryInterval != nil {
+		in, out := &in.RetryInterval, &out.RetryInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Webhook.
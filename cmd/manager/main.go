@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"os"
 	"time"
 
@@ -25,6 +26,8 @@ import (
 
 	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
 	"github.com/flux-iac/tofu-controller/controllers"
+	tfmetrics "github.com/flux-iac/tofu-controller/internal/metrics"
+	"github.com/flux-iac/tofu-controller/internal/tracing"
 	"github.com/fluxcd/pkg/runtime/acl"
 	"github.com/fluxcd/pkg/runtime/client"
 	runtimeCtrl "github.com/fluxcd/pkg/runtime/controller"
@@ -37,11 +40,13 @@ import (
 	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
 	flag "github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -98,6 +103,9 @@ func main() {
 		aclOptions                acl.Options
 		allowCrossNamespaceRefs   bool
 		usePodSubdomainResolution bool
+		freezeConfigMap           string
+		driftDetectionConcurrency int
+		tracingOptions            tracing.Options
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
@@ -120,6 +128,13 @@ func main() {
 	flag.BoolVar(&allowBreakTheGlass, "allow-break-the-glass", false, "Allow break the glass mode.")
 	flag.StringVar(&clusterDomain, "cluster-domain", "cluster.local", "The cluster domain used by the cluster.")
 	flag.BoolVar(&usePodSubdomainResolution, "use-pod-subdomain-resolution", false, "Allow to use pod hostname/subdomain DNS resolution instead of IP based")
+	flag.StringVar(&freezeConfigMap, "freeze-configmap", "tf-controller-freeze",
+		"The name of the ConfigMap in the runtime namespace that holds all applies while it exists. Set to an empty string to disable change freezes.")
+	flag.IntVar(&driftDetectionConcurrency, "drift-detection-concurrency", 0,
+		"The maximum number of drift detections running at once across all Terraform objects. Set to 0 for no limit.")
+	flag.StringVar(&tracingOptions.Endpoint, "otlp-endpoint", "",
+		"The host:port of the OTLP/HTTP collector to export the traces of the controller and the runners to. Tracing is disabled if empty.")
+	flag.BoolVar(&tracingOptions.Insecure, "otlp-insecure", false, "Export the traces to the OTLP collector without TLS.")
 
 	clientOptions.BindFlags(flag.CommandLine)
 	logOptions.BindFlags(flag.CommandLine)
@@ -177,9 +192,22 @@ func main() {
 	}
 
 	metricsH := runtimeCtrl.NewMetrics(mgr, metrics.MustMakeRecorder(), infrav1.TerraformFinalizer)
+	metricsRecorder := tfmetrics.NewRecorder()
+	crmetrics.Registry.MustRegister(metricsRecorder.Collectors()...)
 
 	signalHandlerContext := ctrl.SetupSignalHandler()
 
+	shutdownTracing, err := tracing.Setup(signalHandlerContext, "tofu-controller", tracingOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			setupLog.Error(err, "unable to flush the traces")
+		}
+	}()
+
 	certsReady := make(chan struct{})
 	rotator := &mtls.CertRotator{
 		Ready:                         certsReady,
@@ -232,6 +260,7 @@ func main() {
 		Scheme:                    mgr.GetScheme(),
 		EventRecorder:             eventRecorder,
 		Metrics:                   metricsH,
+		MetricsRecorder:           metricsRecorder,
 		StatusPoller:              polling.NewStatusPoller(mgr.GetClient(), mgr.GetRESTMapper(), polling.Options{}),
 		CertRotator:               rotator,
 		RunnerGRPCPort:            runnerGRPCPort,
@@ -242,6 +271,9 @@ func main() {
 		NoCrossNamespaceRefs:      !allowCrossNamespaceRefs,
 		UsePodSubdomainResolution: usePodSubdomainResolution,
 		Clientset:                 clientset,
+		FreezeConfigMap:           types.NamespacedName{Namespace: runtimeNamespace, Name: freezeConfigMap},
+		DriftDetectionConcurrency: driftDetectionConcurrency,
+		Tracing:                   tracingOptions,
 	}
 
 	if err = reconciler.SetupWithManager(mgr, concurrent, httpRetry); err != nil {
@@ -17,12 +17,14 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/flux-iac/tofu-controller/internal/tracing"
 	"github.com/flux-iac/tofu-controller/mtls"
 	"github.com/fluxcd/pkg/runtime/logger"
 	flag "github.com/spf13/pflag"
@@ -58,11 +60,14 @@ func main() {
 		grpcPort           int
 		tlsSecretName      string
 		grpcMaxMessageSize int
+		tracingOptions     tracing.Options
 	)
 
 	flag.IntVar(&grpcPort, "grpc-port", 30000, "The port on which to expose the grpc endpoint.")
 	flag.StringVar(&tlsSecretName, "tls-secret-name", "", "The TLS secret name.")
 	flag.IntVar(&grpcMaxMessageSize, "grpc-max-message-size", 4, "The maximum size of gRPC messages in MiB.")
+	flag.StringVar(&tracingOptions.Endpoint, "otlp-endpoint", "", "The host:port of the OTLP/HTTP collector to export the traces to.")
+	flag.BoolVar(&tracingOptions.Insecure, "otlp-insecure", false, "Export the traces to the OTLP collector without TLS.")
 	flag.Parse()
 
 	addr := fmt.Sprintf(":%d", grpcPort)
@@ -87,7 +92,15 @@ func main() {
 
 	log.Println("Starting the runner...", "version", BuildVersion, "sha", BuildSHA)
 
-	err := mtls.RunnerServe(podNamespace, addr, tlsSecretName, sigterm, grpcMaxMessageSize)
+	shutdownTracing, err := tracing.Setup(context.Background(), "tf-runner", tracingOptions)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	err = mtls.RunnerServe(podNamespace, addr, tlsSecretName, sigterm, grpcMaxMessageSize)
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		log.Println("unable to flush the traces", shutdownErr)
+	}
 	if err != nil {
 		log.Fatal(err.Error())
 	}
@@ -22,6 +22,9 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/kubectl/pkg/util"
 	"k8s.io/kubectl/pkg/util/podutils"
+	"sigs.k8s.io/yaml"
+
+	"github.com/flux-iac/tofu-controller/tfctl"
 )
 
 type logsFlags struct {
@@ -35,6 +38,7 @@ type logsFlags struct {
 	sinceTime           string
 	sinceSeconds        time.Duration
 	runner              bool
+	output              string
 }
 
 var logsArgs = logsFlags{
@@ -68,6 +72,9 @@ func buildLogsCmd() *cobra.Command {
 
 	  # Print runner logs
 	  tfctl logs --runner --name=podinfo
+
+	  # Print the log entries as JSON lines
+	  tfctl logs --all-namespaces -o json
 		`,
 		RunE: logsCmdRun,
 	}
@@ -103,6 +110,10 @@ func logsCmdRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no argument required")
 	}
 
+	if logsArgs.output, err = cmd.Flags().GetString("output"); err != nil {
+		return err
+	}
+
 	if logsArgs.runner {
 		return runnerLogs(ctx, logsArgs, clientset)
 	}
@@ -168,7 +179,7 @@ LOOP:
 	stream, err := req.Stream(ctx)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			fmt.Printf("%s/%s runner pod is not running, waiting...\n", logsArgs.controllerNamespace, podName)
+			fmt.Fprintf(os.Stderr, "%s/%s runner pod is not running, waiting...\n", logsArgs.controllerNamespace, podName)
 			time.Sleep(runnerLogsSleep)
 
 			goto LOOP
@@ -178,11 +189,37 @@ LOOP:
 	}
 	defer stream.Close()
 
+	if logsArgs.output != "" {
+		return printRunnerLogEntries(stream, os.Stdout)
+	}
+
 	_, err = io.Copy(os.Stdout, stream)
 
 	return err
 }
 
+// printRunnerLogEntries prints the structured entries of the runner logs as JSON lines or YAML documents,
+// leaving out the lines which are not structured.
+func printRunnerLogEntries(stream io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !json.Valid(line) {
+			continue
+		}
+		if logsArgs.output == tfctl.OutputYAML {
+			data, err := yaml.JSONToYAML(line)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "---\n%s", data)
+			continue
+		}
+		fmt.Fprintf(w, "%s\n", line)
+	}
+	return scanner.Err()
+}
+
 // getPods searches for all Deployments in the given namespace that match the given label and returns a list of Pods
 // from these Deployments. For each Deployment a single Pod is chosen (based on various factors such as the running
 // state). If no Pod is found, an error is returned.
@@ -329,13 +366,30 @@ func filterPrintLog(t *template.Template, l *ControllerLogEntry, w io.Writer) {
 		(logsArgs.kind == "" || strings.EqualFold(logsArgs.kind, l.Kind) || strings.EqualFold(logsArgs.kind, l.ControllerKind)) &&
 		(logsArgs.name == "" || strings.EqualFold(logsArgs.name, l.Name)) &&
 		(logsArgs.allNamespaces || strings.EqualFold(*kubeconfigArgs.Namespace, l.Namespace)) {
-		err := t.Execute(w, l)
+		err := printLogEntry(t, l, w)
 		if err != nil {
 			fmt.Printf("log template error: %s\n", err)
 		}
 	}
 }
 
+// printLogEntry prints a log entry with the template, or as a JSON line or a YAML document with --output.
+func printLogEntry(t *template.Template, l *ControllerLogEntry, w io.Writer) error {
+	switch logsArgs.output {
+	case tfctl.OutputJSON:
+		return json.NewEncoder(w).Encode(l)
+	case tfctl.OutputYAML:
+		data, err := yaml.Marshal(l)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "---\n%s", data)
+		return err
+	default:
+		return t.Execute(w, l)
+	}
+}
+
 type ControllerLogEntry struct {
 	Timestamp      string `json:"ts"`
 	Level          string `json:"level"`
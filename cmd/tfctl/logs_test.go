@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"os"
 	"strings"
@@ -95,6 +96,40 @@ func TestLogRequest(t *testing.T) {
 	}
 }
 
+func TestLogRequestOutput(t *testing.T) {
+	g := NewWithT(t)
+
+	logsArgs = logsFlags{
+		tail:          -1,
+		logLevel:      "error",
+		allNamespaces: true,
+		output:        "json",
+	}
+	defer func() {
+		logsArgs = logsFlags{
+			tail: -1,
+		}
+	}()
+
+	w := &bytes.Buffer{}
+	g.Expect(logRequest(context.Background(), &testResponseMapper{}, w)).To(Succeed())
+
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	g.Expect(lines).To(HaveLen(3))
+	for _, line := range lines {
+		entry := ControllerLogEntry{}
+		g.Expect(json.Unmarshal([]byte(line), &entry)).To(Succeed())
+		g.Expect(entry.Level).To(Equal("error"))
+	}
+
+	// printing the structured runner log entries only
+	logsArgs.output = "yaml"
+	w.Reset()
+	runnerLogs := "starting the runner\n{\"level\":\"info\",\"msg\":\"plan generated\"}\n"
+	g.Expect(printRunnerLogEntries(strings.NewReader(runnerLogs), w)).To(Succeed())
+	g.Expect(w.String()).To(Equal("---\nlevel: info\nmsg: plan generated\n"))
+}
+
 var testPodLogs = `{"level":"info","ts":"2022-08-02T12:55:34.419Z","msg":"no changes since last reconcilation: observed revision","controller":"gitrepository","controllerGroup":"source.toolkit.fluxcd.io","controllerKind":"GitRepository","gitRepository":{"name":"podinfo","namespace":"default"},"namespace":"default","name":"podinfo","reconcileID":"5ef9b2ef-4ea5-47b7-b887-a247cafc1bce"}
 {"level":"error","ts":"2022-08-02T12:56:04.679Z","logger":"controller.gitrepository","msg":"no changes since last reconcilation: observed revision","controllerGroup":"source.toolkit.fluxcd.io","controllerKind":"GitRepository","gitRepository":{"name":"podinfo","namespace":"flux-system"},"name":"flux-system","namespace":"flux-system","reconcileID":"543ef9b2ef-4ea5-47b7-b887-a247cafc1bce"}
 {"level":"error","ts":"2022-08-02T12:56:34.961Z","logger":"controller.kustomization","msg":"no changes since last reconcilation: observed revision","reconciler group":"kustomize.toolkit.fluxcd.io","reconciler kind":"Kustomization","name":"flux-system","namespace":"flux-system"}
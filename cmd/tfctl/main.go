@@ -2,13 +2,16 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
 	"github.com/flux-iac/tofu-controller/tfctl"
 )
 
@@ -25,7 +28,11 @@ var kubeconfigArgs = genericclioptions.NewConfigFlags(false)
 
 func main() {
 	cmd := newRootCommand()
-	cobra.CheckErr(cmd.Execute())
+	if err := cmd.Execute(); errors.Is(err, tfctl.ErrChangesPending) {
+		os.Exit(tfctl.ExitCodeChangesPending)
+	} else {
+		cobra.CheckErr(err)
+	}
 }
 
 func newRootCommand() *cobra.Command {
@@ -46,6 +53,9 @@ func newRootCommand() *cobra.Command {
 
 	// flags
 	rootCmd.PersistentFlags().String("terraform", "/usr/bin/terraform", "The location of the terraform binary.")
+	rootCmd.PersistentFlags().StringP("output", "o", "", "Print the result as json or yaml, instead of human readable text.")
+	rootCmd.PersistentFlags().Bool("detailed-exitcode", false,
+		"Exit with 0 when there are no changes, 1 on errors, and 2 when a plan is pending, a diff has changes or drift is detected.")
 	kubeconfigArgs.AddFlags(rootCmd.PersistentFlags())
 
 	// bind flags to config
@@ -53,7 +63,11 @@ func newRootCommand() *cobra.Command {
 
 	rootCmd.AddCommand(buildCreateCmd(app))
 	rootCmd.AddCommand(buildDeleteCmd(app))
+	rootCmd.AddCommand(buildDestroyCmd(app))
+	rootCmd.AddCommand(buildDiffCmd(app))
+	rootCmd.AddCommand(buildDriftCmd(app))
 	rootCmd.AddCommand(buildForceUnlockCmd(app))
+	rootCmd.AddCommand(buildImportCmd(app))
 	rootCmd.AddCommand(buildInstallCmd(app))
 	rootCmd.AddCommand(buildReconcileCmd(app))
 	rootCmd.AddCommand(buildApprovePlanCmd(app))
@@ -62,9 +76,12 @@ func newRootCommand() *cobra.Command {
 	rootCmd.AddCommand(buildSuspendCmd(app))
 	rootCmd.AddCommand(buildUninstallCmd(app))
 	rootCmd.AddCommand(buildVersionCmd(app))
+	rootCmd.AddCommand(buildWaitCmd(app))
+	rootCmd.AddCommand(buildWatchCmd(app))
 
 	rootCmd.AddCommand(buildGetGroup(app))
 	rootCmd.AddCommand(buildShowGroup(app))
+	rootCmd.AddCommand(buildStateGroup(app))
 
 	rootCmd.AddCommand(buildBreakTheGlassCmd(app))
 
@@ -243,9 +260,158 @@ func buildShowPlanCmd(app *tfctl.CLI) *cobra.Command {
 	}
 }
 
+func buildStateGroup(app *tfctl.CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Manage the Terraform state",
+	}
+	cmd.AddCommand(buildStateListCmd(app))
+	cmd.AddCommand(buildStateShowCmd(app))
+	cmd.AddCommand(buildStateMvCmd(app))
+	cmd.AddCommand(buildStateRmCmd(app))
+	cmd.AddCommand(buildStateRestoreCmd(app))
+	return cmd
+}
+
+var stateListExamples = `
+  # List the resources in the state of a Terraform resource
+  tfctl state list my-resource
+
+  # List the resources of a module
+  tfctl state list my-resource module.network
+`
+
+func buildStateListCmd(app *tfctl.CLI) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list NAME [ADDRESS...]",
+		Short:   "List the resources in the Terraform state",
+		Example: strings.Trim(stateListExamples, "\n"),
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.StateOperation(cmd.Context(), os.Stdout, args[0], infrav1.StateOperationList, args[1:])
+		},
+	}
+}
+
+var stateShowExamples = `
+  # Show the attributes of a resource in the state of a Terraform resource
+  tfctl state show my-resource aws_s3_bucket.bucket
+`
+
+func buildStateShowCmd(app *tfctl.CLI) *cobra.Command {
+	return &cobra.Command{
+		Use:     "show NAME ADDRESS",
+		Short:   "Show a resource in the Terraform state",
+		Example: strings.Trim(stateShowExamples, "\n"),
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.StateOperation(cmd.Context(), os.Stdout, args[0], infrav1.StateOperationShow, args[1:])
+		},
+	}
+}
+
+var stateMvExamples = `
+  # Rename a resource in the state of a Terraform resource
+  tfctl state mv my-resource aws_s3_bucket.bucket aws_s3_bucket.logs
+
+  # Move a resource into a module
+  tfctl state mv my-resource aws_s3_bucket.bucket module.storage.aws_s3_bucket.bucket
+`
+
+func buildStateMvCmd(app *tfctl.CLI) *cobra.Command {
+	return &cobra.Command{
+		Use:     "mv NAME SOURCE DESTINATION",
+		Short:   "Move a resource to another address in the Terraform state",
+		Example: strings.Trim(stateMvExamples, "\n"),
+		Args:    cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.StateOperation(cmd.Context(), os.Stdout, args[0], infrav1.StateOperationMv, args[1:])
+		},
+	}
+}
+
+var stateRmExamples = `
+  # Stop managing a resource, without destroying it
+  tfctl state rm my-resource aws_s3_bucket.bucket
+`
+
+func buildStateRmCmd(app *tfctl.CLI) *cobra.Command {
+	return &cobra.Command{
+		Use:     "rm NAME ADDRESS...",
+		Short:   "Remove resources from the Terraform state",
+		Example: strings.Trim(stateRmExamples, "\n"),
+		Args:    cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.StateOperation(cmd.Context(), os.Stdout, args[0], infrav1.StateOperationRm, args[1:])
+		},
+	}
+}
+
+var importExamples = `
+  # Import an existing bucket into the state of a Terraform resource
+  tfctl import my-resource aws_s3_bucket.bucket my-bucket-name
+`
+
+func buildImportCmd(app *tfctl.CLI) *cobra.Command {
+	return &cobra.Command{
+		Use:     "import NAME ADDRESS ID",
+		Short:   "Import an existing resource into the Terraform state",
+		Example: strings.Trim(importExamples, "\n"),
+		Args:    cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.StateOperation(cmd.Context(), os.Stdout, args[0], infrav1.StateOperationImport, args[1:])
+		},
+	}
+}
+
+var stateRestoreExamples = `
+  # Restore the state of a Terraform resource as it was before the apply of a source revision
+  tfctl state restore my-resource --to main@sha1:8cb43fe
+
+  # Restore the latest snapshot of the state taken before the given time
+  tfctl state restore my-resource --to 2024-01-01T12:00:00Z
+
+  # Restore a snapshot without asking for confirmation
+  tfctl state restore my-resource --to tfstate-backup-default-my-resource-20240101120000-x7k2p --yes
+`
+
+func buildStateRestoreCmd(app *tfctl.CLI) *cobra.Command {
+	restore := &cobra.Command{
+		Use:     "restore NAME",
+		Short:   "Restore the Terraform state from a snapshot",
+		Long:    "Restore the Terraform state of a resource using the Kubernetes backend from a snapshot taken before an apply, after showing the difference in resources",
+		Example: strings.Trim(stateRestoreExamples, "\n"),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			to, err := cmd.Flags().GetString("to")
+			if err != nil {
+				return err
+			}
+			if to == "" {
+				return errors.New("--to is required")
+			}
+
+			yes, err := cmd.Flags().GetBool("yes")
+			if err != nil {
+				return err
+			}
+
+			return app.RestoreState(cmd.Context(), os.Stdout, os.Stdin, args[0], to, yes)
+		},
+	}
+
+	restore.Flags().String("to", "", "The snapshot to restore: a snapshot name, a source revision, or an RFC3339 time")
+	restore.Flags().BoolP("yes", "y", false, "Restore without asking for confirmation")
+
+	return restore
+}
+
 var approvePlanExamples = `
   # Approve the plan for a Terraform resource
   tfctl approve my-resource -f manifests/my-resource.yaml
+
+  # Review and approve the plan for a Terraform resource in-cluster, without GitOps
+  tfctl approve my-resource --in-cluster
 `
 
 func buildApprovePlanCmd(app *tfctl.CLI) *cobra.Command {
@@ -255,11 +421,21 @@ func buildApprovePlanCmd(app *tfctl.CLI) *cobra.Command {
 		Example: strings.Trim(approvePlanExamples, "\n"),
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			inCluster, err := cmd.Flags().GetBool("in-cluster")
+			if err != nil {
+				return err
+			}
+			if inCluster {
+				return app.ApprovePlanInCluster(cmd.Context(), os.Stdout, os.Stdin, args[0])
+			}
 			return app.ApprovePlan(os.Stdout, args[0], viper.GetString("filename"))
 		},
 	}
 
 	approvePlan.Flags().StringP("filename", "f", "", "YAML file to approve.")
+	approvePlan.Flags().Bool("in-cluster", false, "Approve the plan on the live object after reviewing it, instead of in a YAML file.")
+	approvePlan.MarkFlagsMutuallyExclusive("filename", "in-cluster")
+	approvePlan.MarkFlagsOneRequired("filename", "in-cluster")
 	viper.BindPFlags(approvePlan.Flags())
 	return approvePlan
 }
@@ -318,38 +494,284 @@ func buildDeleteCmd(app *tfctl.CLI) *cobra.Command {
 	return cmd
 }
 
+var destroyExamples = `
+  # Destroy a Terraform resource and the resources it manages
+  tfctl destroy my-resource
+
+  # Destroy a Terraform resource together with every resource depending on it, leaves first
+  tfctl destroy my-resource --cascade
+`
+
+func buildDestroyCmd(app *tfctl.CLI) *cobra.Command {
+	destroy := &cobra.Command{
+		Use:     "destroy NAME",
+		Short:   "Destroy a Terraform resource and the resources it manages",
+		Example: strings.Trim(destroyExamples, "\n"),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cascade, err := cmd.Flags().GetBool("cascade")
+			if err != nil {
+				return err
+			}
+
+			timeout, err := cmd.Flags().GetDuration("timeout")
+			if err != nil {
+				return err
+			}
+
+			return app.Destroy(cmd.Context(), os.Stdout, args[0], cascade, timeout)
+		},
+	}
+
+	destroy.Flags().Bool("cascade", false, "Destroy the dependants of the resource too, walking the dependency graph in reverse")
+	destroy.Flags().Duration("timeout", 30*time.Minute, "The time to wait for the resources to be destroyed")
+
+	return destroy
+}
+
+var driftExamples = `
+  # Show the drift found by the last drift detection of a Terraform resource
+  tfctl drift my-resource
+
+  # Summarize the drift of all Terraform resources in the namespace
+  tfctl drift --all
+
+  # Summarize the drift of all Terraform resources across all namespaces
+  tfctl drift --all -A
+`
+
+func buildDriftCmd(app *tfctl.CLI) *cobra.Command {
+	drift := &cobra.Command{
+		Use:     "drift [NAME]",
+		Short:   "Show the drift detected on Terraform resources",
+		Example: strings.Trim(driftExamples, "\n"),
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, err := cmd.Flags().GetBool("all")
+			if err != nil {
+				return err
+			}
+
+			allNamespaces, err := cmd.Flags().GetBool("all-namespaces")
+			if err != nil {
+				return err
+			}
+
+			if all || allNamespaces {
+				if len(args) > 0 {
+					return errors.New("a resource name cannot be used with --all")
+				}
+				return app.DriftSummary(os.Stdout, allNamespaces)
+			}
+
+			if len(args) == 0 {
+				return errors.New("a resource name or --all is required")
+			}
+			return app.Drift(os.Stdout, args[0])
+		},
+	}
+
+	drift.Flags().Bool("all", false, "Summarize the drift of all Terraform resources")
+	drift.Flags().BoolP("all-namespaces", "A", false, "Summarize the drift of all Terraform resources across all namespaces")
+
+	return drift
+}
+
 var createExamples = `
   # Create a Terraform resource in the default namespace
   tfctl create -n default my-resource --source GitRepository/my-project --path ./terraform --interval 15m
 
   # Generate a Terraform resource manifest
   tfctl create -n default my-resource --source GitRepository/my-project --path ./terraform --interval 15m --export
+
+  # Generate a Terraform resource manifest with the variables of a local checkout of the module,
+  # an s3 backend and the outputs written to a Secret
+  tfctl create my-resource --source GitRepository/my-project --path ./terraform --interval 15m \
+    --module-path ./terraform --var region=eu-west-1 --vars-from Secret/aws-credentials \
+    --backend s3 --backend-config bucket=my-state --backend-config key=my-resource.tfstate --backend-config region=eu-west-1 \
+    --write-outputs-to-secret my-resource-outputs --depends-on network --export
+
+  # Create a Terraform resource interactively, asking for the values of the variables of the module
+  tfctl create my-resource --module-path ./terraform --interactive
 `
 
 func buildCreateCmd(app *tfctl.CLI) *cobra.Command {
 	create := &cobra.Command{
-		Use:     "create NAME",
-		Short:   "Create a Terraform resource",
+		Use:   "create NAME",
+		Short: "Create a Terraform resource",
+		Long: `Create a Terraform resource, or print its manifest with --export. The resource is validated
+against the schema of the Terraform CRD before it is created or printed.
+
+With --module-path, the variables declared by a local checkout of the module are added to the vars,
+with their default values unless set with --var. The values of --var are converted to the types of the
+variables: a string variable takes the value as is, the other types a JSON value.`,
 		Example: strings.Trim(createExamples, "\n"),
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return app.Create(os.Stdout,
-				args[0],
-				viper.GetString("namespace"),
-				viper.GetString("path"),
-				viper.GetString("source"),
-				viper.GetString("interval"),
-				viper.GetBool("export"))
+			flags := cmd.Flags()
+			opts := tfctl.CreateOptions{}
+			var err error
+			for name, value := range map[string]*string{
+				"source":                  &opts.Source,
+				"path":                    &opts.Path,
+				"interval":                &opts.Interval,
+				"module-path":             &opts.ModulePath,
+				"write-outputs-to-secret": &opts.OutputsSecret,
+				"backend":                 &opts.BackendType,
+				"workspace":               &opts.Workspace,
+				"approve-plan":            &opts.ApprovePlan,
+				"runner-pod-template":     &opts.RunnerPodTemplate,
+			} {
+				if *value, err = flags.GetString(name); err != nil {
+					return err
+				}
+			}
+			for name, value := range map[string]*[]string{
+				"var":            &opts.Vars,
+				"vars-from":      &opts.VarsFrom,
+				"outputs":        &opts.Outputs,
+				"backend-config": &opts.BackendConfig,
+				"depends-on":     &opts.DependsOn,
+			} {
+				if *value, err = flags.GetStringArray(name); err != nil {
+					return err
+				}
+			}
+			if opts.Interactive, err = flags.GetBool("interactive"); err != nil {
+				return err
+			}
+			if opts.Export, err = flags.GetBool("export"); err != nil {
+				return err
+			}
+
+			return app.Create(os.Stdin, os.Stdout, args[0], opts)
 		},
 	}
-	create.Flags().String("path", "", "")
-	create.Flags().String("source", "", "")
-	create.Flags().String("interval", "", "")
+	create.Flags().String("source", "", "The source of the module, as GitRepository, Bucket or OCIRepository/name")
+	create.Flags().String("path", "", "The path of the module in the source")
+	create.Flags().String("interval", "", "The reconciliation interval, e.g. 15m")
+	create.Flags().StringArray("var", nil, "A variable, as name=value")
+	create.Flags().StringArray("vars-from", nil, "A Secret or ConfigMap to read the variables from, as Secret/name or ConfigMap/name, with :key,key to select keys")
+	create.Flags().String("module-path", "", "A local checkout of the module, which variables are added to the vars with their default values")
+	create.Flags().String("write-outputs-to-secret", "", "The Secret to write the outputs to")
+	create.Flags().StringArray("outputs", nil, "An output to write to the Secret, all of them by default")
+	create.Flags().String("backend", tfctl.BackendKubernetes, fmt.Sprintf("The backend type, one of: %s", strings.Join([]string{
+		tfctl.BackendKubernetes, tfctl.BackendS3, tfctl.BackendGCS, tfctl.BackendAzureRM, tfctl.BackendPG, tfctl.BackendSharded, tfctl.BackendNone}, ", ")))
+	create.Flags().StringArray("backend-config", nil, "A setting of the backend, as key=value with the field names of spec.backendConfig or of the backend, e.g. bucket=my-state for s3")
+	create.Flags().String("workspace", "", "The Terraform workspace")
+	create.Flags().String("approve-plan", "", `The approval mode, "auto" to approve the plans automatically, or empty to approve them manually`)
+	create.Flags().String("runner-pod-template", "", "A YAML file with the runner pod template, with its metadata and spec")
+	create.Flags().StringArray("depends-on", nil, "A Terraform resource to depend on, as name or namespace/name")
+	create.Flags().BoolP("interactive", "i", false, "Ask for the source, path, interval, approval mode and the values of the variables of the module")
 	create.Flags().Bool("export", false, "Print generated Terraform resource to stdout")
-	viper.BindPFlags(create.Flags())
 	return create
 }
 
+var diffExamples = `
+  # Show what the controller would plan for a Terraform resource with the local configuration
+  tfctl diff my-resource --path .
+`
+
+func buildDiffCmd(app *tfctl.CLI) *cobra.Command {
+	diff := &cobra.Command{
+		Use:     "diff NAME",
+		Short:   "Plan a local configuration with a Terraform resource, without saving or applying the plan",
+		Example: strings.Trim(diffExamples, "\n"),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := cmd.Flags().GetString("path")
+			if err != nil {
+				return err
+			}
+			return app.Diff(cmd.Context(), os.Stdout, args[0], path)
+		},
+	}
+	diff.Flags().String("path", ".", "The local directory to plan, packaged as the root of the source of the resource")
+	return diff
+}
+
+var waitExamples = `
+  # Wait until a Terraform resource is ready
+  tfctl wait my-resource
+
+  # Wait until the commit of a pipeline is applied, failing after 20 minutes
+  tfctl wait my-resource --for=applied --revision $GITHUB_SHA --timeout 20m
+
+  # Wait until the drift of a Terraform resource is corrected
+  tfctl wait my-resource --for=drift-free
+`
+
+func buildWaitCmd(app *tfctl.CLI) *cobra.Command {
+	wait := &cobra.Command{
+		Use:   "wait NAME",
+		Short: "Wait until a Terraform resource is applied, planned, ready or drift-free",
+		Long: `Wait until a Terraform resource is applied, planned, ready or drift-free, printing the changes of
+its conditions. With --revision, wait until the resource reaches the state at the given source
+revision, either in full or as a prefix of the commit sha.
+
+The command fails as soon as the resource reports a failure, such as a failed plan or apply,
+a failed health check or the retry limit being reached, and when the timeout expires.`,
+		Example: strings.Trim(waitExamples, "\n"),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := cmd.Flags().GetString("for")
+			if err != nil {
+				return err
+			}
+
+			revision, err := cmd.Flags().GetString("revision")
+			if err != nil {
+				return err
+			}
+
+			timeout, err := cmd.Flags().GetDuration("timeout")
+			if err != nil {
+				return err
+			}
+
+			return app.Wait(cmd.Context(), os.Stdout, args[0], target, revision, timeout)
+		},
+	}
+
+	wait.Flags().String("for", tfctl.WaitForReady, fmt.Sprintf("The state to wait for, one of: %s, %s, %s, %s",
+		tfctl.WaitForApplied, tfctl.WaitForPlanned, tfctl.WaitForReady, tfctl.WaitForDriftFree))
+	wait.Flags().String("revision", "", "The source revision to wait for, e.g. a commit sha")
+	wait.Flags().Duration("timeout", 20*time.Minute, "The time to wait for the resource to reach the state")
+
+	return wait
+}
+
+var watchExamples = `
+  # Watch the Terraform resources in the default namespace
+  tfctl watch
+
+  # Watch the Terraform resources across all namespaces
+  tfctl watch -A
+`
+
+func buildWatchCmd(app *tfctl.CLI) *cobra.Command {
+	watch := &cobra.Command{
+		Use:   "watch",
+		Short: "Watch the Terraform resources and the runner logs in a terminal dashboard",
+		Long: `Watch the Terraform resources in a terminal dashboard, with the phase, pending plan, drift
+and last applied revision of each resource, and the runner logs of the selected resource.
+
+Select a resource with the arrow keys, then press r to reconcile, s to suspend, u to resume,
+or a to review and approve its pending plan. Press q to quit.`,
+		Example: strings.Trim(watchExamples, "\n"),
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			allNamespaces, err := cmd.Flags().GetBool("all-namespaces")
+			if err != nil {
+				return err
+			}
+			return app.Watch(cmd.Context(), os.Stdin, os.Stdout, allNamespaces)
+		},
+	}
+	watch.Flags().BoolP("all-namespaces", "A", false, "Watch the Terraform resources across all namespaces")
+	return watch
+}
+
 var forceUnlockExample = `
 	# Unlock Terraform resource "aws-security-group" with lock id "f2ab685b-f84d-ac0b-a125-378a22877e8d" in the default namespace
 	tfctl force-unlock aws-security-group -n default --lock-id="f2ab685b-f84d-ac0b-a125-378a22877e8d"
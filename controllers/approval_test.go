@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+func TestEvaluateApprovals(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	approval := func(name, terraform, planID, approver string, groups []string, age time.Duration) infrav1.PlanApproval {
+		return infrav1.PlanApproval{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			Spec: infrav1.PlanApprovalSpec{
+				TerraformRef: meta.LocalObjectReference{Name: terraform},
+				PlanID:       planID,
+				Approver:     approver,
+				Groups:       groups,
+			},
+		}
+	}
+
+	approvals := []infrav1.PlanApproval{
+		approval("bob", "my-tf", "plan-main-1234", "bob", []string{"platform"}, time.Minute),
+		approval("alice", "my-tf", "plan-main-1234", "alice", nil, 2*time.Minute),
+		approval("alice-again", "my-tf", "plan-main-1234", "alice", nil, 0),
+		approval("eve", "my-tf", "plan-main-1234", "eve", []string{"developers"}, 0),
+		approval("carol-stale", "my-tf", "plan-main-0000", "carol", nil, time.Hour),
+		approval("carol-prefix", "my-tf", "plan-main", "carol", nil, time.Hour),
+		approval("dave-other", "other-tf", "plan-main-1234", "dave", nil, time.Hour),
+	}
+
+	// counting the distinct approvers of the exact plan, in order
+	policies := []infrav1.ApprovalPolicySpec{{RequiredApprovals: 2}}
+	status, notAllowed, _ := evaluateApprovals(policies, approvals, "my-tf", "plan-main-1234", nil)
+	g.Expect(status.PlanID).To(Equal("plan-main-1234"))
+	g.Expect(status.RequiredApprovals).To(Equal(2))
+	g.Expect(approvers(status)).To(Equal([]string{"alice", "bob", "eve"}))
+	g.Expect(status.Approvals[0].PlanApprovalName).To(Equal("alice"))
+	g.Expect(notAllowed).To(BeEmpty())
+	g.Expect(status.Approved()).To(BeTrue())
+
+	// only counting the approvers allowed by every policy, by name or group
+	policies = []infrav1.ApprovalPolicySpec{
+		{RequiredApprovals: 2, Approvers: []string{"alice"}, ApproverGroups: []string{"platform"}},
+		{RequiredApprovals: 3},
+	}
+	status, notAllowed, _ = evaluateApprovals(policies, approvals, "my-tf", "plan-main-1234", nil)
+	g.Expect(status.RequiredApprovals).To(Equal(3))
+	g.Expect(approvers(status)).To(Equal([]string{"alice", "bob"}))
+	g.Expect(notAllowed).To(Equal([]string{"eve"}))
+	g.Expect(status.Approved()).To(BeFalse())
+
+	// rejecting the approvals given before the plan expired and was planned again with the same id
+	expiredPlan := &infrav1.ExpiredPlanStatus{PlanID: "plan-main-1234", ExpiredAt: metav1.NewTime(now.Add(-90 * time.Second))}
+	status, _, expired := evaluateApprovals([]infrav1.ApprovalPolicySpec{{RequiredApprovals: 2}}, approvals, "my-tf", "plan-main-1234", expiredPlan)
+	g.Expect(approvers(status)).To(Equal([]string{"bob", "alice", "eve"}))
+	g.Expect(status.Approvals[1].PlanApprovalName).To(Equal("alice-again"))
+	g.Expect(expired).To(Equal([]string{"alice"}))
+}
+
+func TestParseApprovalPolicy(t *testing.T) {
+	g := NewWithT(t)
+
+	policy, err := parseApprovalPolicy(map[string]string{
+		infrav1.ApprovalPolicyRequiredApprovalsKey: " 2 ",
+		infrav1.ApprovalPolicyApproversKey:         "alice, bob,",
+		infrav1.ApprovalPolicyApproverGroupsKey:    "platform",
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(policy).To(Equal(infrav1.ApprovalPolicySpec{
+		RequiredApprovals: 2,
+		Approvers:         []string{"alice", "bob"},
+		ApproverGroups:    []string{"platform"},
+	}))
+
+	_, err = parseApprovalPolicy(map[string]string{infrav1.ApprovalPolicyApproversKey: "alice"})
+	g.Expect(err).To(HaveOccurred())
+	_, err = parseApprovalPolicy(map[string]string{infrav1.ApprovalPolicyRequiredApprovalsKey: "0"})
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/fluxcd/pkg/apis/meta"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRenderTypedBackendConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	r := &TerraformReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "aws-credentials", Namespace: "flux-system"},
+			Data: map[string][]byte{
+				"access_key": []byte("AKIA\n"),
+				"secret_key": []byte("s3cr${et}"),
+			},
+		}).Build(),
+	}
+
+	terraform := infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "helloworld", Namespace: "flux-system"},
+		Spec: infrav1.TerraformSpec{
+			BackendConfig: &infrav1.BackendConfigSpec{
+				S3: &infrav1.S3BackendSpec{
+					Bucket:               "my-bucket",
+					Key:                  "helloworld.tfstate",
+					Region:               "eu-west-1",
+					Encrypt:              true,
+					RoleARN:              "arn:aws:iam::123456789012:role/terraform",
+					CredentialsSecretRef: &meta.LocalObjectReference{Name: "aws-credentials"},
+				},
+			},
+		},
+	}
+
+	config, err := r.renderTypedBackendConfig(context.TODO(), terraform)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(config).To(Equal(`
+terraform {
+  backend "s3" {
+    bucket      = "my-bucket"
+    key         = "helloworld.tfstate"
+    region      = "eu-west-1"
+    encrypt     = true
+    assume_role = { role_arn = "arn:aws:iam::123456789012:role/terraform" }
+    access_key  = "AKIA"
+    secret_key  = "s3cr$${et}"
+  }
+}
+`))
+
+	terraform.Spec.BackendConfig.GCS = &infrav1.GCSBackendSpec{Bucket: "my-bucket"}
+	_, err = r.renderTypedBackendConfig(context.TODO(), terraform)
+	g.Expect(err).To(MatchError(ContainSubstring("only one backend can be specified in backendConfig, got: s3, gcs")))
+}
+
+func TestBackendChanged(t *testing.T) {
+	g := NewWithT(t)
+
+	terraform := infrav1.Terraform{
+		Spec: infrav1.TerraformSpec{
+			BackendConfig: &infrav1.BackendConfigSpec{
+				S3: &infrav1.S3BackendSpec{Bucket: "bucket", Key: "key", Region: "us-east-1"},
+			},
+		},
+	}
+
+	By("not reporting a change when no backend is recorded.")
+	g.Expect(backendChanged(terraform)).To(BeFalse())
+
+	By("not reporting a change when the recorded backend is the same.")
+	terraform.Status.Backend = &infrav1.BackendStatus{Config: terraform.Spec.BackendConfig.DeepCopy()}
+	g.Expect(backendChanged(terraform)).To(BeFalse())
+
+	By("reporting a change from the default backend.")
+	terraform.Status.Backend = &infrav1.BackendStatus{}
+	g.Expect(backendChanged(terraform)).To(BeTrue())
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/fluxcd/pkg/apis/meta"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCascadeDestroy(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	scheme := runtime.NewScheme()
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	now := metav1.Now()
+	network := &infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "network",
+			Namespace:         "dev",
+			DeletionTimestamp: &now,
+			Annotations:       map[string]string{infrav1.CascadeDestroyAnnotation: "2024-01-01T00:00:00Z"},
+			Finalizers: []string{
+				infrav1.TerraformFinalizer,
+				infrav1.TFDependencyOfPrefix + "app",
+				infrav1.TFDependencyOfPrefix + "db",
+			},
+		},
+	}
+	dependsOnNetwork := []meta.NamespacedObjectReference{{Name: "network"}}
+	// app has no finalizer, so it is gone as soon as it is deleted
+	app := &infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "dev"},
+		Spec:       infrav1.TerraformSpec{DependsOn: dependsOnNetwork},
+	}
+	// db is held by its finalizer until it has destroyed its resources
+	db := &infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "dev", Finalizers: []string{infrav1.TerraformFinalizer}},
+		Spec:       infrav1.TerraformSpec{DependsOn: dependsOnNetwork},
+	}
+	other := &infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "prod"},
+		Spec:       infrav1.TerraformSpec{DependsOn: []meta.NamespacedObjectReference{{Name: "network", Namespace: "dev"}}},
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	r := &TerraformReconciler{
+		Client:               fake.NewClientBuilder().WithScheme(scheme).WithObjects(network, app, db, other).WithStatusSubresource(&infrav1.Terraform{}).Build(),
+		Scheme:               scheme,
+		EventRecorder:        recorder,
+		NoCrossNamespaceRefs: true,
+	}
+
+	By("listing the dependants of every namespace, unless cross-namespace references are disabled.")
+	dependants, err := r.getDependants(ctx, *network)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(dependants).To(HaveLen(2))
+	r.NoCrossNamespaceRefs = false
+	dependants, err = r.getDependants(ctx, *network)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(dependants).To(HaveLen(3))
+	r.NoCrossNamespaceRefs = true
+
+	dependantFinalizers := []string{"app", "db"}
+	By("deleting the dependants in order, with the cascade destroy annotation.")
+	result, err := r.cascadeDestroy(ctx, *network, dependantFinalizers)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).ToNot(BeZero())
+	g.Expect(<-recorder.Events).To(ContainSubstring("Cascade destroy: deleting dependant dev/app"))
+	g.Expect(<-recorder.Events).To(ContainSubstring("Cascade destroy: deleting dependant dev/db"))
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	g.Expect(apierrors.IsNotFound(r.Get(ctx, client.ObjectKeyFromObject(app), &infrav1.Terraform{}))).To(BeTrue())
+	var deleting infrav1.Terraform
+	g.Expect(r.Get(ctx, client.ObjectKeyFromObject(db), &deleting)).To(Succeed())
+	g.Expect(deleting.DeletionTimestamp).ToNot(BeNil())
+	g.Expect(deleting.Annotations).To(HaveKeyWithValue(infrav1.CascadeDestroyAnnotation, "2024-01-01T00:00:00Z"))
+
+	By("leaving the dependants of other namespaces alone.")
+	var untouched infrav1.Terraform
+	g.Expect(r.Get(ctx, client.ObjectKeyFromObject(other), &untouched)).To(Succeed())
+	g.Expect(untouched.DeletionTimestamp).To(BeNil())
+	g.Expect(untouched.Annotations).ToNot(HaveKey(infrav1.CascadeDestroyAnnotation))
+
+	By("waiting for the dependants being deleted.")
+	var root infrav1.Terraform
+	g.Expect(r.Get(ctx, client.ObjectKeyFromObject(network), &root)).To(Succeed())
+	ready := apimeta.FindStatusCondition(root.Status.Conditions, meta.ReadyCondition)
+	g.Expect(ready).ToNot(BeNil())
+	g.Expect(ready.Reason).To(Equal(infrav1.CascadeDestroyInProgressReason))
+	g.Expect(ready.Message).To(HaveSuffix("dev/app, dev/db"))
+
+	_, err = r.cascadeDestroy(ctx, root, dependantFinalizers)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(recorder.Events).To(BeEmpty())
+	g.Expect(r.Get(ctx, client.ObjectKeyFromObject(network), &root)).To(Succeed())
+	g.Expect(apimeta.FindStatusCondition(root.Status.Conditions, meta.ReadyCondition).Message).To(HaveSuffix("dev/db"))
+
+	By("releasing the root once the dependants have released their finalizers.")
+	g.Expect(r.releaseDependencies(*app)).To(Succeed())
+	g.Expect(r.releaseDependencies(deleting)).To(Succeed())
+	g.Expect(r.Get(ctx, client.ObjectKeyFromObject(network), &root)).To(Succeed())
+	g.Expect(root.Finalizers).To(Equal([]string{infrav1.TerraformFinalizer}))
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHandleDiff(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	artifact := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tfdiff-my-resource-1", Namespace: "default"},
+		Data:       map[string][]byte{infrav1.DiffArtifactDataKey: []byte("not a tar.gz")},
+	}
+	recorder := record.NewFakeRecorder(10)
+	r := &TerraformReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(artifact).Build(),
+		EventRecorder: recorder,
+	}
+
+	terraform := infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-resource",
+			Namespace: "default",
+			Annotations: map[string]string{
+				infrav1.DiffAnnotation:            `{"id":"1","artifactSecretName":"tfdiff-my-resource-1","requestedBy":"mallory"}`,
+				infrav1.DiffRequestedByAnnotation: "alice",
+			},
+		},
+	}
+
+	req, err := terraform.GetDiffRequest()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	By("refusing to plan a Secret which is not the configuration of a diff of the object.")
+	terraform = r.handleDiff(context.TODO(), nil, terraform, *req, "main@sha1:1234", "loop")
+	g.Expect(terraform.Status.Diff.ID).To(Equal("1"))
+	g.Expect(terraform.Status.Diff.Succeeded).To(BeFalse())
+	g.Expect(terraform.Status.Diff.Message).To(Equal("secret tfdiff-my-resource-1 is not labelled as the configuration of a diff of my-resource"))
+	g.Expect(terraform.Status.Diff.RequestedBy).To(Equal("alice"))
+	g.Expect(<-recorder.Events).To(ContainSubstring("alice requested a diff"))
+
+	By("not deleting a Secret which is not the configuration of a diff of the object.")
+	g.Expect(r.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "tfdiff-my-resource-1"}, &corev1.Secret{})).To(Succeed())
+
+	By("deleting the configuration of a diff once planned.")
+	artifact.Labels = map[string]string{infrav1.DiffLabel: "my-resource"}
+	g.Expect(r.Update(context.TODO(), artifact)).To(Succeed())
+	terraform.Spec.Cloud = &infrav1.CloudSpec{Organization: "org"}
+	terraform = r.handleDiff(context.TODO(), nil, terraform, *req, "main@sha1:1234", "loop")
+	g.Expect(terraform.Status.Diff.Message).To(Equal("diff is not supported with Terraform Cloud"))
+	err = r.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "tfdiff-my-resource-1"}, &corev1.Secret{})
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	<-recorder.Events
+
+	By("not running a handled request again.")
+	req, err = terraform.GetDiffRequest()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(req).To(BeNil())
+
+	By("rejecting a request without configuration.")
+	terraform.Annotations[infrav1.DiffAnnotation] = `{"id":"2"}`
+	_, err = terraform.GetDiffRequest()
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestRejectDiffRequest(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &TerraformReconciler{EventRecorder: recorder}
+
+	terraform := infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-resource",
+			Namespace: "default",
+			Annotations: map[string]string{
+				infrav1.DiffAnnotation:            `{"id":`,
+				infrav1.DiffRequestedByAnnotation: "alice",
+			},
+		},
+	}
+
+	By("reporting a diff request which does not parse.")
+	_, err := terraform.GetDiffRequest()
+	g.Expect(err).To(HaveOccurred())
+	terraform = r.rejectDiffRequest(context.TODO(), terraform, err, "main@sha1:1234")
+	g.Expect(terraform.Status.Diff.Succeeded).To(BeFalse())
+	g.Expect(terraform.Status.Diff.RequestedBy).To(Equal("alice"))
+	g.Expect(terraform.Status.Diff.RejectedRequest).To(Equal(`{"id":`))
+	g.Expect(<-recorder.Events).To(ContainSubstring("unable to parse the " + infrav1.DiffAnnotation + " annotation"))
+
+	By("ignoring the same request once reported.")
+	req, err := terraform.GetDiffRequest()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(req).To(BeNil())
+
+	By("reporting a new request without an artifact.")
+	terraform.Annotations[infrav1.DiffAnnotation] = `{"id":"2"}`
+	_, err = terraform.GetDiffRequest()
+	g.Expect(err).To(MatchError(ContainSubstring("requires an id and an artifactSecretName")))
+	terraform = r.rejectDiffRequest(context.TODO(), terraform, err, "main@sha1:1234")
+	g.Expect(terraform.Status.Diff.RejectedRequest).To(Equal(`{"id":"2"}`))
+	g.Expect(<-recorder.Events).To(ContainSubstring("requires an id"))
+	req, err = terraform.GetDiffRequest()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(req).To(BeNil())
+
+	By("handling a valid request.")
+	terraform.Annotations[infrav1.DiffAnnotation] = `{"id":"2","artifactSecretName":"tfdiff-my-resource-2"}`
+	req, err = terraform.GetDiffRequest()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(req.ID).To(Equal("2"))
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"testing"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	tfjson "github.com/hashicorp/terraform-json"
+	. "github.com/onsi/gomega"
+)
+
+func TestEvaluateDriftPolicy(t *testing.T) {
+	g := NewWithT(t)
+
+	update := func(address string, before, after map[string]interface{}) *tfjson.ResourceChange {
+		return &tfjson.ResourceChange{
+			Address: address,
+			Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionUpdate}, Before: before, After: after},
+		}
+	}
+
+	changes := []*tfjson.ResourceChange{
+		update("aws_autoscaling_group.web", map[string]interface{}{"desired_capacity": 3.0, "max_size": 5.0}, map[string]interface{}{"desired_capacity": 2.0, "max_size": 5.0}),
+		update("aws_autoscaling_group.api", map[string]interface{}{"desired_capacity": 3.0, "max_size": 5.0}, map[string]interface{}{"desired_capacity": 2.0, "max_size": 4.0}),
+		update(`aws_s3_bucket.logs["eu"]`, map[string]interface{}{"tags": map[string]interface{}{"team": "a"}}, map[string]interface{}{"tags": map[string]interface{}{}}),
+		update("module.network.aws_vpc.main", map[string]interface{}{"cidr_block": "10.1.0.0/16"}, map[string]interface{}{"cidr_block": "10.0.0.0/16"}),
+		{
+			Address: "aws_iam_role.ci",
+			Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionCreate}, After: map[string]interface{}{"name": "ci"}},
+		},
+		{
+			Address: "aws_instance.noop",
+			Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionNoop}},
+		},
+	}
+
+	policy := &infrav1.DriftPolicySpec{
+		Rules: []infrav1.DriftRule{
+			{Addresses: []string{"aws_autoscaling_group.*"}, Attributes: []string{"desired_capacity"}, Action: infrav1.DriftActionIgnore},
+			{Addresses: []string{`aws_s3_bucket.logs["*"]`}, Attributes: []string{"tags"}, Action: infrav1.DriftActionIgnore},
+			{Addresses: []string{"module.network.*"}, Action: infrav1.DriftActionAlert},
+		},
+	}
+
+	// classifying each resource by the strongest action of its changed attributes
+	drift := evaluateDriftPolicy(policy, changes)
+	g.Expect(drift.Ignore).To(Equal([]string{"aws_autoscaling_group.web", `aws_s3_bucket.logs["eu"]`}))
+	g.Expect(drift.Alert).To(Equal([]string{"module.network.aws_vpc.main"}))
+	g.Expect(drift.Correct).To(Equal([]string{"aws_autoscaling_group.api", "aws_iam_role.ci"}))
+
+	// targeting only the resources to correct
+	terraform := infrav1.Terraform{
+		Spec:   infrav1.TerraformSpec{DriftPolicy: policy},
+		Status: infrav1.TerraformStatus{Drift: &drift},
+	}
+	drift.Revision = "main@sha1:1234"
+	g.Expect(driftCorrectionTargets(terraform, "main@sha1:1234")).To(Equal(drift.Correct))
+	g.Expect(shouldCorrectDrift(terraform)).To(BeTrue())
+
+	// keeping the resources to correct within the targets of the spec
+	terraform.Spec.Targets = []string{"aws_iam_role.ci", "module.network"}
+	g.Expect(driftCorrectionTargets(terraform, "main@sha1:1234")).To(Equal([]string{"aws_iam_role.ci"}))
+	g.Expect(driftCorrectionTargets(terraform, "main@sha1:5678")).To(Equal(terraform.Spec.Targets))
+	terraform.Spec.Targets = []string{"aws_autoscaling_group.api[0]", "module.network"}
+	g.Expect(driftCorrectionTargets(terraform, "main@sha1:1234")).To(Equal(terraform.Spec.Targets))
+	terraform.Spec.Targets = []string{"aws_autoscaling_group.api"}
+	g.Expect(isTargeted(terraform.Spec.Targets, "aws_autoscaling_group.api[0]")).To(BeTrue())
+	g.Expect(isTargeted(terraform.Spec.Targets, "aws_autoscaling_group.api_v2")).To(BeFalse())
+	g.Expect(isTargeted([]string{"module.app"}, `module.app["eu"].aws_s3_bucket.logs`)).To(BeTrue())
+
+	// only alerting when there is nothing to correct
+	policy.Default = infrav1.DriftActionAlert
+	drift = evaluateDriftPolicy(policy, changes)
+	terraform.Status.Drift = &drift
+	g.Expect(drift.Correct).To(BeEmpty())
+	g.Expect(shouldCorrectDrift(terraform)).To(BeFalse())
+
+	// ignoring everything
+	policy.Default = infrav1.DriftActionIgnore
+	drift = evaluateDriftPolicy(policy, changes)
+	g.Expect(drift.Correct).To(BeEmpty())
+	g.Expect(drift.Alert).To(Equal([]string{"module.network.aws_vpc.main"}))
+	policy.Rules = policy.Rules[:2]
+	drift = evaluateDriftPolicy(policy, changes)
+	g.Expect(drift.Alert).To(BeEmpty())
+	g.Expect(drift.Ignore).To(HaveLen(5))
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	tfjson "github.com/hashicorp/terraform-json"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDriftReport(t *testing.T) {
+	g := NewWithT(t)
+
+	changes := []*tfjson.ResourceChange{
+		{
+			Address: "aws_db_instance.main",
+			Change: &tfjson.Change{
+				Actions:         tfjson.Actions{tfjson.ActionUpdate},
+				Before:          map[string]interface{}{"instance_class": "db.t3.large", "password": "old", "tags": map[string]interface{}{"team": "a"}},
+				After:           map[string]interface{}{"instance_class": "db.t3.micro", "password": "new", "tags": map[string]interface{}{"team": "a"}},
+				AfterUnknown:    map[string]interface{}{"endpoint": true},
+				BeforeSensitive: map[string]interface{}{"password": true},
+				AfterSensitive:  map[string]interface{}{"password": true},
+			},
+		},
+		{
+			Address: "aws_instance.noop",
+			Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionNoop}},
+		},
+		{
+			Address: "aws_s3_bucket.big",
+			Change: &tfjson.Change{
+				Actions: tfjson.Actions{tfjson.ActionUpdate},
+				Before:  map[string]interface{}{"policy": ""},
+				After:   map[string]interface{}{"policy": strings.Repeat("x", 2*infrav1.DriftReportMaxValueLength)},
+			},
+		},
+	}
+	policy := &infrav1.DriftPolicySpec{
+		Rules: []infrav1.DriftRule{{Addresses: []string{"aws_s3_bucket.*"}, Action: infrav1.DriftActionAlert}},
+	}
+
+	By("reporting the changed attributes, redacting the sensitive values and shortening the long ones.")
+	report := buildDriftReport(policy, changes, "main@sha1:1234", metav1.Now())
+	g.Expect(report.Revision).To(Equal("main@sha1:1234"))
+	g.Expect(report.Resources).To(HaveLen(2))
+	g.Expect(report.Resources[0]).To(Equal(infrav1.DriftedResource{
+		Address:      "aws_db_instance.main",
+		Actions:      []string{"update"},
+		PolicyAction: infrav1.DriftActionCorrect,
+		Attributes: []infrav1.DriftedAttribute{
+			{Name: "endpoint", After: infrav1.DriftReportUnknownValue},
+			{Name: "instance_class", Before: `"db.t3.large"`, After: `"db.t3.micro"`},
+			{Name: "password", Before: infrav1.DriftReportSensitiveValue, After: infrav1.DriftReportSensitiveValue},
+		},
+	}))
+	g.Expect(report.Resources[1].PolicyAction).To(Equal(infrav1.DriftActionAlert))
+	g.Expect(report.Resources[1].Attributes[0].After).To(HaveLen(infrav1.DriftReportMaxValueLength + 3))
+	g.Expect(report.Truncated).To(BeTrue())
+
+	By("storing the report in a ConfigMap referenced from the status.")
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+	r := &TerraformReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build(), Scheme: scheme}
+
+	terraform := &infrav1.Terraform{ObjectMeta: metav1.ObjectMeta{Name: "my-resource", Namespace: "default", UID: "1234"}}
+	g.Expect(r.writeDriftReport(context.TODO(), terraform, "main@sha1:1234", &tfjson.Plan{ResourceChanges: changes})).To(Succeed())
+	g.Expect(terraform.Status.DriftReportConfigMapName).To(Equal("tfdrift-report-my-resource"))
+
+	cm := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: "default", Name: "tfdrift-report-my-resource"}
+	g.Expect(r.Get(context.TODO(), key, cm)).To(Succeed())
+	g.Expect(cm.OwnerReferences).To(HaveLen(1))
+	stored := infrav1.DriftReport{}
+	g.Expect(json.Unmarshal([]byte(cm.Data[infrav1.DriftReportDataKey]), &stored)).To(Succeed())
+	g.Expect(stored.Resources).To(HaveLen(2))
+
+	By("deleting the report once the drift is gone.")
+	r.deleteDriftReport(context.TODO(), terraform)
+	g.Expect(terraform.Status.DriftReportConfigMapName).To(BeEmpty())
+	g.Expect(r.Get(context.TODO(), key, cm)).ToNot(Succeed())
+}
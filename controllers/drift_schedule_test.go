@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/runner"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeferDriftDetection(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	r := &TerraformReconciler{driftDetectionLimiter: newDriftDetectionLimiter(1)}
+	terraform := infrav1.Terraform{
+		Spec: infrav1.TerraformSpec{
+			Interval:               metav1.Duration{Duration: time.Minute},
+			DriftDetectionInterval: &metav1.Duration{Duration: time.Hour},
+		},
+	}
+
+	By("running the first drift detection right away.")
+	g.Expect(r.deferDriftDetection(terraform, now)).To(BeNil())
+
+	By("deferring drift detection until the drift detection interval has passed since the last run.")
+	terraform.Status.LastDriftDetectionAt = &metav1.Time{Time: now.Add(-50 * time.Minute)}
+	deferred := r.deferDriftDetection(terraform, now)
+	g.Expect(deferred).ToNot(BeNil())
+	g.Expect(deferred.Error()).To(Equal("drift detection is due at 2024-01-10T12:10:00Z"))
+	g.Expect(deferred.requeueAfter).To(Equal(time.Minute))
+
+	By("following the last detected drift when it is later.")
+	terraform.Status.LastDriftDetectedAt = &metav1.Time{Time: now.Add(-20 * time.Minute)}
+	g.Expect(terraform.NextDriftDetectionAt()).To(Equal(now.Add(40 * time.Minute)))
+
+	By("running drift detection once it is due.")
+	g.Expect(r.deferDriftDetection(terraform, now.Add(40*time.Minute))).To(BeNil())
+
+	By("running drift detection at every reconciliation without a drift detection interval.")
+	terraform.Spec.DriftDetectionInterval = nil
+	g.Expect(r.deferDriftDetection(terraform, now)).To(BeNil())
+}
+
+type panickingRunnerClient struct {
+	runner.RunnerClient
+}
+
+func (c *panickingRunnerClient) Plan(ctx context.Context, in *runner.PlanRequest, opts ...grpc.CallOption) (*runner.PlanReply, error) {
+	panic("plan failed")
+}
+
+func TestDetectDriftWhenDue(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	r := &TerraformReconciler{driftDetectionLimiter: newDriftDetectionLimiter(1)}
+	terraform := infrav1.Terraform{
+		Spec: infrav1.TerraformSpec{Interval: metav1.Duration{Duration: time.Minute}},
+	}
+
+	By("deferring drift detection while all slots are taken.")
+	g.Expect(r.driftDetectionLimiter.tryAcquire()).To(BeTrue())
+	_, err := r.detectDriftWhenDue(context.TODO(), terraform, "instance", &panickingRunnerClient{}, "main@sha1:1234", "/tmp/dir", now)
+	var deferred *driftDetectionDeferredError
+	g.Expect(errors.As(err, &deferred)).To(BeTrue())
+	g.Expect(deferred.requeueAfter).To(Equal(driftDetectionBusyRequeue))
+	r.driftDetectionLimiter.release()
+
+	By("releasing the slot even when drift detection panics.")
+	g.Expect(func() {
+		_, _ = r.detectDriftWhenDue(context.TODO(), terraform, "instance", &panickingRunnerClient{}, "main@sha1:1234", "/tmp/dir", now)
+	}).To(PanicWith("plan failed"))
+	g.Expect(r.driftDetectionLimiter).To(BeEmpty())
+}
+
+func TestEndDriftDetectionSpan(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	for _, err := range []error{nil, errors.New(infrav1.DriftDetectedReason), errors.New("plan failed")} {
+		_, span := tracer.Start(context.TODO(), "drift-detection")
+		endDriftDetectionSpan(span, err)
+	}
+
+	spans := recorder.Ended()
+	g.Expect(spans).To(HaveLen(3))
+
+	By("recording no drift.")
+	g.Expect(spans[0].Attributes()).To(ContainElement(attribute.Bool("drift.detected", false)))
+	g.Expect(spans[0].Status().Code).To(Equal(codes.Unset))
+
+	By("recording detected drift without an error status.")
+	g.Expect(spans[1].Attributes()).To(ContainElement(attribute.Bool("drift.detected", true)))
+	g.Expect(spans[1].Status().Code).To(Equal(codes.Unset))
+	g.Expect(spans[1].Events()).To(BeEmpty())
+
+	By("recording a failed drift detection with an error status.")
+	g.Expect(spans[2].Attributes()).To(ContainElement(attribute.Bool("drift.detected", false)))
+	g.Expect(spans[2].Status()).To(Equal(sdktrace.Status{Code: codes.Error, Description: "plan failed"}))
+}
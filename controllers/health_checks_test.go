@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDNSHealthCheck(t *testing.T) {
+	g := NewWithT(t)
+	r := &TerraformReconciler{}
+
+	hc := infrav1.HealthCheck{
+		Name:    "localhost",
+		Type:    infrav1.HealthCheckTypeDNS,
+		Address: "${{ .host }}",
+		DNS: &infrav1.DNSHealthCheck{
+			ExpectedRecords: []string{"127.0.0.1"},
+		},
+	}
+	g.Expect(r.doDNSHealthCheck(context.TODO(), hc, map[string]string{"host": "localhost"})).To(Succeed())
+
+	hc.DNS.ExpectedRecords = []string{"127.0.0.2"}
+	g.Expect(r.doDNSHealthCheck(context.TODO(), hc, map[string]string{"host": "localhost"})).
+		To(MatchError(ContainSubstring(`expected A record "127.0.0.2" not found`)))
+}
+
+func TestGRPCHealthCheck(t *testing.T) {
+	g := NewWithT(t)
+	r := &TerraformReconciler{}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("ready", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("not-ready", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	hc := infrav1.HealthCheck{
+		Name:    "grpc",
+		Type:    infrav1.HealthCheckTypeGRPC,
+		Address: lis.Addr().String(),
+		Timeout: &metav1.Duration{Duration: 5 * time.Second},
+		GRPC:    &infrav1.GRPCHealthCheck{Service: "ready"},
+	}
+	g.Expect(r.doGRPCHealthCheck(context.TODO(), hc, nil)).To(Succeed())
+
+	hc.GRPC.Service = "not-ready"
+	g.Expect(r.doGRPCHealthCheck(context.TODO(), hc, nil)).To(MatchError(ContainSubstring("NOT_SERVING")))
+}
+
+func TestTLSHealthCheckRejectsUntrustedCertificate(t *testing.T) {
+	g := NewWithT(t)
+	r := &TerraformReconciler{}
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	hc := infrav1.HealthCheck{
+		Name:    "tls",
+		Type:    infrav1.HealthCheckTypeTLS,
+		Address: strings.TrimPrefix(srv.URL, "https://"),
+		Timeout: &metav1.Duration{Duration: 5 * time.Second},
+	}
+	g.Expect(r.doTLSHealthCheck(context.TODO(), hc, nil)).To(MatchError(ContainSubstring("certificate")))
+}
+
+func TestHTTPHealthCheckExpectations(t *testing.T) {
+	g := NewWithT(t)
+	r := &TerraformReconciler{}
+
+	var maintenance atomic.Bool
+	maintenance.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if maintenance.Load() {
+			_, _ = w.Write([]byte(`{"status": "maintenance"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": "ok", "version": "1.2.3"}`))
+	}))
+	defer srv.Close()
+
+	hc := infrav1.HealthCheck{
+		Name:    "app",
+		Type:    infrav1.HealthCheckTypeHttpGet,
+		URL:     srv.URL,
+		Timeout: &metav1.Duration{Duration: 200 * time.Millisecond},
+		HTTP: &infrav1.HTTPHealthCheck{
+			ExpectedStatusCodes: []int{http.StatusOK},
+			ExpectedHeaders:     map[string]string{"Content-Type": "^application/json"},
+			BodyRegex:           `"version"`,
+			BodyJSONPath:        ".status",
+			BodyJSONPathValue:   "ok",
+			SuccessThreshold:    2,
+			Interval:            &metav1.Duration{Duration: 10 * time.Millisecond},
+		},
+	}
+
+	By("rejecting a maintenance page answered with 200.")
+	g.Expect(r.doHTTPHealthCheckWithExpectations(context.TODO(), hc, srv.URL)).
+		To(MatchError(ContainSubstring(`does not match "\"version\""`)))
+
+	By("accepting the expected responses.")
+	maintenance.Store(false)
+	g.Expect(r.doHTTPHealthCheckWithExpectations(context.TODO(), hc, srv.URL)).To(Succeed())
+
+	By("rejecting an unexpected JSONPath value.")
+	hc.HTTP.BodyJSONPathValue = "healthy"
+	g.Expect(r.doHTTPHealthCheckWithExpectations(context.TODO(), hc, srv.URL)).
+		To(MatchError(ContainSubstring(`returned "ok", expected "healthy"`)))
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+func TestIsPlanExpired(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &TerraformReconciler{}
+	now := time.Now()
+	terraform := infrav1.Terraform{
+		Spec: infrav1.TerraformSpec{PlanTTL: &metav1.Duration{Duration: 24 * time.Hour}},
+		Status: infrav1.TerraformStatus{
+			Plan:       infrav1.PlanStatus{Pending: "plan-main-1234"},
+			LastPlanAt: &metav1.Time{Time: now.Add(-25 * time.Hour)},
+		},
+	}
+
+	// expiring the plans waiting for their approval
+	g.Expect(terraform.PlanExpiresAt()).To(Equal(now.Add(-time.Hour)))
+	g.Expect(r.isPlanExpired(terraform, now)).To(BeTrue())
+	g.Expect(r.isPlanExpired(terraform, now.Add(-2*time.Hour))).To(BeFalse())
+
+	// not expiring the approved plans, unless they wait for the approval quorum
+	terraform.Spec.ApprovePlan = "plan-main-1234"
+	g.Expect(r.isPlanExpired(terraform, now)).To(BeFalse())
+	terraform.Status.Approval = &infrav1.ApprovalStatus{PlanID: "plan-main-1234", RequiredApprovals: 2}
+	g.Expect(r.isPlanExpired(terraform, now)).To(BeTrue())
+
+	terraform.Spec.ApprovePlan = infrav1.ApprovePlanAutoValue
+	terraform.Status.Approval = nil
+	g.Expect(r.isPlanExpired(terraform, now)).To(BeFalse())
+
+	// not expiring without a plan TTL
+	terraform.Spec.ApprovePlan = ""
+	terraform.Spec.PlanTTL = nil
+	g.Expect(terraform.PlanExpiresAt().IsZero()).To(BeTrue())
+	g.Expect(r.isPlanExpired(terraform, now)).To(BeFalse())
+}
+
+func TestExpiredPlanApproval(t *testing.T) {
+	g := NewWithT(t)
+
+	terraform := infrav1.TerraformPlanExpired(infrav1.Terraform{
+		Spec:   infrav1.TerraformSpec{ApprovePlan: "plan-main-1234"},
+		Status: infrav1.TerraformStatus{Plan: infrav1.PlanStatus{Pending: "plan-main-1234"}},
+	}, "main@sha1:1234", "Plan plan-main-1234 expired")
+	g.Expect(terraform.Status.Plan.Pending).To(BeEmpty())
+	g.Expect(terraform.Status.ExpiredPlan.PlanID).To(Equal("plan-main-1234"))
+
+	// rejecting the approval of the expired plan
+	g.Expect(expiredPlanApproval(terraform)).To(HavePrefix(`approvePlan "plan-main-1234" rejected, plan plan-main-1234 expired at `))
+	terraform.Status.Plan.Pending = "plan-main-5678"
+	g.Expect(expiredPlanApproval(terraform)).To(HaveSuffix(", approve the pending plan plan-main-5678 instead"))
+
+	// accepting the approval of the plan planned again
+	terraform.Status.Plan.Pending = "plan-main-1234"
+	g.Expect(expiredPlanApproval(terraform)).To(BeEmpty())
+	terraform.Spec.ApprovePlan = "plan-main-5678"
+	terraform.Status.Plan.Pending = "plan-main-5678"
+	g.Expect(expiredPlanApproval(terraform)).To(BeEmpty())
+	terraform.Spec.ApprovePlan = infrav1.ApprovePlanAutoValue
+	g.Expect(expiredPlanApproval(terraform)).To(BeEmpty())
+}
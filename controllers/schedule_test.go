@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckApplyHold(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	freezeKey := types.NamespacedName{Namespace: "flux-system", Name: "tf-controller-freeze"}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := &TerraformReconciler{Client: kubeClient, FreezeConfigMap: freezeKey}
+
+	// Wednesday 12:00 UTC
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	terraform := infrav1.Terraform{
+		Spec: infrav1.TerraformSpec{
+			ApprovePlan: infrav1.ApprovePlanAutoValue,
+			Schedule: &infrav1.ScheduleSpec{
+				Windows: []infrav1.MaintenanceWindow{
+					{Cron: "0 22 * * 1-5", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+				},
+			},
+		},
+	}
+
+	By("holding an auto-apply outside the maintenance windows.")
+	hold, err := r.checkApplyHold(context.TODO(), terraform, now)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(hold).ToNot(BeNil())
+	g.Expect(hold.reason).To(Equal(infrav1.OutsideMaintenanceWindowReason))
+	g.Expect(hold.message).To(ContainSubstring("the next window opens at 2024-01-10T22:00:00Z"))
+	g.Expect(hold.requeueAfter(now, time.Hour)).To(Equal(time.Hour))
+	g.Expect(hold.requeueAfter(now, 24*time.Hour)).To(Equal(10 * time.Hour))
+
+	By("allowing the apply inside a window.")
+	hold, err = r.checkApplyHold(context.TODO(), terraform, time.Date(2024, 1, 10, 23, 30, 0, 0, time.UTC))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(hold).To(BeNil())
+
+	By("evaluating the windows in the time zone of the schedule.")
+	terraform.Spec.Schedule.TimeZone = "Asia/Tokyo"
+	hold, err = r.checkApplyHold(context.TODO(), terraform, time.Date(2024, 1, 10, 13, 30, 0, 0, time.UTC))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(hold).To(BeNil())
+	terraform.Spec.Schedule.TimeZone = ""
+
+	By("not holding a manual approval.")
+	manual := *terraform.DeepCopy()
+	manual.Spec.ApprovePlan = "plan-main-1234"
+	hold, err = r.checkApplyHold(context.TODO(), manual, now)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(hold).To(BeNil())
+
+	By("holding the apply during a blackout period, even inside a window.")
+	terraform.Spec.Schedule.Blackouts = []infrav1.BlackoutPeriod{{
+		Start:  metav1.NewTime(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		End:    metav1.NewTime(time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)),
+		Reason: "release day",
+	}}
+	hold, err = r.checkApplyHold(context.TODO(), terraform, time.Date(2024, 1, 10, 23, 30, 0, 0, time.UTC))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(hold.reason).To(Equal(infrav1.BlackoutPeriodReason))
+	g.Expect(hold.message).To(HaveSuffix("release day"))
+
+	By("holding every apply during a change freeze.")
+	g.Expect(kubeClient.Create(context.TODO(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: freezeKey.Namespace, Name: freezeKey.Name},
+		Data: map[string]string{
+			infrav1.ChangeFreezeReasonKey: "incident 42",
+			infrav1.ChangeFreezeUntilKey:  "2024-01-12T00:00:00Z",
+		},
+	})).To(Succeed())
+	hold, err = r.checkApplyHold(context.TODO(), manual, now)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(hold.reason).To(Equal(infrav1.ChangeFreezeReason))
+	g.Expect(hold.message).To(Equal("Plan generated, apply held by a change freeze until 2024-01-12T00:00:00Z: incident 42"))
+
+	By("lifting the change freeze at its end.")
+	hold, err = r.checkApplyHold(context.TODO(), manual, time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(hold).To(BeNil())
+
+	By("reporting an invalid cron expression.")
+	terraform.Spec.Schedule = &infrav1.ScheduleSpec{
+		Windows: []infrav1.MaintenanceWindow{{Cron: "0 25 * * *", Duration: metav1.Duration{Duration: time.Hour}}},
+	}
+	_, err = checkSchedule(*terraform.Spec.Schedule, now)
+	g.Expect(err).To(MatchError(ContainSubstring(`invalid cron expression "0 25 * * *"`)))
+}
+
+func TestCronParser(t *testing.T) {
+	g := NewWithT(t)
+
+	// Wednesday
+	now := time.Date(2024, 1, 10, 12, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 10, 12, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 10, 12, 45, 0, 0, time.UTC)},
+		{"0 22 * * 1-5", time.Date(2024, 1, 10, 22, 0, 0, 0, time.UTC)},
+		{"0 2 * * 6,0", time.Date(2024, 1, 13, 2, 0, 0, 0, time.UTC)},
+		{"0 2 * * SUN", time.Date(2024, 1, 14, 2, 0, 0, 0, time.UTC)},
+		{"30 1 1 */3 *", time.Date(2024, 4, 1, 1, 30, 0, 0, time.UTC)},
+		// either the day of month or the day of week matches when both are restricted
+		{"0 0 20 * 1", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		cron, err := cronParser.Parse(tt.expr)
+		g.Expect(err).ToNot(HaveOccurred(), tt.expr)
+		g.Expect(cron.Next(now)).To(Equal(tt.expected), tt.expr)
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "a * * * *", "@daily"} {
+		_, err := cronParser.Parse(expr)
+		g.Expect(err).To(HaveOccurred(), expr)
+	}
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/runner"
+	"github.com/fluxcd/pkg/apis/meta"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type stateMigrationRunnerClient struct {
+	runner.RunnerClient
+
+	migrateStateRequest *runner.MigrateStateRequest
+}
+
+func (c *stateMigrationRunnerClient) MigrateState(ctx context.Context, in *runner.MigrateStateRequest, opts ...grpc.CallOption) (*runner.MigrateStateReply, error) {
+	c.migrateStateRequest = in
+	return &runner.MigrateStateReply{Message: "migrated 2 resources", ResourcesBefore: 2, ResourcesAfter: 2}, nil
+}
+
+func TestMigrateState(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	scheme := runtime.NewScheme()
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	backend := func(bucket string) *infrav1.BackendConfigSpec {
+		return &infrav1.BackendConfigSpec{CustomConfiguration: `backend "s3" { bucket = "` + bucket + `" }`}
+	}
+	terraform := infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-resource", Namespace: "default"},
+		Spec:       infrav1.TerraformSpec{BackendConfig: backend("new")},
+		Status:     infrav1.TerraformStatus{Backend: &infrav1.BackendStatus{Config: backend("old")}},
+	}
+	objectKey := client.ObjectKeyFromObject(&terraform)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &TerraformReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(terraform.DeepCopy()).WithStatusSubresource(&infrav1.Terraform{}).Build(),
+		Scheme:        scheme,
+		EventRecorder: recorder,
+	}
+	runnerClient := &stateMigrationRunnerClient{}
+
+	By("holding a backend change without a migration request.")
+	terraform, err := r.migrateState(ctx, runnerClient, terraform, "instance", "/tmp/dir", "main@sha1:1234", objectKey)
+	g.Expect(err).To(MatchError(ContainSubstring(infrav1.MigrateStateAnnotation)))
+	g.Expect(runnerClient.migrateStateRequest).To(BeNil())
+	g.Expect(apimeta.FindStatusCondition(terraform.Status.Conditions, meta.ReadyCondition).Reason).To(Equal(infrav1.StateMigrationRequiredReason))
+	g.Expect(apimeta.FindStatusCondition(terraform.Status.Conditions, infrav1.ConditionTypeStateMigration)).To(And(
+		HaveField("Status", metav1.ConditionFalse),
+		HaveField("Reason", infrav1.StateMigrationRequiredReason),
+	))
+	g.Expect(terraform.Status.Backend.Config).To(Equal(backend("old")))
+
+	By("using the new backend as is when the migration is skipped.")
+	terraform.Annotations = map[string]string{infrav1.SkipStateMigrationAnnotation: "1"}
+	terraform, err = r.migrateState(ctx, runnerClient, terraform, "instance", "/tmp/dir", "main@sha1:1234", objectKey)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(runnerClient.migrateStateRequest).To(BeNil())
+	g.Expect(apimeta.FindStatusCondition(terraform.Status.Conditions, infrav1.ConditionTypeStateMigration)).To(And(
+		HaveField("Status", metav1.ConditionTrue),
+		HaveField("Reason", infrav1.StateMigrationSkippedReason),
+	))
+	g.Expect(terraform.Status.Backend.Config).To(Equal(backend("new")))
+	g.Expect(terraform.Status.LastHandledMigrateStateAt).To(Equal("1"))
+	g.Expect(<-recorder.Events).To(ContainSubstring("State migration skipped"))
+
+	By("holding the next backend change, as the skip request has been handled.")
+	terraform.Spec.BackendConfig = backend("newer")
+	_, err = r.migrateState(ctx, runnerClient, terraform, "instance", "/tmp/dir", "main@sha1:1234", objectKey)
+	g.Expect(err).To(HaveOccurred())
+
+	By("migrating the state when requested.")
+	terraform.Annotations[infrav1.MigrateStateAnnotation] = "2"
+	terraform, err = r.migrateState(ctx, runnerClient, terraform, "instance", "/tmp/dir", "main@sha1:1234", objectKey)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(runnerClient.migrateStateRequest.OldBackendConfig)).To(ContainSubstring(`bucket = "new"`))
+	g.Expect(string(runnerClient.migrateStateRequest.NewBackendConfig)).To(ContainSubstring(`bucket = "newer"`))
+	g.Expect(apimeta.FindStatusCondition(terraform.Status.Conditions, infrav1.ConditionTypeStateMigration).Reason).To(Equal(infrav1.StateMigrationSucceededReason))
+	g.Expect(terraform.Status.Backend.Config).To(Equal(backend("newer")))
+
+	By("not holding an unchanged backend.")
+	_, err = r.migrateState(ctx, runnerClient, terraform, "instance", "/tmp/dir", "main@sha1:1234", objectKey)
+	g.Expect(err).ToNot(HaveOccurred())
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/runner"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type stateOperationRunnerClient struct {
+	runner.RunnerClient
+
+	stateMvRequest *runner.StateMvRequest
+	stateMvErr     error
+	written        *runner.WriteOutputsRequest
+}
+
+func (c *stateOperationRunnerClient) StateList(ctx context.Context, in *runner.StateListRequest, opts ...grpc.CallOption) (*runner.StateListReply, error) {
+	return &runner.StateListReply{Message: "listed 2 resources", Output: "null_resource.a\nnull_resource.b\n"}, nil
+}
+
+func (c *stateOperationRunnerClient) StateMv(ctx context.Context, in *runner.StateMvRequest, opts ...grpc.CallOption) (*runner.StateMvReply, error) {
+	c.stateMvRequest = in
+	if c.stateMvErr != nil {
+		return nil, c.stateMvErr
+	}
+	return &runner.StateMvReply{Message: "moved " + in.Source + " to " + in.Destination}, nil
+}
+
+func (c *stateOperationRunnerClient) WriteOutputs(ctx context.Context, in *runner.WriteOutputsRequest, opts ...grpc.CallOption) (*runner.WriteOutputsReply, error) {
+	c.written = in
+	return &runner.WriteOutputsReply{Message: "ok", Changed: true}, nil
+}
+
+func TestHandleStateOperation(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &TerraformReconciler{EventRecorder: recorder}
+	runnerClient := &stateOperationRunnerClient{}
+
+	terraform := infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-resource",
+			Namespace: "default",
+			Annotations: map[string]string{
+				infrav1.StateOperationAnnotation:            `{"id":"1","operation":"mv","args":["null_resource.a","null_resource.b"],"requestedBy":"mallory"}`,
+				infrav1.StateOperationRequestedByAnnotation: "alice",
+			},
+		},
+	}
+
+	By("moving the resource through the runner, on behalf of the user of the requested-by annotation.")
+	terraform = r.handleStateOperation(context.TODO(), runnerClient, terraform, "instance", "main@sha1:1234")
+	g.Expect(runnerClient.stateMvRequest.Source).To(Equal("null_resource.a"))
+	g.Expect(runnerClient.stateMvRequest.Destination).To(Equal("null_resource.b"))
+	g.Expect(runnerClient.stateMvRequest.RequestedBy).To(Equal("alice"))
+	g.Expect(terraform.Status.StateOperation.ID).To(Equal("1"))
+	g.Expect(terraform.Status.StateOperation.Succeeded).To(BeTrue())
+	g.Expect(terraform.Status.StateOperation.OutputSecretName).To(BeEmpty())
+	g.Expect(<-recorder.Events).To(ContainSubstring("alice ran terraform state mv null_resource.a null_resource.b"))
+
+	By("not running a handled request again.")
+	runnerClient.stateMvRequest = nil
+	terraform = r.handleStateOperation(context.TODO(), runnerClient, terraform, "instance", "main@sha1:1234")
+	g.Expect(runnerClient.stateMvRequest).To(BeNil())
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	By("recording a failed operation.")
+	runnerClient.stateMvErr = errors.New("resource not found")
+	terraform.Annotations[infrav1.StateOperationAnnotation] = `{"id":"2","operation":"mv","args":["null_resource.c","null_resource.d"]}`
+	terraform = r.handleStateOperation(context.TODO(), runnerClient, terraform, "instance", "main@sha1:1234")
+	g.Expect(terraform.Status.StateOperation.ID).To(Equal("2"))
+	g.Expect(terraform.Status.StateOperation.Succeeded).To(BeFalse())
+	g.Expect(terraform.Status.StateOperation.Message).To(Equal("resource not found"))
+	g.Expect(<-recorder.Events).To(HavePrefix("Warning"))
+
+	By("refusing an argument which would be taken as a flag.")
+	runnerClient.stateMvErr = nil
+	runnerClient.stateMvRequest = nil
+	terraform.Annotations[infrav1.StateOperationAnnotation] = `{"id":"flag","operation":"mv","args":["-state-out=/tmp/state","null_resource.d"]}`
+	terraform = r.handleStateOperation(context.TODO(), runnerClient, terraform, "instance", "main@sha1:1234")
+	g.Expect(runnerClient.stateMvRequest).To(BeNil())
+	g.Expect(terraform.Status.StateOperation.Succeeded).To(BeFalse())
+	g.Expect(terraform.Status.StateOperation.Message).To(Equal(`invalid argument "-state-out=/tmp/state", state operations do not accept flags`))
+	<-recorder.Events
+
+	By("writing the output of a list to a secret.")
+	delete(terraform.Annotations, infrav1.StateOperationRequestedByAnnotation)
+	terraform.Annotations[infrav1.StateOperationAnnotation] = `{"id":"3","operation":"list"}`
+	terraform = r.handleStateOperation(context.TODO(), runnerClient, terraform, "instance", "main@sha1:1234")
+	g.Expect(terraform.Status.StateOperation.Succeeded).To(BeTrue())
+	g.Expect(terraform.Status.StateOperation.OutputSecretName).To(Equal("tfstate-operation-my-resource"))
+	g.Expect(runnerClient.written.SecretName).To(Equal("tfstate-operation-my-resource"))
+	g.Expect(string(runnerClient.written.Data[infrav1.StateOperationOutputDataKey])).To(Equal("null_resource.a\nnull_resource.b\n"))
+	g.Expect(<-recorder.Events).To(ContainSubstring("unknown user ran terraform state list"))
+
+	By("rejecting an operation with missing arguments.")
+	terraform.Annotations[infrav1.StateOperationAnnotation] = `{"id":"4","operation":"show"}`
+	terraform = r.handleStateOperation(context.TODO(), runnerClient, terraform, "instance", "main@sha1:1234")
+	g.Expect(terraform.Status.StateOperation.Succeeded).To(BeFalse())
+	g.Expect(terraform.Status.StateOperation.Message).To(ContainSubstring("expects exactly one address"))
+	<-recorder.Events
+
+	By("reporting a request which does not parse once.")
+	terraform.Annotations[infrav1.StateOperationAnnotation] = `{"id":`
+	terraform = r.handleStateOperation(context.TODO(), runnerClient, terraform, "instance", "main@sha1:1234")
+	g.Expect(terraform.Status.StateOperation.Succeeded).To(BeFalse())
+	g.Expect(terraform.Status.StateOperation.RejectedRequest).To(Equal(`{"id":`))
+	g.Expect(<-recorder.Events).To(ContainSubstring("unable to parse the " + infrav1.StateOperationAnnotation + " annotation"))
+	terraform = r.handleStateOperation(context.TODO(), runnerClient, terraform, "instance", "main@sha1:1234")
+	g.Expect(recorder.Events).To(BeEmpty())
+}
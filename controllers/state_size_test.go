@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckStateSize(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	stateSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tfstate-default-my-resource", Namespace: "default"},
+		Data:       map[string][]byte{"tfstate": make([]byte, 900*1024)},
+	}
+	recorder := record.NewFakeRecorder(10)
+	r := &TerraformReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(stateSecret).Build(),
+		EventRecorder: recorder,
+	}
+
+	terraform := infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-resource", Namespace: "default"},
+	}
+
+	By("warning above the default threshold.")
+	terraform = r.checkStateSize(context.TODO(), terraform, "main@sha1:1234")
+	c := apimeta.FindStatusCondition(terraform.Status.Conditions, infrav1.ConditionTypeStateSize)
+	g.Expect(c).ToNot(BeNil())
+	g.Expect(c.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(c.Reason).To(Equal(infrav1.StateSizeNearLimitReason))
+	g.Expect(c.Message).To(ContainSubstring("900.0 KiB, 87% of the 1024.0 KiB size limit"))
+	g.Expect(recorder.Events).To(HaveLen(1))
+
+	By("not warning below a custom threshold.")
+	terraform.Spec.TFState = &infrav1.TFStateSpec{SizeWarningPercent: 90}
+	terraform = r.checkStateSize(context.TODO(), terraform, "main@sha1:1234")
+	c = apimeta.FindStatusCondition(terraform.Status.Conditions, infrav1.ConditionTypeStateSize)
+	g.Expect(c.Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(c.Reason).To(Equal(infrav1.StateSizeWithinLimitReason))
+
+	By("skipping the sharded state.")
+	_, ok := r.kubernetesBackendStateKey(infrav1.Terraform{
+		Spec: infrav1.TerraformSpec{BackendConfig: &infrav1.BackendConfigSpec{Sharded: true}},
+	})
+	g.Expect(ok).To(BeFalse())
+
+	By("explaining the error of a state too large for a Secret.")
+	err := explainStateSizeError(errors.New(`Secret "tfstate-default-my-resource" is invalid: data: Too long: must have at most 1048576 bytes`))
+	g.Expect(err).To(MatchError(ContainSubstring("consider setting spec.backendConfig.sharded")))
+}
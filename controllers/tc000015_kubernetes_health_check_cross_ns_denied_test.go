@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/fluxcd/pkg/runtime/acl"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_000015_cross_namespace_kubernetes_health_check_denied_test(t *testing.T) {
+	Spec("This spec describes the behaviour of a kubernetes health check on an object in another namespace when cross-namespace refs are not allowed.")
+	It("should fail with an access denied error, without reading the object.")
+
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	Given("a ConfigMap with a Ready condition in another namespace")
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "database", Namespace: "databases"},
+	}
+	r := &TerraformReconciler{
+		Client:               fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build(),
+		NoCrossNamespaceRefs: true,
+	}
+
+	terraform := infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "tf-cross-ns", Namespace: "flux-system"},
+	}
+	hc := infrav1.HealthCheck{
+		Name: "database",
+		Type: infrav1.HealthCheckTypeKubernetes,
+		KubernetesObject: &infrav1.KubernetesObjectHealthCheck{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Name:       "${{ .name }}",
+			Namespace:  "${{ .namespace }}",
+		},
+	}
+	outputs := map[string]string{"name": "database", "namespace": "databases"}
+
+	By("checking the health of the object in the namespace given by the outputs")
+	err := r.doKubernetesHealthCheck(ctx, terraform, hc, outputs)
+	It("should be denied")
+	var accessDenied acl.AccessDeniedError
+	g.Expect(errors.As(err, &accessDenied)).To(BeTrue())
+	g.Expect(err).To(MatchError(ContainSubstring("cannot access ConfigMap databases/database, cross-namespace references have been disabled")))
+
+	By("checking the health of an object in the namespace of the Terraform object")
+	outputs["namespace"] = "flux-system"
+	err = r.doKubernetesHealthCheck(ctx, terraform, hc, outputs)
+	It("should read the object, not found here")
+	g.Expect(errors.As(err, &accessDenied)).To(BeFalse())
+	g.Expect(err).To(MatchError(ContainSubstring("not found")))
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/runner"
+	"google.golang.org/grpc"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+type mockRunnerClientForTestWebhooksForPostApply struct {
+	runner.RunnerClient
+}
+
+func (m *mockRunnerClientForTestWebhooksForPostApply) Output(ctx context.Context, req *runner.OutputRequest, opts ...grpc.CallOption) (*runner.OutputReply, error) {
+	return &runner.OutputReply{
+		Outputs: map[string]*runner.OutputMeta{
+			"hello": {
+				Type:  []byte(`"string"`),
+				Value: []byte(`"world"`),
+			},
+			"password": {
+				Sensitive: true,
+				Type:      []byte(`"string"`),
+				Value:     []byte(`"secret"`),
+			},
+		},
+	}, nil
+}
+
+func Test_000341_PreparePostApplyWebhookPayloadPlanOnly(t *testing.T) {
+	g := NewWithT(t)
+	terraform := infrav1.Terraform{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "infra.contrib.fluxcd.io/v1alpha2",
+		},
+	}
+	err := terraform.FromBytes([]byte(fmt.Sprintf(`
+apiVersion: infra.contrib.fluxcd.io/v1alpha2
+kind: Terraform
+metadata:
+  name: %s
+  namespace: flux-system
+spec:
+  approvePlan: auto
+  path: ./terraform-hello-world-example
+  sourceRef:
+    kind: GitRepository
+    name: %s
+    namespace: flux-system
+  interval: 10s
+  webhooks:
+  - stage: post-apply
+    url: %s
+    payloadType: PlanOnly
+status:
+  inventory:
+    entries:
+    - n: hello
+      t: null_resource
+      id: "1234"
+`, "helloworld", "gitrepo", "http://localhost:8080/terraform/admission")), runnerServer.Scheme)
+	g.Expect(err).ToNot(HaveOccurred())
+	mockRunnerClient := &mockRunnerClientForTestWebhooksForPostApply{}
+	payload, err := reconciler.preparePostApplyWebhookPayload(terraform, mockRunnerClient, "PlanOnly", uuid.New().String())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	By("checking that the sensitive output value is redacted.")
+	expected, err := yaml.Parse(`
+outputs:
+  hello:
+    sensitive: false
+    type: string
+    value: world
+  password:
+    sensitive: true
+    type: string
+inventory:
+- n: hello
+  t: null_resource
+  id: "1234"
+`)
+
+	g.Expect(err).ToNot(HaveOccurred())
+	expectedBytes, err := expected.MarshalJSON()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	var payloadMap map[string]interface{}
+	var expectedMap map[string]interface{}
+
+	err = json.Unmarshal(payload, &payloadMap)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	err = json.Unmarshal(expectedBytes, &expectedMap)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(payloadMap).To(Equal(expectedMap))
+}
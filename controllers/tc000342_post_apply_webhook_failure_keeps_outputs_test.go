@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/runner"
+	"github.com/fluxcd/pkg/apis/meta"
+	"google.golang.org/grpc"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type mockRunnerClientForTestPostApplyWebhookFailure struct {
+	runner.RunnerClient
+	written map[string][]byte
+}
+
+func (m *mockRunnerClientForTestPostApplyWebhookFailure) Output(ctx context.Context, req *runner.OutputRequest, opts ...grpc.CallOption) (*runner.OutputReply, error) {
+	return &runner.OutputReply{
+		Outputs: map[string]*runner.OutputMeta{
+			"hello": {
+				Type:  []byte(`"string"`),
+				Value: []byte(`"world"`),
+			},
+		},
+	}, nil
+}
+
+func (m *mockRunnerClientForTestPostApplyWebhookFailure) WriteOutputs(ctx context.Context, req *runner.WriteOutputsRequest, opts ...grpc.CallOption) (*runner.WriteOutputsReply, error) {
+	m.written = req.Data
+	return &runner.WriteOutputsReply{Message: "ok", Changed: true}, nil
+}
+
+func Test_000342_post_apply_webhook_failure_keeps_outputs_test(t *testing.T) {
+	Spec("This spec describes the behaviour of a post-apply webhook rejecting the result of a successful apply.")
+	It("should write the outputs, keep the object ready, and record the failure on the PostApplyWebhook condition.")
+
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	Given("a post-apply webhook rejecting the apply result")
+	t.Setenv("DISABLE_WEBHOOK_TLS_VERIFY", "1")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"recorded": false, "error": "CMDB unavailable"}`))
+	}))
+	defer srv.Close()
+
+	terraform := infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "tf-post-apply", Namespace: "flux-system"},
+		Spec: infrav1.TerraformSpec{
+			WriteOutputsToSecret: &infrav1.WriteOutputsToSecretSpec{Name: "tf-post-apply-outputs"},
+			Webhooks: []infrav1.Webhook{
+				{
+					Stage:                infrav1.PostApplyWebhook,
+					URL:                  srv.URL,
+					PayloadType:          "PlanOnly",
+					TestExpression:       "${{ .recorded }}",
+					ErrorMessageTemplate: "CMDB update failed: ${{ .error }}",
+				},
+			},
+		},
+	}
+	terraform = infrav1.TerraformApplied(terraform, "main@sha1:1234", "Applied successfully", false, nil)
+
+	r := &TerraformReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(terraform.DeepCopy()).WithStatusSubresource(&infrav1.Terraform{}).Build(),
+		Scheme:        scheme,
+		EventRecorder: record.NewFakeRecorder(10),
+	}
+	runnerClient := &mockRunnerClientForTestPostApplyWebhookFailure{}
+
+	By("processing the outputs, then the post-apply webhooks, as the reconciliation does after an apply")
+	terraform, err := r.processOutputs(ctx, runnerClient, terraform, "tf-instance", "main@sha1:1234")
+	g.Expect(err).ToNot(HaveOccurred())
+	terraform = r.processPostApplyWebhooks(ctx, terraform, runnerClient, "main@sha1:1234", "tf-instance")
+
+	It("should have written the outputs")
+	g.Expect(runnerClient.written).To(HaveKeyWithValue("hello", []byte("world")))
+	g.Expect(apimeta.IsStatusConditionTrue(terraform.Status.Conditions, infrav1.ConditionTypeOutput)).To(BeTrue())
+
+	It("should keep the object ready")
+	g.Expect(apimeta.IsStatusConditionTrue(terraform.Status.Conditions, meta.ReadyCondition)).To(BeTrue())
+
+	It("should record the failure on the PostApplyWebhook condition")
+	condition := apimeta.FindStatusCondition(terraform.Status.Conditions, infrav1.ConditionTypePostApplyWebhook)
+	g.Expect(condition).ToNot(BeNil())
+	g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(condition.Reason).To(Equal(infrav1.PostApplyWebhookFailedReason))
+	g.Expect(condition.Message).To(ContainSubstring("CMDB update failed: CMDB unavailable"))
+}
@@ -19,6 +19,7 @@ package controllers
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -41,6 +42,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/hashicorp/go-retryablehttp"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -61,6 +63,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/internal/metrics"
+	"github.com/flux-iac/tofu-controller/internal/tracing"
 	"github.com/flux-iac/tofu-controller/mtls"
 )
 
@@ -85,14 +89,27 @@ type TerraformReconciler struct {
 	NoCrossNamespaceRefs      bool
 	UsePodSubdomainResolution bool
 	Clientset                 *kubernetes.Clientset
+	// FreezeConfigMap is the ConfigMap that holds all applies across namespaces while it exists.
+	FreezeConfigMap types.NamespacedName
+	// DriftDetectionConcurrency caps the number of drift detections running at once, 0 for no limit.
+	DriftDetectionConcurrency int
+	// MetricsRecorder records the Terraform specific metrics, next to the generic ones of Metrics.
+	MetricsRecorder *metrics.Recorder
+	// Tracing is the OTLP collector to which the runners export their traces.
+	Tracing tracing.Options
+
+	driftDetectionLimiter driftDetectionLimiter
 }
 
 //+kubebuilder:rbac:groups=infra.contrib.fluxcd.io,resources=terraforms,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=infra.contrib.fluxcd.io,resources=terraforms/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=infra.contrib.fluxcd.io,resources=terraforms/finalizers,verbs=get;create;update;patch;delete
+//+kubebuilder:rbac:groups=infra.contrib.fluxcd.io,resources=planapprovals,verbs=get;list;watch
 //+kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=buckets;gitrepositories;ocirepositories,verbs=get;list;watch
 //+kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=buckets/status;gitrepositories/status;ocirepositories/status,verbs=get
 //+kubebuilder:rbac:groups="",resources=configmaps;secrets;serviceaccounts,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=delete
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -133,6 +150,11 @@ func (r *TerraformReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 	log.Info(fmt.Sprintf(">> Started Generation: %d", terraform.GetGeneration()))
 
+	ctx, span := tracing.Start(ctx, "reconcile", terraform.Namespace, terraform.Name)
+	defer func() {
+		tracing.End(span, retErr)
+	}()
+
 	defer func() {
 		if err := r.finalizeStatus(ctx, &terraform); err != nil {
 			retErr = kerrors.NewAggregate([]error{retErr, err})
@@ -142,6 +164,11 @@ func (r *TerraformReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		r.Metrics.RecordReadiness(ctx, &terraform)
 		r.Metrics.RecordSuspend(ctx, &terraform, terraform.Spec.Suspend)
 		r.Metrics.RecordDuration(ctx, &terraform, reconcileStart)
+		if !terraform.DeletionTimestamp.IsZero() && !controllerutil.ContainsFinalizer(&terraform, infrav1.TerraformFinalizer) {
+			r.MetricsRecorder.Delete(terraform)
+		} else {
+			r.MetricsRecorder.RecordStatus(terraform)
+		}
 	}()
 
 	// Add our finalizer if it does not exist
@@ -173,6 +200,10 @@ func (r *TerraformReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			}
 		}
 
+		if len(dependants) > 0 && terraform.IsCascadeDestroyRequested() {
+			return r.cascadeDestroy(ctx, terraform, dependants)
+		}
+
 		if len(dependants) > 0 {
 			msg := fmt.Sprintf("Deletion in progress, but blocked. Please delete %s to resume ...", strings.Join(dependants, ", "))
 			terraform = infrav1.TerraformNotReady(terraform, "", infrav1.DeletionBlockedByDependants, msg)
@@ -303,6 +334,16 @@ func (r *TerraformReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		}
 	}
 
+	traceLog.Info("Check for a diff request")
+	diffRequest, err := terraform.GetDiffRequest()
+	if err != nil {
+		terraform = r.rejectDiffRequest(ctx, terraform, err, sourceObj.GetArtifact().Revision)
+		if err := r.patchStatus(ctx, req.NamespacedName, terraform.Status); err != nil {
+			log.Error(err, "unable to update status after rejecting the diff request")
+			return ctrl.Result{Requeue: true}, err
+		}
+	}
+
 	if !isBeingDeleted(terraform) {
 		// case 1:
 		// If revision is changed, and there's no intend to apply,
@@ -336,14 +377,46 @@ func (r *TerraformReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			}
 		}
 
+		// discard the pending plan once expired, to plan again against the current infrastructure
+		traceLog.Info("Check for an expired plan")
+		if r.isPlanExpired(terraform, time.Now()) {
+			var err error
+			if terraform, err = r.expirePlan(ctx, terraform, sourceObj.GetArtifact().Revision); err != nil {
+				log.Error(err, "unable to discard the expired plan")
+				return ctrl.Result{}, err
+			}
+			if err := r.patchStatus(ctx, req.NamespacedName, terraform.Status); err != nil {
+				log.Error(err, "unable to update status after discarding the expired plan")
+				return ctrl.Result{Requeue: true}, err
+			}
+		}
+
+		// record the approvals of the pending plan, which only gate the apply once the plan is approved
+		traceLog.Info("Record the approvals of the pending plan")
+		approvalStatus := terraform.Status.DeepCopy()
+		var approvalErr error
+		if terraform, _, approvalErr = r.reconcileApprovals(ctx, terraform, sourceObj.GetArtifact().Revision); approvalErr != nil {
+			log.Error(approvalErr, "unable to check the plan approvals")
+		}
+		if !equality.Semantic.DeepEqual(approvalStatus, &terraform.Status) {
+			if err := r.patchStatus(ctx, req.NamespacedName, terraform.Status); err != nil {
+				log.Error(err, "unable to update status after recording the plan approvals")
+				return ctrl.Result{Requeue: true}, err
+			}
+		}
+
 		// case 3:
-		// return early if it's manually mode and pending
+		// return early if it's manually mode and pending, unless a diff is requested
 		//
 		traceLog.Info("Check for pending plan, forceOrAutoApply and shouldApply")
 		if terraform.Status.Plan.Pending != "" &&
 			!r.forceOrAutoApply(terraform) &&
-			!r.shouldApply(terraform) {
+			!r.shouldApply(terraform) &&
+			diffRequest == nil {
 			log.Info("reconciliation is stopped to wait for a manual approve")
+			if expiresAt := terraform.PlanExpiresAt(); !expiresAt.IsZero() {
+				return ctrl.Result{RequeueAfter: time.Until(expiresAt)}, nil
+			}
 			return ctrl.Result{}, nil
 		}
 	}
@@ -351,7 +424,9 @@ func (r *TerraformReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	// Create Runner Pod.
 	// Wait for the Runner Pod to start.
 	traceLog.Info("Fetch/Create Runner pod for this Terraform resource")
-	runnerClient, closeConn, err := r.LookupOrCreateRunner(ctx, terraform, sourceObj.GetArtifact().Revision)
+	runnerCtx, runnerSpan := tracing.Start(ctx, "runner", terraform.Namespace, terraform.Name)
+	runnerClient, closeConn, err := r.LookupOrCreateRunner(runnerCtx, terraform, sourceObj.GetArtifact().Revision)
+	tracing.End(runnerSpan, err)
 	if err != nil {
 		log.Error(err, "unable to lookup or create runner")
 		if closeConn != nil {
@@ -455,14 +530,30 @@ func (r *TerraformReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{Requeue: false}, nil
 	}
 
+	// plan the configuration of the diff request only, then reconcile again
+	if diffRequest != nil && !isBeingDeleted(terraform) {
+		traceLog.Info("Run the requested diff")
+		diffCtx, span := tracing.Start(ctx, "diff", terraform.Namespace, terraform.Name)
+		terraform = r.handleDiff(diffCtx, runnerClient, terraform, *diffRequest, sourceObj.GetArtifact().Revision, reconciliationLoopID)
+		tracing.End(span, nil)
+		if err := r.patchStatus(ctx, req.NamespacedName, terraform.Status); err != nil {
+			log.Error(err, "unable to update status after the diff")
+			return ctrl.Result{Requeue: true}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
 	// reconcile Terraform by applying the latest revision
 	traceLog.Info("Run reconcile for the Terraform resource")
 	reconciledTerraform, reconcileErr := r.reconcile(ctx, runnerClient, *terraform.DeepCopy(), sourceObj, reconciliationLoopID)
 
 	// Check remediation.
+	var driftDetectionDeferred *driftDetectionDeferredError
 	if reconcileErr == nil {
 		log.Info("Reset reconciliation failures count. Reason: successful reconciliation")
 		terraform = infrav1.TerraformResetRetry(*reconciledTerraform)
+	} else if errors.As(reconcileErr, &driftDetectionDeferred) {
+		terraform = *reconciledTerraform
 	} else {
 		terraform = *reconciledTerraform
 		terraform.IncrementReconciliationFailures()
@@ -475,7 +566,11 @@ func (r *TerraformReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 
 	traceLog.Info("Check for reconciliation errors")
-	if reconcileErr != nil && reconcileErr.Error() == infrav1.DriftDetectedReason {
+	if driftDetectionDeferred != nil {
+		log.Info(fmt.Sprintf("Reconciliation completed, %s. Generation: %d", driftDetectionDeferred.Error(), terraform.GetGeneration()),
+			"requeueAfter", driftDetectionDeferred.requeueAfter.String())
+		return ctrl.Result{RequeueAfter: driftDetectionDeferred.requeueAfter}, nil
+	} else if reconcileErr != nil && reconcileErr.Error() == infrav1.DriftDetectedReason {
 		log.Error(reconcileErr, fmt.Sprintf("Drift detected after %s, next try in %s",
 			time.Since(reconcileStart).String(),
 			terraform.GetRetryInterval().String()),
@@ -514,9 +609,22 @@ func (r *TerraformReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	log.Info(fmt.Sprintf("Reconciliation completed. Generation: %d", terraform.GetGeneration()))
 
+	traceLog.Info("Check for a plan held by the schedule or a change freeze")
+	if isApplyHeld(terraform) && terraform.Status.Plan.Pending != "" {
+		requeueAfter := terraform.Spec.Interval.Duration
+		if hold, err := r.checkApplyHold(ctx, terraform, time.Now()); err == nil && hold != nil {
+			requeueAfter = hold.requeueAfter(time.Now(), requeueAfter)
+		}
+		log.Info("Apply is held, requeue to apply the pending plan", "requeueAfter", requeueAfter.String())
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
 	traceLog.Info("Check for pending plan and forceOrAutoApply")
 	if terraform.Status.Plan.Pending != "" && !r.forceOrAutoApply(terraform) {
 		log.Info("Reconciliation is stopped to wait for manual operations")
+		if expiresAt := terraform.PlanExpiresAt(); !expiresAt.IsZero() {
+			return ctrl.Result{RequeueAfter: time.Until(expiresAt)}, nil
+		}
 		return ctrl.Result{}, nil
 	}
 
@@ -531,6 +639,8 @@ func isBeingDeleted(terraform infrav1.Terraform) bool {
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *TerraformReconciler) SetupWithManager(mgr ctrl.Manager, maxConcurrentReconciles int, httpRetry int) error {
+	r.driftDetectionLimiter = newDriftDetectionLimiter(r.DriftDetectionConcurrency)
+
 	// Index the Terraforms by the GitRepository references they (may) point at.
 	if err := mgr.GetCache().IndexField(context.TODO(), &infrav1.Terraform{}, infrav1.GitRepositoryIndexKey,
 		r.IndexBy(sourcev1.GitRepositoryKind)); err != nil {
@@ -585,6 +695,10 @@ func (r *TerraformReconciler) SetupWithManager(mgr ctrl.Manager, maxConcurrentRe
 			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &infrav1.Terraform{}, handler.OnlyControllerOwner()),
 			builder.WithPredicates(SecretDeletePredicate{}),
 		).
+		Watches(
+			&infrav1.PlanApproval{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForPlanApproval),
+		).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrentReconciles,
 			RecoverPanic:            &recoverPanic,
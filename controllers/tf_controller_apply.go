@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 
 	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/internal/metrics"
 	"github.com/flux-iac/tofu-controller/runner"
 	"google.golang.org/grpc/status"
 	"k8s.io/apimachinery/pkg/types"
@@ -77,6 +79,37 @@ func (r *TerraformReconciler) apply(ctx context.Context, terraform infrav1.Terra
 
 	log.Info(fmt.Sprintf("load tf plan: %s", loadTFPlanReply.Message))
 
+	if shouldProcessWebhooks(terraform, infrav1.PreApplyWebhook) {
+		log.Info("calling pre-apply webhooks")
+		terraform, err = r.processPreApplyWebhooks(ctx, terraform, runnerClient, revision, tfInstance)
+		if err != nil {
+			log.Error(err, "failed during process pre-apply webhooks")
+			return infrav1.TerraformNotReady(
+				terraform,
+				revision,
+				infrav1.PreApplyWebhookFailedReason,
+				err.Error(),
+			), err
+		}
+	}
+
+	if terraform.Spec.StateBackup != nil && !r.backendCompletelyDisable(terraform) {
+		backupStateReply, err := runnerClient.BackupState(ctx, &runner.BackupStateRequest{
+			TfInstance: tfInstance,
+			Revision:   revision,
+		})
+		if err != nil {
+			err = fmt.Errorf("error backing up the state: %s", err)
+			return infrav1.TerraformNotReady(
+				terraform,
+				revision,
+				infrav1.StateBackupFailedReason,
+				err.Error(),
+			), err
+		}
+		log.Info(fmt.Sprintf("backup state: %s", backupStateReply.Message))
+	}
+
 	terraform = infrav1.TerraformApplying(terraform, revision, "Apply started")
 	if err := r.patchStatus(ctx, objectKey, terraform.Status); err != nil {
 		log.Error(err, "error recording apply status: %s", err)
@@ -101,11 +134,13 @@ func (r *TerraformReconciler) apply(ctx context.Context, terraform infrav1.Terra
 
 	// this a special case, when backend is completely disabled.
 	// we need to use "destroy" command instead of apply
+	applyStart := time.Now()
 	if r.backendCompletelyDisable(terraform) && terraform.Spec.Destroy == true {
 		destroyReply, err := runnerClient.Destroy(ctx, &runner.DestroyRequest{
 			TfInstance: tfInstance,
 			Targets:    terraform.Spec.Targets,
 		})
+		r.MetricsRecorder.RecordStageDuration(terraform, metrics.StageApply, applyStart)
 		log.Info(fmt.Sprintf("destroy: %s", destroyReply.Message))
 
 		eventSent := false
@@ -138,6 +173,7 @@ func (r *TerraformReconciler) apply(ctx context.Context, terraform infrav1.Terra
 	} else {
 		eventSent := false
 		applyReply, err := runnerClient.Apply(ctx, applyRequest)
+		r.MetricsRecorder.RecordStageDuration(terraform, metrics.StageApply, applyStart)
 		if err != nil {
 			if st, ok := status.FromError(err); ok {
 				for _, detail := range st.Details() {
@@ -155,7 +191,7 @@ func (r *TerraformReconciler) apply(ctx context.Context, terraform infrav1.Terra
 				r.event(ctx, terraform, revision, eventv1.EventSeverityError, msg, nil)
 			}
 
-			err = fmt.Errorf("error running Apply: %s", err)
+			err = explainStateSizeError(fmt.Errorf("error running Apply: %s", err))
 			return infrav1.TerraformAppliedFailResetPlanAndNotReady(
 				terraform,
 				revision,
@@ -204,6 +240,7 @@ func (r *TerraformReconciler) apply(ctx context.Context, terraform infrav1.Terra
 	}
 	r.event(ctx, terraform, revision, eventv1.EventSeverityInfo, msg, nil)
 	terraform = infrav1.TerraformApplied(terraform, revision, msg, isDestroyApplied, inventoryEntries)
+	terraform = r.checkStateSize(ctx, terraform, revision)
 
 	return terraform, nil
 }
@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+// approvalPolicies returns the approval policies of the object and of its namespace.
+func (r *TerraformReconciler) approvalPolicies(ctx context.Context, terraform infrav1.Terraform) ([]infrav1.ApprovalPolicySpec, error) {
+	var policies []infrav1.ApprovalPolicySpec
+	if terraform.Spec.ApprovalPolicy != nil {
+		policies = append(policies, *terraform.Spec.ApprovalPolicy)
+	}
+
+	cm := corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: terraform.Namespace, Name: infrav1.ApprovalPolicyConfigMapName}
+	if err := r.Get(ctx, key, &cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return policies, nil
+		}
+		return nil, fmt.Errorf("unable to get the approval policy ConfigMap %s: %w", key, err)
+	}
+
+	policy, err := parseApprovalPolicy(cm.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid approval policy ConfigMap %s: %w", key, err)
+	}
+	return append(policies, policy), nil
+}
+
+func parseApprovalPolicy(data map[string]string) (infrav1.ApprovalPolicySpec, error) {
+	policy := infrav1.ApprovalPolicySpec{
+		Approvers:      splitList(data[infrav1.ApprovalPolicyApproversKey]),
+		ApproverGroups: splitList(data[infrav1.ApprovalPolicyApproverGroupsKey]),
+	}
+
+	required, err := strconv.Atoi(strings.TrimSpace(data[infrav1.ApprovalPolicyRequiredApprovalsKey]))
+	if err != nil || required < 1 {
+		return policy, fmt.Errorf("%s must be a number greater than 0", infrav1.ApprovalPolicyRequiredApprovalsKey)
+	}
+	policy.RequiredApprovals = required
+	return policy, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// reconcileApprovals records the approvals of the pending plan in the status, and returns true if the plan
+// is approved by the approval policies. A plan is always approved when no approval policy applies.
+// The approvals of an expired plan are rejected.
+func (r *TerraformReconciler) reconcileApprovals(ctx context.Context, terraform infrav1.Terraform, revision string) (infrav1.Terraform, bool, error) {
+	if msg := expiredPlanApproval(terraform); msg != "" {
+		terraform = infrav1.TerraformApprovals(terraform, metav1.ConditionFalse, infrav1.PlanExpiredReason, msg)
+		return terraform, false, nil
+	}
+
+	planID := terraform.Status.Plan.Pending
+	if planID == "" {
+		// keep the approvals of the last plan
+		if c := apimeta.FindStatusCondition(terraform.Status.Conditions, infrav1.ConditionTypeApproval); c != nil && c.Reason == infrav1.PlanExpiredReason {
+			apimeta.RemoveStatusCondition(&terraform.Status.Conditions, infrav1.ConditionTypeApproval)
+		}
+		return terraform, true, nil
+	}
+
+	policies, err := r.approvalPolicies(ctx, terraform)
+	if err != nil {
+		terraform = infrav1.TerraformApprovals(terraform, metav1.ConditionFalse, infrav1.ApprovalPolicyInvalidReason, err.Error())
+		return terraform, false, err
+	}
+	if len(policies) == 0 {
+		terraform.Status.Approval = nil
+		apimeta.RemoveStatusCondition(&terraform.Status.Conditions, infrav1.ConditionTypeApproval)
+		return terraform, true, nil
+	}
+
+	approvals := infrav1.PlanApprovalList{}
+	if err := r.List(ctx, &approvals, client.InNamespace(terraform.Namespace)); err != nil {
+		return terraform, false, fmt.Errorf("unable to list the plan approvals: %w", err)
+	}
+	approval, notAllowed, expired := evaluateApprovals(policies, approvals.Items, terraform.Name, planID, terraform.Status.ExpiredPlan)
+
+	var previous []infrav1.PlanApprovalRecord
+	if terraform.Status.Approval != nil && terraform.Status.Approval.PlanID == planID {
+		previous = terraform.Status.Approval.Approvals
+	}
+	terraform.Status.Approval = &approval
+
+	for _, record := range approval.Approvals {
+		if slices.ContainsFunc(previous, func(p infrav1.PlanApprovalRecord) bool { return p.Approver == record.Approver }) {
+			continue
+		}
+		msg := fmt.Sprintf("Plan %s approved by %s (%d/%d)", planID, record.Approver, len(approval.Approvals), approval.RequiredApprovals)
+		r.event(ctx, terraform, revision, eventv1.EventSeverityInfo, msg, map[string]string{
+			"approver":   record.Approver,
+			"approvedAt": record.ApprovedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	if approval.Approved() {
+		msg := fmt.Sprintf("Plan %s approved by %s", planID, strings.Join(approvers(approval), ", "))
+		terraform = infrav1.TerraformApprovals(terraform, metav1.ConditionTrue, infrav1.ApprovalQuorumReachedReason, msg)
+		return terraform, true, nil
+	}
+
+	msg := fmt.Sprintf("Plan %s has %d of %d required approvals", planID, len(approval.Approvals), approval.RequiredApprovals)
+	if len(approval.Approvals) > 0 {
+		msg += fmt.Sprintf(", approved by %s", strings.Join(approvers(approval), ", "))
+	}
+	if len(notAllowed) > 0 {
+		msg += fmt.Sprintf(", ignoring the approvals of %s not allowed by the approval policy", strings.Join(notAllowed, ", "))
+	}
+	if len(expired) > 0 {
+		msg += fmt.Sprintf(", rejecting the approvals of %s given before the plan expired", strings.Join(expired, ", "))
+	}
+	terraform = infrav1.TerraformApprovals(terraform, metav1.ConditionFalse, infrav1.AwaitingApprovalsReason, msg)
+	return terraform, false, nil
+}
+
+// evaluateApprovals returns the approvals of the plan by distinct approvers allowed by all the policies,
+// the approvers not allowed, and the approvers who approved the plan before it expired and was planned
+// again with the same id. The number of required approvals is the highest of the policies.
+func evaluateApprovals(policies []infrav1.ApprovalPolicySpec, approvals []infrav1.PlanApproval, terraformName, planID string, expiredPlan *infrav1.ExpiredPlanStatus) (infrav1.ApprovalStatus, []string, []string) {
+	status := infrav1.ApprovalStatus{PlanID: planID}
+	for _, policy := range policies {
+		status.RequiredApprovals = max(status.RequiredApprovals, policy.RequiredApprovals)
+	}
+
+	sort.SliceStable(approvals, func(i, j int) bool {
+		return approvals[i].CreationTimestamp.Before(&approvals[j].CreationTimestamp)
+	})
+
+	var notAllowed, expired []string
+	for _, approval := range approvals {
+		if approval.Spec.TerraformRef.Name != terraformName || approval.Spec.PlanID != planID {
+			continue
+		}
+		if expiredPlan != nil && expiredPlan.PlanID == planID && !approval.CreationTimestamp.After(expiredPlan.ExpiredAt.Time) {
+			if !slices.Contains(expired, approval.Spec.Approver) {
+				expired = append(expired, approval.Spec.Approver)
+			}
+			continue
+		}
+		if isAllowedApprover(policies, approval.Spec) {
+			if !slices.ContainsFunc(status.Approvals, func(record infrav1.PlanApprovalRecord) bool { return record.Approver == approval.Spec.Approver }) {
+				status.Approvals = append(status.Approvals, infrav1.PlanApprovalRecord{
+					Approver:         approval.Spec.Approver,
+					ApprovedAt:       approval.CreationTimestamp,
+					PlanApprovalName: approval.Name,
+				})
+			}
+		} else if !slices.Contains(notAllowed, approval.Spec.Approver) {
+			notAllowed = append(notAllowed, approval.Spec.Approver)
+		}
+	}
+	return status, notAllowed, expired
+}
+
+// isAllowedApprover returns true if every policy allows the approver, by name or by group.
+func isAllowedApprover(policies []infrav1.ApprovalPolicySpec, approval infrav1.PlanApprovalSpec) bool {
+	for _, policy := range policies {
+		if len(policy.Approvers) == 0 && len(policy.ApproverGroups) == 0 {
+			continue
+		}
+		if slices.Contains(policy.Approvers, approval.Approver) {
+			continue
+		}
+		if slices.ContainsFunc(approval.Groups, func(group string) bool { return slices.Contains(policy.ApproverGroups, group) }) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func approvers(approval infrav1.ApprovalStatus) []string {
+	names := make([]string, 0, len(approval.Approvals))
+	for _, record := range approval.Approvals {
+		names = append(names, record.Approver)
+	}
+	return names
+}
+
+// requestsForPlanApproval enqueues the Terraform object approved by a PlanApproval.
+func (r *TerraformReconciler) requestsForPlanApproval(ctx context.Context, obj client.Object) []reconcile.Request {
+	approval, ok := obj.(*infrav1.PlanApproval)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: approval.Namespace, Name: approval.Spec.TerraformRef.Name}},
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/internal/metrics"
 	"github.com/flux-iac/tofu-controller/runner"
 	"github.com/fluxcd/pkg/runtime/acl"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
@@ -47,13 +49,24 @@ func (r *TerraformReconciler) setupTerraform(ctx context.Context, runnerClient r
 		), tfInstance, tmpDir, err
 	}
 
+	return r.setupTerraformWithArtifact(ctx, runnerClient, terraform, buf.Bytes(), revision, objectKey, reconciliationLoopID)
+}
+
+// setupTerraformWithArtifact extracts the tar.gz artifact in the runner, and initializes
+// Terraform in the working directory with the backend, variables and workspace of the object.
+func (r *TerraformReconciler) setupTerraformWithArtifact(ctx context.Context, runnerClient runner.RunnerClient, terraform infrav1.Terraform, artifact []byte, revision string, objectKey types.NamespacedName, reconciliationLoopID string) (infrav1.Terraform, string, string, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	tfInstance := "0"
+	tmpDir := ""
+
 	// we fix timeout of UploadAndExtract to be 30s
 	// ctx30s, cancelCtx30s := context.WithTimeout(ctx, 30*time.Second)
 	// defer cancelCtx30s()
 	uploadAndExtractReply, err := runnerClient.UploadAndExtract(ctx, &runner.UploadAndExtractRequest{
 		Namespace: terraform.Namespace,
 		Name:      terraform.Name,
-		TarGz:     buf.Bytes(),
+		TarGz:     artifact,
 		Path:      terraform.Spec.Path,
 	})
 	if err != nil {
@@ -67,57 +80,15 @@ func (r *TerraformReconciler) setupTerraform(ctx context.Context, runnerClient r
 	workingDir := uploadAndExtractReply.WorkingDir
 	tmpDir = uploadAndExtractReply.TmpDir
 
-	var backendConfig string
-	DisableTFK8SBackend := os.Getenv("DISABLE_TF_K8S_BACKEND") == "1"
-
-	if terraform.Spec.BackendConfig != nil && terraform.Spec.BackendConfig.CustomConfiguration != "" {
-		backendConfig = fmt.Sprintf(`
-terraform {
-  %v
-}
-`,
-			terraform.Spec.BackendConfig.CustomConfiguration)
-	} else if terraform.Spec.BackendConfig != nil {
-		backendConfig = fmt.Sprintf(`
-terraform {
-  backend "kubernetes" {
-    secret_suffix     = "%s"
-    in_cluster_config = %v
-    config_path       = "%s"
-    namespace         = "%s"
-    labels            = {
-      %s
-    }
-  }
-}
-`,
-			terraform.Spec.BackendConfig.SecretSuffix,
-			terraform.Spec.BackendConfig.InClusterConfig,
-			terraform.Spec.BackendConfig.ConfigPath,
-			terraform.Namespace,
-			getLabelsAsHCL(terraform.Labels, 6))
-	} else if DisableTFK8SBackend && terraform.Spec.BackendConfig == nil {
-		backendConfig = `
-terraform {
-  backend "local" { }
-}`
-	} else if terraform.Spec.BackendConfig == nil {
-		// TODO must be tested in cluster only
-		backendConfig = fmt.Sprintf(`
-terraform {
-  backend "kubernetes" {
-    secret_suffix     = "%s"
-    in_cluster_config = true
-    namespace         = "%s"
-    labels            = {
-      %s
-    }
-  }
-}
-`,
-			terraform.Name,
-			terraform.Namespace,
-			getLabelsAsHCL(terraform.Labels, 6))
+	backendConfig, err := r.getBackendConfig(ctx, terraform)
+	if err != nil {
+		err = fmt.Errorf("error rendering backend config: %w", err)
+		return infrav1.TerraformNotReady(
+			terraform,
+			revision,
+			infrav1.TFExecInitFailedReason,
+			err.Error(),
+		), tfInstance, tmpDir, err
 	}
 
 	if r.backendCompletelyDisable(terraform) {
@@ -143,7 +114,13 @@ terraform {
 			})
 		if err != nil {
 			log.Error(err, "write backend config error")
-			return terraform, tfInstance, tmpDir, err
+			err = fmt.Errorf("error writing backend config: %s", err)
+			return infrav1.TerraformNotReady(
+				terraform,
+				revision,
+				infrav1.TFExecInitFailedReason,
+				err.Error(),
+			), tfInstance, tmpDir, err
 		}
 		log.Info(fmt.Sprintf("write backend config: %s", writeBackendConfigReply.Message))
 	}
@@ -339,6 +316,11 @@ terraform {
 
 	log.Info("generated template")
 
+	terraform, err = r.migrateState(ctx, runnerClient, terraform, tfInstance, workingDir, revision, objectKey)
+	if err != nil {
+		return terraform, tfInstance, tmpDir, err
+	}
+
 	// TODO we currently use a fork version of TFExec to workaround the forceCopy bug
 	// https://github.com/hashicorp/terraform-exec/issues/262
 
@@ -352,7 +334,9 @@ terraform {
 		initRequest.ForceCopy = false
 	}
 
+	initStart := time.Now()
 	initReply, err := runnerClient.Init(ctx, initRequest)
+	r.MetricsRecorder.RecordStageDuration(terraform, metrics.StageInit, initStart)
 	if err != nil {
 		if st, ok := status.FromError(err); ok {
 			for _, detail := range st.Details() {
@@ -372,6 +356,10 @@ terraform {
 		), tfInstance, tmpDir, err
 	}
 	log.Info(fmt.Sprintf("init reply: %s", initReply.Message))
+	// a backend change is recorded by the state migration, or when it is skipped
+	if terraform.Status.Backend == nil {
+		terraform.Status.Backend = &infrav1.BackendStatus{Config: terraform.Spec.BackendConfig.DeepCopy()}
+	}
 
 	log.Info("tfexec initialized terraform")
 
@@ -436,3 +424,80 @@ func getLabelsAsHCL(labels map[string]string, indent int) string {
 
 	return strings.TrimSpace(result)
 }
+
+// getBackendConfig renders the backend configuration of the Terraform object.
+func (r *TerraformReconciler) getBackendConfig(ctx context.Context, terraform infrav1.Terraform) (string, error) {
+	var backendConfig string
+	DisableTFK8SBackend := os.Getenv("DISABLE_TF_K8S_BACKEND") == "1"
+
+	typedBackendConfig, err := r.renderTypedBackendConfig(ctx, terraform)
+	if err != nil {
+		return "", err
+	}
+
+	if terraform.Spec.BackendConfig != nil && terraform.Spec.BackendConfig.Sharded &&
+		(typedBackendConfig != "" || terraform.Spec.BackendConfig.CustomConfiguration != "") {
+		return "", fmt.Errorf("backendConfig.sharded can only be used with the kubernetes backend")
+	}
+
+	if typedBackendConfig != "" {
+		backendConfig = typedBackendConfig
+	} else if terraform.Spec.BackendConfig != nil && terraform.Spec.BackendConfig.CustomConfiguration != "" {
+		backendConfig = fmt.Sprintf(`
+terraform {
+  %v
+}
+`,
+			terraform.Spec.BackendConfig.CustomConfiguration)
+	} else if terraform.Spec.BackendConfig != nil && terraform.Spec.BackendConfig.Sharded {
+		// the address of the http backend is set by the runner, which serves the sharded state
+		backendConfig = `
+terraform {
+  backend "http" { }
+}
+`
+	} else if terraform.Spec.BackendConfig != nil {
+		backendConfig = fmt.Sprintf(`
+terraform {
+  backend "kubernetes" {
+    secret_suffix     = "%s"
+    in_cluster_config = %v
+    config_path       = "%s"
+    namespace         = "%s"
+    labels            = {
+      %s
+    }
+  }
+}
+`,
+			terraform.Spec.BackendConfig.SecretSuffix,
+			terraform.Spec.BackendConfig.InClusterConfig,
+			terraform.Spec.BackendConfig.ConfigPath,
+			terraform.Namespace,
+			getLabelsAsHCL(terraform.Labels, 6))
+	} else if DisableTFK8SBackend && terraform.Spec.BackendConfig == nil {
+		backendConfig = `
+terraform {
+  backend "local" { }
+}`
+	} else if terraform.Spec.BackendConfig == nil {
+		// TODO must be tested in cluster only
+		backendConfig = fmt.Sprintf(`
+terraform {
+  backend "kubernetes" {
+    secret_suffix     = "%s"
+    in_cluster_config = true
+    namespace         = "%s"
+    labels            = {
+      %s
+    }
+  }
+}
+`,
+			terraform.Name,
+			terraform.Namespace,
+			getLabelsAsHCL(terraform.Labels, 6))
+	}
+
+	return backendConfig, nil
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/fluxcd/pkg/apis/meta"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// backendAttribute is an attribute of a backend block, with its value already rendered as HCL.
+type backendAttribute struct {
+	name  string
+	value string
+}
+
+type backendAttributes []backendAttribute
+
+func (a *backendAttributes) addString(name, value string) {
+	if value != "" {
+		*a = append(*a, backendAttribute{name: name, value: hclString(value)})
+	}
+}
+
+func (a *backendAttributes) addBool(name string, value bool) {
+	if value {
+		*a = append(*a, backendAttribute{name: name, value: "true"})
+	}
+}
+
+func (a *backendAttributes) addObject(name string, values map[string]string) {
+	keys := []string{}
+	for k, v := range values {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	sort.Strings(keys)
+	fields := []string{}
+	for _, k := range keys {
+		fields = append(fields, fmt.Sprintf("%s = %s", k, hclString(values[k])))
+	}
+	*a = append(*a, backendAttribute{name: name, value: "{ " + strings.Join(fields, ", ") + " }"})
+}
+
+// hclString quotes the string for HCL, escaping the template sequences.
+func hclString(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	s = strings.ReplaceAll(s, "%{", "%%{")
+	return fmt.Sprintf("%q", s)
+}
+
+func renderBackendConfig(backendType string, attrs backendAttributes) string {
+	width := 0
+	for _, attr := range attrs {
+		if len(attr.name) > width {
+			width = len(attr.name)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("\nterraform {\n")
+	fmt.Fprintf(&b, "  backend %q {\n", backendType)
+	for _, attr := range attrs {
+		fmt.Fprintf(&b, "    %-*s = %s\n", width, attr.name, attr.value)
+	}
+	b.WriteString("  }\n}\n")
+	return b.String()
+}
+
+// renderTypedBackendConfig renders the backend configuration of the typed backend (s3, gcs, azurerm or pg)
+// of the Terraform object. It returns an empty string when no typed backend is specified.
+func (r *TerraformReconciler) renderTypedBackendConfig(ctx context.Context, terraform infrav1.Terraform) (string, error) {
+	spec := terraform.Spec.BackendConfig
+	if spec == nil {
+		return "", nil
+	}
+
+	specified := []string{}
+	if spec.S3 != nil {
+		specified = append(specified, "s3")
+	}
+	if spec.GCS != nil {
+		specified = append(specified, "gcs")
+	}
+	if spec.AzureRM != nil {
+		specified = append(specified, "azurerm")
+	}
+	if spec.PG != nil {
+		specified = append(specified, "pg")
+	}
+
+	if len(specified) == 0 {
+		return "", nil
+	}
+	if len(specified) > 1 {
+		return "", fmt.Errorf("only one backend can be specified in backendConfig, got: %s", strings.Join(specified, ", "))
+	}
+	if spec.CustomConfiguration != "" {
+		return "", fmt.Errorf("backendConfig.customConfiguration cannot be used together with backendConfig.%s", specified[0])
+	}
+
+	attrs := backendAttributes{}
+	switch {
+	case spec.S3 != nil:
+		credentials, err := r.getBackendCredentials(ctx, terraform, spec.S3.CredentialsSecretRef, "access_key", "secret_key", "token")
+		if err != nil {
+			return "", err
+		}
+		attrs.addString("bucket", spec.S3.Bucket)
+		attrs.addString("key", spec.S3.Key)
+		attrs.addString("region", spec.S3.Region)
+		attrs.addObject("endpoints", map[string]string{"s3": spec.S3.Endpoint})
+		attrs.addBool("use_path_style", spec.S3.UsePathStyle)
+		attrs.addString("dynamodb_table", spec.S3.DynamoDBTable)
+		attrs.addBool("encrypt", spec.S3.Encrypt)
+		attrs.addObject("assume_role", map[string]string{"role_arn": spec.S3.RoleARN})
+		attrs.addString("access_key", credentials["access_key"])
+		attrs.addString("secret_key", credentials["secret_key"])
+		attrs.addString("token", credentials["token"])
+		return renderBackendConfig("s3", attrs), nil
+
+	case spec.GCS != nil:
+		credentials, err := r.getBackendCredentials(ctx, terraform, spec.GCS.CredentialsSecretRef, "credentials", "access_token")
+		if err != nil {
+			return "", err
+		}
+		attrs.addString("bucket", spec.GCS.Bucket)
+		attrs.addString("prefix", spec.GCS.Prefix)
+		attrs.addString("credentials", credentials["credentials"])
+		attrs.addString("access_token", credentials["access_token"])
+		return renderBackendConfig("gcs", attrs), nil
+
+	case spec.AzureRM != nil:
+		credentials, err := r.getBackendCredentials(ctx, terraform, spec.AzureRM.CredentialsSecretRef, "access_key", "sas_token", "client_secret")
+		if err != nil {
+			return "", err
+		}
+		attrs.addString("storage_account_name", spec.AzureRM.StorageAccountName)
+		attrs.addString("container_name", spec.AzureRM.ContainerName)
+		attrs.addString("key", spec.AzureRM.Key)
+		attrs.addString("resource_group_name", spec.AzureRM.ResourceGroupName)
+		attrs.addString("subscription_id", spec.AzureRM.SubscriptionID)
+		attrs.addString("tenant_id", spec.AzureRM.TenantID)
+		attrs.addString("client_id", spec.AzureRM.ClientID)
+		attrs.addBool("use_azuread_auth", spec.AzureRM.UseAzureADAuth)
+		attrs.addString("access_key", credentials["access_key"])
+		attrs.addString("sas_token", credentials["sas_token"])
+		attrs.addString("client_secret", credentials["client_secret"])
+		return renderBackendConfig("azurerm", attrs), nil
+
+	default:
+		credentials, err := r.getBackendCredentials(ctx, terraform, &spec.PG.ConnectionSecretRef, "conn_str")
+		if err != nil {
+			return "", err
+		}
+		if credentials["conn_str"] == "" {
+			return "", fmt.Errorf("key conn_str not found in pg backend secret %s", spec.PG.ConnectionSecretRef.Name)
+		}
+		attrs.addString("conn_str", credentials["conn_str"])
+		attrs.addString("schema_name", spec.PG.SchemaName)
+		return renderBackendConfig("pg", attrs), nil
+	}
+}
+
+// getBackendCredentials reads the given keys of the backend credentials Secret.
+func (r *TerraformReconciler) getBackendCredentials(ctx context.Context, terraform infrav1.Terraform, secretRef *meta.LocalObjectReference, keys ...string) (map[string]string, error) {
+	credentials := map[string]string{}
+	if secretRef == nil {
+		return credentials, nil
+	}
+
+	secret := corev1.Secret{}
+	secretLookupKey := types.NamespacedName{
+		Namespace: terraform.Namespace,
+		Name:      secretRef.Name,
+	}
+	if err := r.Get(ctx, secretLookupKey, &secret); err != nil {
+		return nil, fmt.Errorf("unable to get backend credentials secret %s: %w", secretLookupKey, err)
+	}
+
+	for _, key := range keys {
+		if value, ok := secret.Data[key]; ok {
+			credentials[key] = strings.TrimSpace(string(value))
+		}
+	}
+
+	return credentials, nil
+}
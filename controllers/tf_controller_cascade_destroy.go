@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/runtime/logger"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// shouldDestroyResourcesOnDeletion returns true if the resources managed by the object
+// must be destroyed when it is deleted. A cascade destroy implies destroying resources,
+// except for plan-only objects (e.g. the ones created by the branch planner), as they share
+// the state of the object they were copied from.
+func shouldDestroyResourcesOnDeletion(terraform infrav1.Terraform) bool {
+	if terraform.Spec.DestroyResourcesOnDeletion {
+		return true
+	}
+
+	return terraform.IsCascadeDestroyRequested() && !terraform.Spec.PlanOnly
+}
+
+// getDependants returns every Terraform object that lists the given object in its dependsOn.
+// Dependants from other namespaces are ignored when cross-namespace references are disabled.
+func (r *TerraformReconciler) getDependants(ctx context.Context, terraform infrav1.Terraform) ([]infrav1.Terraform, error) {
+	var opts []client.ListOption
+	if r.NoCrossNamespaceRefs {
+		opts = append(opts, client.InNamespace(terraform.GetNamespace()))
+	}
+
+	var list infrav1.TerraformList
+	if err := r.List(ctx, &list, opts...); err != nil {
+		return nil, fmt.Errorf("unable to list Terraform objects: %w", err)
+	}
+
+	var dependants []infrav1.Terraform
+	for _, tf := range list.Items {
+		for _, d := range tf.Spec.DependsOn {
+			namespace := d.Namespace
+			if namespace == "" {
+				namespace = tf.GetNamespace()
+			}
+
+			if d.Name == terraform.GetName() && namespace == terraform.GetNamespace() {
+				dependants = append(dependants, tf)
+				break
+			}
+		}
+	}
+
+	sort.Slice(dependants, func(i, j int) bool {
+		return client.ObjectKeyFromObject(&dependants[i]).String() < client.ObjectKeyFromObject(&dependants[j]).String()
+	})
+
+	return dependants, nil
+}
+
+// cascadeDestroy deletes the dependants of an object being deleted with the cascade destroy
+// annotation. Each dependant receives the same annotation, so it deletes its own dependants
+// in turn. The dependency finalizers then release the objects from the leaves up to the root.
+func (r *TerraformReconciler) cascadeDestroy(ctx context.Context, terraform infrav1.Terraform, dependantFinalizers []string) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	traceLog := log.V(logger.TraceLevel).WithValues("function", "TerraformReconciler.cascadeDestroy")
+	objectKey := types.NamespacedName{Namespace: terraform.Namespace, Name: terraform.Name}
+
+	traceLog.Info("Get the dependants of the Terraform resource")
+	dependants, err := r.getDependants(ctx, terraform)
+	if err != nil {
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	requestedAt := terraform.GetAnnotations()[infrav1.CascadeDestroyAnnotation]
+
+	waitingFor := []string{}
+	for _, dependant := range dependants {
+		dependantKey := client.ObjectKeyFromObject(&dependant).String()
+		waitingFor = append(waitingFor, dependantKey)
+
+		if isBeingDeleted(dependant) {
+			traceLog.Info("Dependant is already being deleted", "dependant", dependantKey)
+			continue
+		}
+
+		traceLog.Info("Propagate the cascade destroy annotation to the dependant", "dependant", dependantKey)
+		patch := client.MergeFrom(dependant.DeepCopy())
+		annotations := dependant.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[infrav1.CascadeDestroyAnnotation] = requestedAt
+		dependant.SetAnnotations(annotations)
+		if err := r.Patch(ctx, &dependant, patch, client.FieldOwner(r.statusManager)); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return ctrl.Result{Requeue: true}, fmt.Errorf("unable to annotate dependant '%s' for cascade destroy: %w", dependantKey, err)
+		}
+
+		traceLog.Info("Delete the dependant", "dependant", dependantKey)
+		if err := r.Delete(ctx, &dependant); client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{Requeue: true}, fmt.Errorf("unable to delete dependant '%s' for cascade destroy: %w", dependantKey, err)
+		}
+
+		msg := fmt.Sprintf("Cascade destroy: deleting dependant %s", dependantKey)
+		log.Info(msg)
+		r.event(ctx, terraform, "", eventv1.EventSeverityInfo, msg, nil)
+	}
+
+	// the finalizers can outlive the dependants, e.g. when a dependant is gone
+	// but has not released its finalizer yet.
+	if len(waitingFor) == 0 {
+		waitingFor = dependantFinalizers
+	}
+
+	msg := fmt.Sprintf("Cascade destroy in progress, waiting for dependants to be destroyed: %s", strings.Join(waitingFor, ", "))
+	terraform = infrav1.TerraformNotReady(terraform, "", infrav1.CascadeDestroyInProgressReason, msg)
+	if err := r.patchStatus(ctx, objectKey, terraform.Status); err != nil {
+		log.Error(err, "unable to update status for cascade destroy")
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	return ctrl.Result{RequeueAfter: terraform.GetRetryInterval()}, nil
+}
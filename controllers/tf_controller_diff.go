@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/runner"
+)
+
+// rejectDiffRequest records the diff request which is invalid in the status, so that it is reported once.
+func (r *TerraformReconciler) rejectDiffRequest(ctx context.Context, terraform infrav1.Terraform, err error, revision string) infrav1.Terraform {
+	log := ctrl.LoggerFrom(ctx)
+
+	log.Error(err, "invalid diff request")
+	terraform.Status.Diff = &infrav1.DiffStatus{
+		RequestedBy:     terraform.GetAnnotations()[infrav1.DiffRequestedByAnnotation],
+		Succeeded:       false,
+		Message:         err.Error(),
+		HandledAt:       &metav1.Time{Time: time.Now()},
+		RejectedRequest: terraform.GetAnnotations()[infrav1.DiffAnnotation],
+	}
+	r.event(ctx, terraform, revision, eventv1.EventSeverityError, err.Error(), nil)
+	return terraform
+}
+
+// handleDiff plans the configuration of the diff request with the vars, backend and workspace of the object,
+// writes the human readable plan to the diff output Secret, and records the result in the status.
+// The plan is neither saved nor applied, and the Secret holding the configuration is deleted once planned.
+func (r *TerraformReconciler) handleDiff(ctx context.Context, runnerClient runner.RunnerClient, terraform infrav1.Terraform, req infrav1.DiffRequest, revision string, reconciliationLoopID string) infrav1.Terraform {
+	log := ctrl.LoggerFrom(ctx)
+
+	// the user is taken from the requested-by annotation, which the admission policy verifies
+	user := terraform.GetAnnotations()[infrav1.DiffRequestedByAnnotation]
+	requestedBy := user
+	if requestedBy == "" {
+		requestedBy = "unknown user"
+	}
+
+	result := &infrav1.DiffStatus{
+		ID:          req.ID,
+		RequestedBy: user,
+	}
+
+	var message, output string
+	var hasChanges bool
+	artifact, err := r.getDiffArtifact(ctx, terraform, req)
+	if err == nil {
+		message, output, hasChanges, err = r.doDiff(ctx, runnerClient, terraform, artifact.Data[infrav1.DiffArtifactDataKey], revision, reconciliationLoopID)
+
+		if err := r.Delete(ctx, artifact); err != nil && !apierrors.IsNotFound(err) {
+			log.Error(err, "unable to delete the configuration of the diff", "secret", artifact.Name)
+		}
+	}
+	if err == nil {
+		result.OutputSecretName = infrav1.DiffOutputSecretName(terraform.Name)
+		_, err = runnerClient.WriteOutputs(ctx, &runner.WriteOutputsRequest{
+			Namespace:  terraform.Namespace,
+			Name:       terraform.Name,
+			SecretName: result.OutputSecretName,
+			Uuid:       string(terraform.UID),
+			Data:       map[string][]byte{infrav1.DiffOutputDataKey: []byte(output)},
+		})
+		if err != nil {
+			result.OutputSecretName = ""
+			err = fmt.Errorf("unable to write the plan of the diff: %w", err)
+		}
+	}
+
+	metadata := map[string]string{
+		infrav1.GroupVersion.Group + "/requested-by": requestedBy,
+	}
+	severity := eventv1.EventSeverityInfo
+	if err != nil {
+		result.Succeeded = false
+		result.Message = err.Error()
+		severity = eventv1.EventSeverityError
+		log.Error(err, "diff failed", "requested-by", requestedBy)
+	} else {
+		result.Succeeded = true
+		result.HasChanges = hasChanges
+		result.Message = message
+		log.Info("diff succeeded", "requested-by", requestedBy)
+	}
+	result.HandledAt = &metav1.Time{Time: time.Now()}
+
+	terraform.Status.Diff = result
+	r.event(ctx, terraform, revision, severity, fmt.Sprintf("%s requested a diff: %s", requestedBy, result.Message), metadata)
+
+	return terraform
+}
+
+// getDiffArtifact returns the Secret holding the configuration of the diff request,
+// which must be labelled as the configuration of a diff of the object.
+func (r *TerraformReconciler) getDiffArtifact(ctx context.Context, terraform infrav1.Terraform, req infrav1.DiffRequest) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: terraform.Namespace, Name: req.ArtifactSecretName}
+	if err := r.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("unable to get the configuration of the diff %s: %w", key.Name, err)
+	}
+	if secret.Labels[infrav1.DiffLabel] != terraform.Name {
+		return nil, fmt.Errorf("secret %s is not labelled as the configuration of a diff of %s", key.Name, terraform.Name)
+	}
+	return secret, nil
+}
+
+// doDiff sets up Terraform with the packaged configuration, plans it, and returns
+// a summary of the plan together with the human readable plan, and whether it has changes.
+func (r *TerraformReconciler) doDiff(ctx context.Context, runnerClient runner.RunnerClient, terraform infrav1.Terraform, artifact []byte, revision string, reconciliationLoopID string) (string, string, bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if terraform.Spec.Cloud != nil {
+		return "", "", false, fmt.Errorf("diff is not supported with Terraform Cloud")
+	}
+	if terraform.IsMigrateStateRequested() {
+		return "", "", false, fmt.Errorf("diff is not possible while a state migration is requested")
+	}
+
+	objectKey := types.NamespacedName{Namespace: terraform.Namespace, Name: terraform.Name}
+	_, tfInstance, tmpDir, err := r.setupTerraformWithArtifact(ctx, runnerClient, terraform, artifact, revision, objectKey, reconciliationLoopID)
+	defer func() {
+		if tmpDir == "" {
+			return
+		}
+		if _, err := runnerClient.CleanupDir(ctx, &runner.CleanupDirRequest{TmpDir: tmpDir}); err != nil {
+			log.Error(err, "clean up error")
+		}
+	}()
+	if err != nil {
+		return "", "", false, err
+	}
+
+	planRequest := &runner.PlanRequest{
+		TfInstance:       tfInstance,
+		Out:              runner.TFPlanName,
+		Refresh:          true,
+		Destroy:          terraform.Spec.Destroy,
+		SourceRefRootDir: tmpDir,
+	}
+	if terraform.Spec.TFState != nil && terraform.Spec.TFState.LockTimeout.Duration != 0 {
+		planRequest.LockTimeout = terraform.Spec.TFState.LockTimeout.Duration.String()
+	}
+
+	planReply, err := runnerClient.Plan(ctx, planRequest)
+	if err != nil {
+		return "", "", false, fmt.Errorf("error running Plan: %w", err)
+	}
+
+	showReply, err := runnerClient.ShowPlanFileRaw(ctx, &runner.ShowPlanFileRawRequest{
+		TfInstance: tfInstance,
+		Filename:   runner.TFPlanName,
+	})
+	if err != nil {
+		return "", "", false, fmt.Errorf("error showing the plan: %w", err)
+	}
+
+	message := "No changes"
+	if planReply.Drifted {
+		message = fmt.Sprintf("%d to add, %d to change, %d to destroy", planReply.ToAdd, planReply.ToChange, planReply.ToDestroy)
+	}
+	return message, showReply.RawOutput, planReply.Drifted, nil
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	tfjson "github.com/hashicorp/terraform-json"
 
 	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/internal/metrics"
 	"github.com/flux-iac/tofu-controller/runner"
 	"google.golang.org/grpc/status"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -80,7 +83,9 @@ func (r *TerraformReconciler) detectDrift(ctx context.Context, terraform infrav1
 	}
 
 	eventSent := false
+	planStart := time.Now()
 	planReply, err := runnerClient.Plan(ctx, planRequest)
+	r.MetricsRecorder.RecordStageDuration(terraform, metrics.StageDriftDetection, planStart)
 	if err != nil {
 		if st, ok := status.FromError(err); ok {
 			for _, detail := range st.Details() {
@@ -109,6 +114,35 @@ func (r *TerraformReconciler) detectDrift(ctx context.Context, terraform infrav1
 	drifted := planReply.Drifted
 	log.Info(fmt.Sprintf("plan for drift: %s found drift: %v", planReply.Message, planReply.Drifted))
 
+	var driftPlan *tfjson.Plan
+	if drifted && !r.backendCompletelyDisable(terraform) {
+		if driftPlan, err = r.showDriftPlan(ctx, tfInstance, runnerClient, driftFilename); err != nil {
+			return infrav1.TerraformNotReady(
+				terraform,
+				revision,
+				infrav1.DriftDetectionFailedReason,
+				err.Error(),
+			), err
+		}
+	}
+
+	if driftPlan != nil && terraform.Spec.DriftPolicy != nil {
+		drift := evaluateDriftPolicy(terraform.Spec.DriftPolicy, driftPlan.ResourceChanges)
+		drift.Revision = revision
+		terraform.Status.Drift = &drift
+
+		// drift entirely ignored by the policy keeps the object ready, with its own reason
+		if len(drift.Ignore) > 0 && len(drift.Correct) == 0 && len(drift.Alert) == 0 {
+			msg := fmt.Sprintf("Drift ignored by the drift policy: %s", strings.Join(drift.Ignore, ", "))
+			log.Info(msg)
+			r.deleteDriftReport(ctx, &terraform)
+			terraform = infrav1.TerraformNoDrift(terraform, revision, infrav1.DriftIgnoredReason, msg)
+			return terraform, nil
+		}
+	} else {
+		terraform.Status.Drift = nil
+	}
+
 	if drifted {
 		var rawOutput string
 		if r.backendCompletelyDisable(terraform) {
@@ -134,13 +168,23 @@ func (r *TerraformReconciler) detectDrift(ctx context.Context, terraform infrav1
 		rawOutput = strings.Replace(rawOutput, "You can apply this plan to save these new output values to the Terraform\nstate, without changing any real infrastructure.", "", 1)
 
 		msg := fmt.Sprintf("Drift detected.\n%s", rawOutput)
+		if drift := terraform.Status.Drift; drift != nil {
+			msg = fmt.Sprintf("Drift detected.\n%s\n%s", driftPolicySummary(*drift), rawOutput)
+		}
 		r.event(ctx, terraform, revision, eventv1.EventSeverityError, msg, nil)
 
+		if driftPlan != nil {
+			if err := r.writeDriftReport(ctx, &terraform, revision, driftPlan); err != nil {
+				log.Error(err, "unable to write the drift report")
+			}
+		}
+
 		// If drift detected & we use the auto mode, then we continue
 		terraform = infrav1.TerraformDriftDetected(terraform, revision, infrav1.DriftDetectedReason, rawOutput)
 		return terraform, fmt.Errorf(infrav1.DriftDetectedReason)
 	}
 
+	r.deleteDriftReport(ctx, &terraform)
 	terraform = infrav1.TerraformNoDrift(terraform, revision, infrav1.NoDriftReason, "No drift")
 	return terraform, nil
 }
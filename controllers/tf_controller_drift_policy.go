@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/runner"
+)
+
+// showDriftPlan reads the drift detection plan, to classify and report its drifted resources.
+func (r *TerraformReconciler) showDriftPlan(ctx context.Context, tfInstance string, runnerClient runner.RunnerClient, planFilename string) (*tfjson.Plan, error) {
+	showPlanFileReply, err := runnerClient.ShowPlanFile(ctx, &runner.ShowPlanFileRequest{
+		TfInstance: tfInstance,
+		Filename:   planFilename,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var plan tfjson.Plan
+	if err := json.Unmarshal(showPlanFileReply.JsonOutput, &plan); err != nil {
+		return nil, fmt.Errorf("unable to read the drift detection plan: %w", err)
+	}
+	return &plan, nil
+}
+
+// isDriftChange returns true if the resource change corrects drift.
+func isDriftChange(rc *tfjson.ResourceChange) bool {
+	return rc != nil && rc.Change != nil && !rc.Change.Actions.NoOp() && !rc.Change.Actions.Read()
+}
+
+// evaluateDriftPolicy classifies the resources changed by a plan by the action of the drift policy.
+func evaluateDriftPolicy(policy *infrav1.DriftPolicySpec, changes []*tfjson.ResourceChange) infrav1.DriftStatus {
+	drift := infrav1.DriftStatus{}
+	for _, rc := range changes {
+		if !isDriftChange(rc) {
+			continue
+		}
+
+		switch resourceDriftAction(policy, rc) {
+		case infrav1.DriftActionIgnore:
+			drift.Ignore = append(drift.Ignore, rc.Address)
+		case infrav1.DriftActionAlert:
+			drift.Alert = append(drift.Alert, rc.Address)
+		default:
+			drift.Correct = append(drift.Correct, rc.Address)
+		}
+	}
+
+	sort.Strings(drift.Correct)
+	sort.Strings(drift.Alert)
+	sort.Strings(drift.Ignore)
+	return drift
+}
+
+// resourceDriftAction returns the strongest action of the changed attributes of a resource.
+// The creation or deletion of a resource only matches the rules without attributes.
+func resourceDriftAction(policy *infrav1.DriftPolicySpec, rc *tfjson.ResourceChange) string {
+	if !rc.Change.Actions.Update() {
+		return matchDriftRule(policy, rc.Address, "")
+	}
+
+	action := ""
+	for _, attribute := range changedAttributes(rc.Change) {
+		action = strongestDriftAction(action, matchDriftRule(policy, rc.Address, attribute))
+	}
+	if action == "" {
+		// the change is not visible in the top level attributes, e.g. a sensitive value
+		return matchDriftRule(policy, rc.Address, "")
+	}
+	return action
+}
+
+// matchDriftRule returns the action of the first rule matching the address and the attribute.
+// An empty attribute only matches the rules without attributes.
+func matchDriftRule(policy *infrav1.DriftPolicySpec, address, attribute string) string {
+	for _, rule := range policy.Rules {
+		if !matchAnyGlob(rule.Addresses, address) {
+			continue
+		}
+		if len(rule.Attributes) > 0 && !slices.Contains(rule.Attributes, attribute) {
+			continue
+		}
+		return rule.Action
+	}
+	return policy.GetDefault()
+}
+
+func strongestDriftAction(a, b string) string {
+	rank := map[string]int{
+		infrav1.DriftActionIgnore:  1,
+		infrav1.DriftActionAlert:   2,
+		infrav1.DriftActionCorrect: 3,
+	}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// changedAttributes returns the sorted names of the top level attributes changed by an update.
+func changedAttributes(change *tfjson.Change) []string {
+	before, _ := change.Before.(map[string]interface{})
+	after, _ := change.After.(map[string]interface{})
+	unknown, _ := change.AfterUnknown.(map[string]interface{})
+
+	names := map[string]struct{}{}
+	for name, value := range before {
+		if !reflect.DeepEqual(value, after[name]) {
+			names[name] = struct{}{}
+		}
+	}
+	for name, value := range after {
+		if _, ok := before[name]; !ok && value != nil {
+			names[name] = struct{}{}
+		}
+	}
+	for name, value := range unknown {
+		if value == true {
+			names[name] = struct{}{}
+		}
+	}
+
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// matchAnyGlob returns true if the address matches one of the globs. Only * is a wildcard,
+// so that the brackets of indexed addresses are matched literally.
+func matchAnyGlob(globs []string, address string) bool {
+	for _, glob := range globs {
+		pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(glob), `\*`, ".*") + "$"
+		if matched, _ := regexp.MatchString(pattern, address); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// driftCorrectionTargets returns the targets of the plan correcting the drift. When the drift policy
+// only corrects some of the drifted resources, the plan targets them, leaving the others drifted.
+// The targets of the spec still scope the plan, so only the resources to correct within them are kept.
+func driftCorrectionTargets(terraform infrav1.Terraform, revision string) []string {
+	drift := terraform.Status.Drift
+	if drift == nil || drift.Revision != revision || len(drift.Correct) == 0 ||
+		len(drift.Alert)+len(drift.Ignore) == 0 {
+		return terraform.Spec.Targets
+	}
+	if len(terraform.Spec.Targets) == 0 {
+		return drift.Correct
+	}
+
+	var targets []string
+	for _, address := range drift.Correct {
+		if isTargeted(terraform.Spec.Targets, address) {
+			targets = append(targets, address)
+		}
+	}
+	if len(targets) == 0 {
+		return terraform.Spec.Targets
+	}
+	return targets
+}
+
+// isTargeted returns true if the resource address is one of the targets, or is within one of them,
+// e.g. a resource of a targeted module or an instance of a targeted resource.
+func isTargeted(targets []string, address string) bool {
+	for _, target := range targets {
+		if address == target || strings.HasPrefix(address, target+".") || strings.HasPrefix(address, target+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldCorrectDrift returns false if the drift policy only alerts on the detected drift.
+func shouldCorrectDrift(terraform infrav1.Terraform) bool {
+	drift := terraform.Status.Drift
+	return terraform.Spec.DriftPolicy == nil || drift == nil || len(drift.Correct) > 0 || len(drift.Alert) == 0
+}
+
+// driftPolicySummary lists the drifted resources by action of the drift policy.
+func driftPolicySummary(drift infrav1.DriftStatus) string {
+	var lines []string
+	for _, group := range []struct {
+		title     string
+		addresses []string
+	}{
+		{"To correct", drift.Correct},
+		{"Alert only", drift.Alert},
+		{"Ignored", drift.Ignore},
+	} {
+		if len(group.addresses) > 0 {
+			lines = append(lines, fmt.Sprintf("%s: %s", group.title, strings.Join(group.addresses, ", ")))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+// writeDriftReport stores the report of the drift found in the plan in a ConfigMap owned by the object,
+// and references it from the status.
+func (r *TerraformReconciler) writeDriftReport(ctx context.Context, terraform *infrav1.Terraform, revision string, plan *tfjson.Plan) error {
+	report := buildDriftReport(terraform.Spec.DriftPolicy, plan.ResourceChanges, revision, metav1.Now())
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      infrav1.DriftReportConfigMapName(terraform.Name),
+			Namespace: terraform.Namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		cm.Data = map[string]string{infrav1.DriftReportDataKey: string(data)}
+		return controllerutil.SetOwnerReference(terraform, cm, r.Scheme)
+	}); err != nil {
+		return fmt.Errorf("unable to write the drift report ConfigMap %s: %w", cm.Name, err)
+	}
+
+	terraform.Status.DriftReportConfigMapName = cm.Name
+	return nil
+}
+
+// deleteDriftReport removes the report of a drift that is gone.
+func (r *TerraformReconciler) deleteDriftReport(ctx context.Context, terraform *infrav1.Terraform) {
+	if terraform.Status.DriftReportConfigMapName == "" {
+		return
+	}
+
+	cm := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: terraform.Namespace, Name: terraform.Status.DriftReportConfigMapName}
+	if err := r.Get(ctx, key, cm); err == nil {
+		if err := r.Delete(ctx, cm); err != nil && !apierrors.IsNotFound(err) {
+			ctrl.LoggerFrom(ctx).Error(err, "unable to delete the drift report", "configmap", key.Name)
+			return
+		}
+	} else if !apierrors.IsNotFound(err) {
+		ctrl.LoggerFrom(ctx).Error(err, "unable to get the drift report", "configmap", key.Name)
+		return
+	}
+	terraform.Status.DriftReportConfigMapName = ""
+}
+
+// buildDriftReport reports the drifted resources of a plan, with the before and after values of their
+// changed attributes. Sensitive values are redacted, and the report is bounded to DriftReportMaxSize.
+func buildDriftReport(policy *infrav1.DriftPolicySpec, changes []*tfjson.ResourceChange, revision string, detectedAt metav1.Time) infrav1.DriftReport {
+	report := infrav1.DriftReport{
+		Revision:   revision,
+		DetectedAt: detectedAt,
+		Resources:  []infrav1.DriftedResource{},
+	}
+
+	size := 0
+	for _, rc := range changes {
+		if !isDriftChange(rc) {
+			continue
+		}
+
+		resource := infrav1.DriftedResource{Address: rc.Address}
+		for _, action := range rc.Change.Actions {
+			resource.Actions = append(resource.Actions, string(action))
+		}
+		if policy != nil {
+			resource.PolicyAction = resourceDriftAction(policy, rc)
+		}
+
+		before, _ := rc.Change.Before.(map[string]interface{})
+		after, _ := rc.Change.After.(map[string]interface{})
+		unknown, _ := rc.Change.AfterUnknown.(map[string]interface{})
+		for _, name := range changedAttributes(rc.Change) {
+			attribute := infrav1.DriftedAttribute{Name: name}
+			if _, ok := before[name]; ok {
+				attribute.Before = driftReportValue(before[name], isSensitiveAttribute(rc.Change.BeforeSensitive, name), &report)
+			}
+			if unknown[name] == true {
+				attribute.After = infrav1.DriftReportUnknownValue
+			} else if _, ok := after[name]; ok {
+				attribute.After = driftReportValue(after[name], isSensitiveAttribute(rc.Change.AfterSensitive, name), &report)
+			}
+			resource.Attributes = append(resource.Attributes, attribute)
+		}
+
+		encoded, _ := json.Marshal(resource)
+		if size+len(encoded) > infrav1.DriftReportMaxSize {
+			report.Truncated = true
+			break
+		}
+		size += len(encoded)
+		report.Resources = append(report.Resources, resource)
+	}
+	return report
+}
+
+// driftReportValue encodes a value in JSON, redacted if it is sensitive and shortened if it is too long.
+func driftReportValue(value interface{}, sensitive bool, report *infrav1.DriftReport) string {
+	if sensitive {
+		return infrav1.DriftReportSensitiveValue
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	if len(encoded) > infrav1.DriftReportMaxValueLength {
+		report.Truncated = true
+		return string(encoded[:infrav1.DriftReportMaxValueLength]) + "..."
+	}
+	return string(encoded)
+}
+
+// isSensitiveAttribute returns true if the sensitivity marks of a resource mark the attribute,
+// or any value nested in it, as sensitive.
+func isSensitiveAttribute(marks interface{}, name string) bool {
+	if marks == true {
+		return true
+	}
+	attributes, ok := marks.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	return containsSensitiveMark(attributes[name])
+}
+
+func containsSensitiveMark(marks interface{}) bool {
+	switch m := marks.(type) {
+	case bool:
+		return m
+	case map[string]interface{}:
+		for _, v := range m {
+			if containsSensitiveMark(v) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, v := range m {
+			if containsSensitiveMark(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/internal/tracing"
+	"github.com/flux-iac/tofu-controller/runner"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// driftDetectionBusyRequeue is how long to wait for a drift detection slot when all of them are taken.
+var driftDetectionBusyRequeue = 30 * time.Second
+
+// driftDetectionLimiter caps the number of drift detections running at once across the controller.
+// A nil limiter does not limit them.
+type driftDetectionLimiter chan struct{}
+
+func newDriftDetectionLimiter(concurrency int) driftDetectionLimiter {
+	if concurrency <= 0 {
+		return nil
+	}
+	return make(driftDetectionLimiter, concurrency)
+}
+
+// tryAcquire takes a drift detection slot without waiting, and returns false if none is free.
+func (l driftDetectionLimiter) tryAcquire() bool {
+	if l == nil {
+		return true
+	}
+	select {
+	case l <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l driftDetectionLimiter) release() {
+	if l != nil {
+		<-l
+	}
+}
+
+// driftDetectionDeferredError is returned by reconcile when drift detection is not run now,
+// because it is not due yet or all drift detection slots are taken. It is not a failure.
+type driftDetectionDeferredError struct {
+	message      string
+	requeueAfter time.Duration
+}
+
+func (e *driftDetectionDeferredError) Error() string {
+	return e.message
+}
+
+// deferDriftDetection returns why drift detection must not run now, or nil if it is due.
+func (r *TerraformReconciler) deferDriftDetection(terraform infrav1.Terraform, now time.Time) *driftDetectionDeferredError {
+	if next := terraform.NextDriftDetectionAt(); !next.IsZero() && now.Before(next) {
+		requeueAfter := next.Sub(now)
+		if requeueAfter > terraform.Spec.Interval.Duration {
+			requeueAfter = terraform.Spec.Interval.Duration
+		}
+		return &driftDetectionDeferredError{
+			message:      fmt.Sprintf("drift detection is due at %s", next.Format(time.RFC3339)),
+			requeueAfter: requeueAfter,
+		}
+	}
+	return nil
+}
+
+// detectDriftWhenDue runs drift detection once it is due and a drift detection slot is free,
+// and holds the slot until drift detection is done. Otherwise, it returns a driftDetectionDeferredError.
+func (r *TerraformReconciler) detectDriftWhenDue(ctx context.Context, terraform infrav1.Terraform, tfInstance string, runnerClient runner.RunnerClient, revision string, sourceRefRootDir string, now time.Time) (infrav1.Terraform, error) {
+	if deferred := r.deferDriftDetection(terraform, now); deferred != nil {
+		return terraform, deferred
+	}
+
+	if !r.driftDetectionLimiter.tryAcquire() {
+		return terraform, &driftDetectionDeferredError{
+			message:      fmt.Sprintf("all %d drift detection slots are taken", cap(r.driftDetectionLimiter)),
+			requeueAfter: driftDetectionBusyRequeue,
+		}
+	}
+	defer r.driftDetectionLimiter.release()
+
+	driftCtx, span := tracing.Start(ctx, "drift-detection", terraform.Namespace, terraform.Name)
+	terraform, err := r.detectDrift(driftCtx, terraform, tfInstance, runnerClient, revision, sourceRefRootDir)
+	endDriftDetectionSpan(span, err)
+	return terraform, err
+}
+
+// endDriftDetectionSpan ends the drift detection span. Detected drift is an outcome of drift detection,
+// not a failure, so it is recorded as the drift.detected attribute, and leaves the span status unset.
+func endDriftDetectionSpan(span trace.Span, err error) {
+	drifted := err != nil && err.Error() == infrav1.DriftDetectedReason
+	span.SetAttributes(attribute.Bool("drift.detected", drifted))
+	if drifted {
+		err = nil
+	}
+	tracing.End(span, err)
+}
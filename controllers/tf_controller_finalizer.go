@@ -26,7 +26,7 @@ func (r *TerraformReconciler) finalize(ctx context.Context, terraform infrav1.Te
 
 	// TODO how to completely delete without planning?
 	traceLog.Info("Check if we need to Destroy on Delete")
-	if terraform.Spec.DestroyResourcesOnDeletion {
+	if shouldDestroyResourcesOnDeletion(terraform) {
 
 		for _, finalizer := range terraform.GetFinalizers() {
 			if strings.HasPrefix(finalizer, infrav1.TFDependencyOfPrefix) {
@@ -91,6 +91,11 @@ func (r *TerraformReconciler) finalize(ctx context.Context, terraform infrav1.Te
 				return terraform, controllerruntime.Result{Requeue: true}, err
 			}
 
+			if shouldProcessWebhooks(terraform, infrav1.PostApplyWebhook) {
+				log.Info("calling post-apply webhooks")
+				terraform = r.processPostApplyWebhooks(ctx, terraform, runnerClient, revision, tfInstance)
+			}
+
 			traceLog.Info("Patch status of the Terraform resource")
 			if err := r.patchStatus(ctx, objectKey, terraform.Status); err != nil {
 				log.Error(err, "unable to update status after applying")
@@ -158,6 +163,18 @@ func (r *TerraformReconciler) finalize(ctx context.Context, terraform infrav1.Te
 	}
 
 	// Remove the dependant finalizer from every dependency
+	if err := r.releaseDependencies(terraform); err != nil {
+		return terraform, controllerruntime.Result{}, err
+	}
+
+	// Stop reconciliation as the object is being deleted
+	traceLog.Info("Return success")
+	return terraform, controllerruntime.Result{}, nil
+}
+
+// releaseDependencies removes the dependant finalizer of the given object from each of its
+// dependencies, which lets a dependency being deleted, e.g. by a cascade destroy, go.
+func (r *TerraformReconciler) releaseDependencies(terraform infrav1.Terraform) error {
 	dependantFinalizer := infrav1.TFDependencyOfPrefix + terraform.GetName()
 	for _, d := range terraform.Spec.DependsOn {
 		if d.Namespace == "" {
@@ -170,21 +187,18 @@ func (r *TerraformReconciler) finalize(ctx context.Context, terraform infrav1.Te
 		var tf infrav1.Terraform
 		err := r.Get(context.Background(), dName, &tf)
 		if err != nil {
-			return terraform, controllerruntime.Result{}, err
+			return err
 		}
 
-		// add finalizer to the dependency
 		if controllerutil.ContainsFinalizer(&tf, dependantFinalizer) {
 			controllerutil.RemoveFinalizer(&tf, dependantFinalizer)
 			if err := r.Update(context.Background(), &tf, client.FieldOwner(r.statusManager)); err != nil {
-				return terraform, controllerruntime.Result{}, err
+				return err
 			}
 		}
 	}
 
-	// Stop reconciliation as the object is being deleted
-	traceLog.Info("Return success")
-	return terraform, controllerruntime.Result{}, nil
+	return nil
 }
 
 func thereIsNothingToDestroy(terraform infrav1.Terraform) bool {
@@ -3,12 +3,15 @@ package controllers
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 	"text/template"
@@ -18,8 +21,17 @@ import (
 	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
 	"github.com/flux-iac/tofu-controller/runner"
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/acl"
 	"github.com/fluxcd/pkg/runtime/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/jsonpath"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -128,7 +140,12 @@ func (r *TerraformReconciler) doHealthChecks(ctx context.Context, terraform infr
 			}
 
 			traceLog.Info("Run HTTP health check and check for an error")
-			if err := r.doHTTPHealthCheck(ctx, hc.Name, parsed, hc.GetTimeout()); err != nil {
+			if hc.HTTP != nil {
+				err = r.doHTTPHealthCheckWithExpectations(ctx, hc, parsed)
+			} else {
+				err = r.doHTTPHealthCheck(ctx, hc.Name, parsed, hc.GetTimeout())
+			}
+			if err != nil {
 				traceLog.Error(err, "Hit an error")
 				msg := fmt.Sprintf("HTTP health check error: %s, url: %s", hc.Name, hc.URL)
 				traceLog.Info("Record an event")
@@ -139,6 +156,62 @@ func (r *TerraformReconciler) doHealthChecks(ctx context.Context, terraform infr
 					err.Error(),
 				), err
 			}
+		case infrav1.HealthCheckTypeDNS:
+			traceLog = traceLog.WithValues("health-check-type", infrav1.HealthCheckTypeDNS)
+			traceLog.Info("Run DNS health check and check for an error")
+			if err := r.doDNSHealthCheck(ctx, hc, outputs); err != nil {
+				traceLog.Error(err, "Hit an error")
+				msg := fmt.Sprintf("DNS health check error: %s, address: %s", hc.Name, hc.Address)
+				traceLog.Info("Record an event")
+				r.event(ctx, terraform, revision, eventv1.EventSeverityError, msg, nil)
+				traceLog.Info("Return failed health check")
+				return infrav1.TerraformHealthCheckFailed(
+					terraform,
+					err.Error(),
+				), err
+			}
+		case infrav1.HealthCheckTypeGRPC:
+			traceLog = traceLog.WithValues("health-check-type", infrav1.HealthCheckTypeGRPC)
+			traceLog.Info("Run gRPC health check and check for an error")
+			if err := r.doGRPCHealthCheck(ctx, hc, outputs); err != nil {
+				traceLog.Error(err, "Hit an error")
+				msg := fmt.Sprintf("gRPC health check error: %s, address: %s", hc.Name, hc.Address)
+				traceLog.Info("Record an event")
+				r.event(ctx, terraform, revision, eventv1.EventSeverityError, msg, nil)
+				traceLog.Info("Return failed health check")
+				return infrav1.TerraformHealthCheckFailed(
+					terraform,
+					err.Error(),
+				), err
+			}
+		case infrav1.HealthCheckTypeTLS:
+			traceLog = traceLog.WithValues("health-check-type", infrav1.HealthCheckTypeTLS)
+			traceLog.Info("Run TLS health check and check for an error")
+			if err := r.doTLSHealthCheck(ctx, hc, outputs); err != nil {
+				traceLog.Error(err, "Hit an error")
+				msg := fmt.Sprintf("TLS health check error: %s, address: %s", hc.Name, hc.Address)
+				traceLog.Info("Record an event")
+				r.event(ctx, terraform, revision, eventv1.EventSeverityError, msg, nil)
+				traceLog.Info("Return failed health check")
+				return infrav1.TerraformHealthCheckFailed(
+					terraform,
+					err.Error(),
+				), err
+			}
+		case infrav1.HealthCheckTypeKubernetes:
+			traceLog = traceLog.WithValues("health-check-type", infrav1.HealthCheckTypeKubernetes)
+			traceLog.Info("Run Kubernetes health check and check for an error")
+			if err := r.doKubernetesHealthCheck(ctx, terraform, hc, outputs); err != nil {
+				traceLog.Error(err, "Hit an error")
+				msg := fmt.Sprintf("Kubernetes health check error: %s", hc.Name)
+				traceLog.Info("Record an event")
+				r.event(ctx, terraform, revision, eventv1.EventSeverityError, msg, nil)
+				traceLog.Info("Return failed health check")
+				return infrav1.TerraformHealthCheckFailed(
+					terraform,
+					err.Error(),
+				), err
+			}
 		}
 	}
 
@@ -217,16 +290,81 @@ func (r *TerraformReconciler) doTCPHealthCheck(ctx context.Context, name string,
 func (r *TerraformReconciler) doHTTPHealthCheck(ctx context.Context, name string, urlString string, timeout time.Duration) error {
 	log := ctrl.LoggerFrom(ctx)
 
+	re, b, err := r.sendHTTPHealthCheckRequest(ctx, name, urlString, timeout)
+	if err != nil {
+		return err
+	}
+
+	// check http status code
+	if re.StatusCode >= http.StatusOK && re.StatusCode < http.StatusBadRequest {
+		log.Info("HTTP health check succeeded for %s on %s, response: %v", name, urlString, *re)
+		return nil
+	}
+
+	err = fmt.Errorf("failed to perform http health check for %s on %s, response body: %v", name, urlString, string(b))
+	log.Error(err, "failed to perform http health check for %s on %s, response body: %v", name, urlString, string(b))
+	return err
+}
+
+// doHTTPHealthCheckWithExpectations repeats the http health check until the expected number of
+// consecutive responses meet the expectations, or the timeout of the health check elapses.
+func (r *TerraformReconciler) doHTTPHealthCheckWithExpectations(ctx context.Context, hc infrav1.HealthCheck, urlString string) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	threshold := hc.HTTP.GetSuccessThreshold()
+	deadline := time.Now().Add(hc.GetTimeout())
+
+	passes := 0
+	var lastErr error
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+
+		re, b, err := r.sendHTTPHealthCheckRequest(ctx, hc.Name, urlString, remaining)
+		if err == nil {
+			err = checkHTTPHealthCheckExpectations(hc.Name, urlString, re, b, *hc.HTTP)
+		}
+
+		if err == nil {
+			passes++
+			log.Info("HTTP health check passed", "name", hc.Name, "url", urlString, "passes", passes, "threshold", threshold)
+			if passes >= threshold {
+				return nil
+			}
+		} else {
+			passes = 0
+			lastErr = err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(hc.HTTP.GetInterval()):
+		}
+	}
+
+	if lastErr == nil {
+		return fmt.Errorf("http health check for %s on %s: only %d of %d consecutive successes within %s", hc.Name, urlString, passes, threshold, hc.GetTimeout())
+	}
+
+	return fmt.Errorf("http health check for %s on %s did not succeed %d consecutive times within %s, last error: %s", hc.Name, urlString, threshold, hc.GetTimeout(), lastErr)
+}
+
+func (r *TerraformReconciler) sendHTTPHealthCheckRequest(ctx context.Context, name string, urlString string, timeout time.Duration) (*http.Response, []byte, error) {
+	log := ctrl.LoggerFrom(ctx)
+
 	// validate url
 	_, err := url.ParseRequestURI(urlString)
 	if err != nil {
-		return fmt.Errorf("invalid url for http health check: %s, %s", urlString, err)
+		return nil, nil, fmt.Errorf("invalid url for http health check: %s, %s", urlString, err)
 	}
 
 	req, err := http.NewRequest("GET", urlString, nil)
 	if err != nil {
 		log.Error(err, "Unexpected error creating HTTP request")
-		return err
+		return nil, nil, err
 	}
 
 	ctxt, cancel := context.WithTimeout(req.Context(), timeout)
@@ -234,7 +372,7 @@ func (r *TerraformReconciler) doHTTPHealthCheck(ctx context.Context, name string
 
 	re, err := http.DefaultClient.Do(req.WithContext(ctxt))
 	if err != nil {
-		return fmt.Errorf("failed to perform http health check for %s on %s: %s", name, urlString, err.Error())
+		return nil, nil, fmt.Errorf("failed to perform http health check for %s on %s: %s", name, urlString, err.Error())
 	}
 	defer func() {
 		if rerr := re.Body.Close(); rerr != nil {
@@ -245,18 +383,304 @@ func (r *TerraformReconciler) doHTTPHealthCheck(ctx context.Context, name string
 	// read http body
 	b, err := io.ReadAll(re.Body)
 	if err != nil {
-		return fmt.Errorf("failed to perform http health check for %s on %s, error reading body: %s", name, urlString, err.Error())
+		return nil, nil, fmt.Errorf("failed to perform http health check for %s on %s, error reading body: %s", name, urlString, err.Error())
 	}
 
-	// check http status code
-	if re.StatusCode >= http.StatusOK && re.StatusCode < http.StatusBadRequest {
-		log.Info("HTTP health check succeeded for %s on %s, response: %v", name, urlString, *re)
-		return nil
+	return re, b, nil
+}
+
+// checkHTTPHealthCheckExpectations returns an error describing the first expectation the response does not meet.
+func checkHTTPHealthCheckExpectations(name string, urlString string, re *http.Response, body []byte, expectations infrav1.HTTPHealthCheck) error {
+	if len(expectations.ExpectedStatusCodes) > 0 {
+		expected := false
+		for _, code := range expectations.ExpectedStatusCodes {
+			if re.StatusCode == code {
+				expected = true
+				break
+			}
+		}
+		if !expected {
+			return fmt.Errorf("http health check for %s on %s: unexpected status code %d, expected one of %v", name, urlString, re.StatusCode, expectations.ExpectedStatusCodes)
+		}
+	} else if re.StatusCode < http.StatusOK || re.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("http health check for %s on %s: unexpected status code %d", name, urlString, re.StatusCode)
 	}
 
-	err = fmt.Errorf("failed to perform http health check for %s on %s, response body: %v", name, urlString, string(b))
-	log.Error(err, "failed to perform http health check for %s on %s, response body: %v", name, urlString, string(b))
-	return err
+	for header, pattern := range expectations.ExpectedHeaders {
+		values := re.Header.Values(header)
+		if len(values) == 0 {
+			return fmt.Errorf("http health check for %s on %s: missing header %s", name, urlString, header)
+		}
+		if pattern == "" {
+			continue
+		}
+		matched, err := regexp.MatchString(pattern, strings.Join(values, ", "))
+		if err != nil {
+			return fmt.Errorf("invalid regular expression for header %s of http health check %s: %s", header, name, err)
+		}
+		if !matched {
+			return fmt.Errorf("http health check for %s on %s: header %s value %q does not match %q", name, urlString, header, strings.Join(values, ", "), pattern)
+		}
+	}
+
+	if expectations.BodyRegex != "" {
+		matched, err := regexp.Match(expectations.BodyRegex, body)
+		if err != nil {
+			return fmt.Errorf("invalid body regular expression for http health check %s: %s", name, err)
+		}
+		if !matched {
+			return fmt.Errorf("http health check for %s on %s: body does not match %q", name, urlString, expectations.BodyRegex)
+		}
+	}
+
+	if expectations.BodyJSONPath != "" {
+		var data interface{}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return fmt.Errorf("http health check for %s on %s: body is not JSON: %s", name, urlString, err)
+		}
+
+		expr := expectations.BodyJSONPath
+		if !strings.HasPrefix(expr, "{") {
+			expr = "{" + expr + "}"
+		}
+		jp := jsonpath.New(name).AllowMissingKeys(true)
+		if err := jp.Parse(expr); err != nil {
+			return fmt.Errorf("invalid body JSONPath for http health check %s: %s", name, err)
+		}
+
+		var result bytes.Buffer
+		if err := jp.Execute(&result, data); err != nil {
+			return fmt.Errorf("http health check for %s on %s: %s", name, urlString, err)
+		}
+
+		if result.Len() == 0 {
+			return fmt.Errorf("http health check for %s on %s: JSONPath %s returned no result", name, urlString, expectations.BodyJSONPath)
+		}
+		if expectations.BodyJSONPathValue != "" && result.String() != expectations.BodyJSONPathValue {
+			return fmt.Errorf("http health check for %s on %s: JSONPath %s returned %q, expected %q", name, urlString, expectations.BodyJSONPath, result.String(), expectations.BodyJSONPathValue)
+		}
+	}
+
+	return nil
+}
+
+func (r *TerraformReconciler) doDNSHealthCheck(ctx context.Context, hc infrav1.HealthCheck, outputs map[string]string) error {
+	host, err := r.parseHealthCheckTemplate(outputs, hc.Address)
+	if err != nil {
+		return err
+	}
+
+	recordType := "A"
+	var expected []string
+	if hc.DNS != nil {
+		if hc.DNS.RecordType != "" {
+			recordType = hc.DNS.RecordType
+		}
+		for _, e := range hc.DNS.ExpectedRecords {
+			parsed, err := r.parseHealthCheckTemplate(outputs, e)
+			if err != nil {
+				return err
+			}
+			expected = append(expected, parsed)
+		}
+	}
+
+	ctxt, cancel := context.WithTimeout(ctx, hc.GetTimeout())
+	defer cancel()
+
+	var records []string
+	switch recordType {
+	case "A", "AAAA":
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctxt, host)
+		if err != nil {
+			return fmt.Errorf("failed to perform dns health check for %s on %s: %s", hc.Name, host, err.Error())
+		}
+		for _, addr := range addrs {
+			if (addr.IP.To4() != nil) == (recordType == "A") {
+				records = append(records, addr.IP.String())
+			}
+		}
+	case "CNAME":
+		cname, err := net.DefaultResolver.LookupCNAME(ctxt, host)
+		if err != nil {
+			return fmt.Errorf("failed to perform dns health check for %s on %s: %s", hc.Name, host, err.Error())
+		}
+		records = append(records, cname)
+	case "TXT":
+		records, err = net.DefaultResolver.LookupTXT(ctxt, host)
+		if err != nil {
+			return fmt.Errorf("failed to perform dns health check for %s on %s: %s", hc.Name, host, err.Error())
+		}
+	default:
+		return fmt.Errorf("unsupported record type for dns health check %s: %s", hc.Name, recordType)
+	}
+
+	if len(records) == 0 {
+		return fmt.Errorf("dns health check for %s on %s: no %s record found", hc.Name, host, recordType)
+	}
+
+	normalize := func(record string) string {
+		return strings.ToLower(strings.TrimSuffix(record, "."))
+	}
+	found := map[string]bool{}
+	for _, record := range records {
+		found[normalize(record)] = true
+	}
+	for _, e := range expected {
+		if !found[normalize(e)] {
+			return fmt.Errorf("dns health check for %s on %s: expected %s record %q not found in %v", hc.Name, host, recordType, e, records)
+		}
+	}
+
+	return nil
+}
+
+func (r *TerraformReconciler) doGRPCHealthCheck(ctx context.Context, hc infrav1.HealthCheck, outputs map[string]string) error {
+	address, err := r.parseHealthCheckTemplate(outputs, hc.Address)
+	if err != nil {
+		return err
+	}
+
+	// validate tcp address
+	if err := validateTCPAddress(address); err != nil {
+		return fmt.Errorf("invalid address for grpc health check: %s, %s", address, err)
+	}
+
+	creds := insecure.NewCredentials()
+	service := ""
+	if hc.GRPC != nil {
+		service = hc.GRPC.Service
+		if hc.GRPC.TLS {
+			creds = credentials.NewTLS(&tls.Config{})
+		}
+	}
+
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return fmt.Errorf("failed to perform grpc health check for %s on %s: %s", hc.Name, address, err.Error())
+	}
+	defer conn.Close()
+
+	ctxt, cancel := context.WithTimeout(ctx, hc.GetTimeout())
+	defer cancel()
+
+	reply, err := healthpb.NewHealthClient(conn).Check(ctxt, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return fmt.Errorf("failed to perform grpc health check for %s on %s: %s", hc.Name, address, err.Error())
+	}
+
+	if reply.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("grpc health check for %s on %s: service %q is %s", hc.Name, address, service, reply.GetStatus())
+	}
+
+	return nil
+}
+
+func (r *TerraformReconciler) doTLSHealthCheck(ctx context.Context, hc infrav1.HealthCheck, outputs map[string]string) error {
+	address, err := r.parseHealthCheckTemplate(outputs, hc.Address)
+	if err != nil {
+		return err
+	}
+
+	// validate tcp address
+	if err := validateTCPAddress(address); err != nil {
+		return fmt.Errorf("invalid address for tls health check: %s, %s", address, err)
+	}
+
+	host, _, _ := net.SplitHostPort(address)
+	tlsConfig := &tls.Config{ServerName: host}
+	var minValidity time.Duration
+	if hc.TLS != nil {
+		if hc.TLS.ServerName != "" {
+			tlsConfig.ServerName = hc.TLS.ServerName
+		}
+		if hc.TLS.MinValidity != nil {
+			minValidity = hc.TLS.MinValidity.Duration
+		}
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: hc.GetTimeout()},
+		Config:    tlsConfig,
+	}
+	ctxt, cancel := context.WithTimeout(ctx, hc.GetTimeout())
+	defer cancel()
+
+	conn, err := dialer.DialContext(ctxt, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to perform tls health check for %s on %s: %s", hc.Name, address, err.Error())
+	}
+	defer conn.Close()
+
+	certificates := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return fmt.Errorf("tls health check for %s on %s: no certificate presented", hc.Name, address)
+	}
+
+	notAfter := certificates[0].NotAfter
+	if remaining := time.Until(notAfter); remaining < minValidity {
+		return fmt.Errorf("tls health check for %s on %s: certificate expires at %s, less than %s from now", hc.Name, address, notAfter.Format(time.RFC3339), minValidity)
+	}
+
+	return nil
+}
+
+func (r *TerraformReconciler) doKubernetesHealthCheck(ctx context.Context, terraform infrav1.Terraform, hc infrav1.HealthCheck, outputs map[string]string) error {
+	if hc.KubernetesObject == nil {
+		return fmt.Errorf("kubernetesObject is required for kubernetes health check %s", hc.Name)
+	}
+
+	name, err := r.parseHealthCheckTemplate(outputs, hc.KubernetesObject.Name)
+	if err != nil {
+		return err
+	}
+
+	namespace, err := r.parseHealthCheckTemplate(outputs, hc.KubernetesObject.Namespace)
+	if err != nil {
+		return err
+	}
+	if namespace == "" {
+		namespace = terraform.Namespace
+	}
+
+	if r.NoCrossNamespaceRefs && namespace != terraform.Namespace {
+		return acl.AccessDeniedError(
+			fmt.Sprintf("cannot access %s %s/%s, cross-namespace references have been disabled", hc.KubernetesObject.Kind, namespace, name),
+		)
+	}
+
+	conditionType := hc.KubernetesObject.ConditionType
+	if conditionType == "" {
+		conditionType = meta.ReadyCondition
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(hc.KubernetesObject.APIVersion)
+	obj.SetKind(hc.KubernetesObject.Kind)
+	objectKey := types.NamespacedName{Namespace: namespace, Name: name}
+	if err := r.Get(ctx, objectKey, obj); err != nil {
+		return fmt.Errorf("failed to perform kubernetes health check for %s on %s %s: %s", hc.Name, hc.KubernetesObject.Kind, objectKey, err.Error())
+	}
+
+	conditions, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil {
+		return fmt.Errorf("failed to perform kubernetes health check for %s on %s %s: %s", hc.Name, hc.KubernetesObject.Kind, objectKey, err.Error())
+	}
+
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+
+		if condition["status"] == string(metav1.ConditionTrue) {
+			return nil
+		}
+
+		return fmt.Errorf("kubernetes health check for %s: %s %s is not %s: %v", hc.Name, hc.KubernetesObject.Kind, objectKey, conditionType, condition["message"])
+	}
+
+	return fmt.Errorf("kubernetes health check for %s: %s %s has no %s condition", hc.Name, hc.KubernetesObject.Kind, objectKey, conditionType)
 }
 
 // parse template string from map[string]string
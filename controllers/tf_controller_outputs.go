@@ -184,6 +184,7 @@ func (r *TerraformReconciler) writeOutput(ctx context.Context, terraform infrav1
 		Annotations: terraform.Spec.WriteOutputsToSecret.Annotations,
 	})
 	if err != nil {
+		r.MetricsRecorder.RecordOutputWriteFailure(terraform)
 		return infrav1.TerraformNotReady(
 			terraform,
 			revision,
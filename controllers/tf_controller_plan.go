@@ -3,11 +3,13 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/flux-iac/tofu-controller/api/planid"
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 
 	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/internal/metrics"
 	"github.com/flux-iac/tofu-controller/runner"
 	"google.golang.org/grpc/status"
 	"k8s.io/apimachinery/pkg/types"
@@ -22,6 +24,9 @@ func (r *TerraformReconciler) shouldPlan(terraform infrav1.Terraform) bool {
 
 	if terraform.Status.Plan.Pending == "" {
 		return true
+	} else if r.forceOrAutoApply(terraform) && isApplyHeld(terraform) {
+		// plan again while the apply is held, so that the plan applied when the hold is lifted is not stale
+		return true
 	} else if terraform.Status.Plan.Pending != "" {
 		return false
 	}
@@ -47,7 +52,7 @@ func (r *TerraformReconciler) plan(ctx context.Context, terraform infrav1.Terraf
 		TfInstance:       tfInstance,
 		Out:              tfplanFilename,
 		Refresh:          true, // be careful, refresh requires to be true by default
-		Targets:          terraform.Spec.Targets,
+		Targets:          driftCorrectionTargets(terraform, revision),
 		SourceRefRootDir: sourceRefRootDir,
 	}
 
@@ -57,8 +62,8 @@ func (r *TerraformReconciler) plan(ctx context.Context, terraform infrav1.Terraf
 	}
 
 	// check if destroy is set to true or
-	// the object is being deleted and DestroyResourcesOnDeletion is set to true
-	if terraform.Spec.Destroy || (!terraform.ObjectMeta.DeletionTimestamp.IsZero() && terraform.Spec.DestroyResourcesOnDeletion) {
+	// the object is being deleted and DestroyResourcesOnDeletion is set to true (or a cascade destroy is requested)
+	if terraform.Spec.Destroy || (!terraform.ObjectMeta.DeletionTimestamp.IsZero() && shouldDestroyResourcesOnDeletion(terraform)) {
 		log.Info("plan to destroy")
 		planRequest.Destroy = true
 	}
@@ -70,7 +75,9 @@ func (r *TerraformReconciler) plan(ctx context.Context, terraform infrav1.Terraf
 		}
 	}
 
+	planStart := time.Now()
 	planReply, err := runnerClient.Plan(ctx, planRequest)
+	r.MetricsRecorder.RecordStageDuration(terraform, metrics.StagePlan, planStart)
 	if err != nil {
 
 		eventSent := false
@@ -100,6 +107,9 @@ func (r *TerraformReconciler) plan(ctx context.Context, terraform infrav1.Terraf
 
 	drifted := planReply.Drifted
 	log.Info(fmt.Sprintf("plan: %s, found drift: %v", planReply.Message, drifted))
+	if planReply.PlanCreated || !drifted {
+		r.MetricsRecorder.RecordResourceChanges(terraform, int(planReply.ToAdd), int(planReply.ToChange), int(planReply.ToDestroy))
+	}
 
 	// currently the PlanCreated flag is only used here to determine if the destroy plan is empty
 	if planRequest.Destroy && planReply.PlanCreated == false {
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+// isPlanExpired returns true if the pending plan waited for its approval longer than the plan TTL.
+// The plans approved, and waiting only for the schedule or the approval quorum to be applied, do not expire.
+func (r *TerraformReconciler) isPlanExpired(terraform infrav1.Terraform, now time.Time) bool {
+	expiresAt := terraform.PlanExpiresAt()
+	if expiresAt.IsZero() || now.Before(expiresAt) {
+		return false
+	}
+
+	approval := terraform.Status.Approval
+	awaitingApprovals := approval != nil && approval.PlanID == terraform.Status.Plan.Pending && !approval.Approved()
+	return !r.shouldApply(terraform) || awaitingApprovals
+}
+
+// expirePlan discards the expired plan, so that it is planned again against the current infrastructure.
+func (r *TerraformReconciler) expirePlan(ctx context.Context, terraform infrav1.Terraform, revision string) (infrav1.Terraform, error) {
+	planName := "tfplan-" + terraform.WorkspaceName() + "-" + terraform.Name
+	for _, obj := range []client.Object{
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: terraform.Namespace, Name: planName}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: terraform.Namespace, Name: planName + ".json"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: terraform.Namespace, Name: planName}},
+	} {
+		if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return terraform, fmt.Errorf("unable to discard the expired plan %s: %w", planName, err)
+		}
+	}
+
+	msg := fmt.Sprintf("Plan %s expired after %s without approval, planning again",
+		terraform.Status.Plan.Pending, terraform.Spec.PlanTTL.Duration)
+	terraform = infrav1.TerraformPlanExpired(terraform, revision, msg)
+	r.event(ctx, terraform, revision, eventv1.EventSeverityInfo, msg, nil)
+	return terraform, nil
+}
+
+// approvesPlan returns true if spec.approvePlan approves the plan, by id or by prefix.
+func approvesPlan(approvePlan, planID string) bool {
+	if approvePlan == "" || approvePlan == infrav1.ApprovePlanAutoValue || planID == "" {
+		return false
+	}
+	return strings.HasPrefix(planID, approvePlan)
+}
+
+// expiredPlanApproval returns a message rejecting spec.approvePlan if it approves the expired plan,
+// and not the pending plan.
+func expiredPlanApproval(terraform infrav1.Terraform) string {
+	expired := terraform.Status.ExpiredPlan
+	if expired == nil || !approvesPlan(terraform.Spec.ApprovePlan, expired.PlanID) ||
+		approvesPlan(terraform.Spec.ApprovePlan, terraform.Status.Plan.Pending) {
+		return ""
+	}
+
+	msg := fmt.Sprintf("approvePlan %q rejected, plan %s expired at %s",
+		terraform.Spec.ApprovePlan, expired.PlanID, expired.ExpiredAt.UTC().Format(time.RFC3339))
+	if terraform.Status.Plan.Pending != "" {
+		msg += fmt.Sprintf(", approve the pending plan %s instead", terraform.Status.Plan.Pending)
+	}
+	return msg
+}
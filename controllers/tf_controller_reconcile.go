@@ -18,14 +18,18 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/internal/tracing"
 	"github.com/flux-iac/tofu-controller/runner"
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 	"github.com/fluxcd/pkg/apis/meta"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -42,9 +46,12 @@ func (r *TerraformReconciler) reconcile(ctx context.Context, runnerClient runner
 		err        error
 
 		lastKnownAction string
+		applied         bool
 	)
 	log.Info("setting up terraform")
-	terraform, tfInstance, tmpDir, err = r.setupTerraform(ctx, runnerClient, terraform, sourceObj, revision, objectKey, reconciliationLoopID)
+	setupCtx, span := tracing.Start(ctx, "setup", terraform.Namespace, terraform.Name)
+	terraform, tfInstance, tmpDir, err = r.setupTerraform(setupCtx, runnerClient, terraform, sourceObj, revision, objectKey, reconciliationLoopID)
+	tracing.End(span, err)
 
 	lastKnownAction = "Setup"
 
@@ -134,9 +141,24 @@ func (r *TerraformReconciler) reconcile(ctx context.Context, runnerClient runner
 		}
 	}
 
+	if terraform.GetAnnotations()[infrav1.StateOperationAnnotation] != "" {
+		terraform = r.handleStateOperation(ctx, runnerClient, terraform, tfInstance, revision)
+		if err := r.patchStatus(ctx, objectKey, terraform.Status); err != nil {
+			log.Error(err, "unable to update status after the state operation")
+			return &terraform, err
+		}
+	}
+
 	if r.shouldDetectDrift(terraform, revision) {
 		var driftDetectionErr error // declared here to avoid shadowing on terraform variable
-		terraform, driftDetectionErr = r.detectDrift(ctx, terraform, tfInstance, runnerClient, revision, tmpDir)
+		terraform, driftDetectionErr = r.detectDriftWhenDue(ctx, terraform, tfInstance, runnerClient, revision, tmpDir, time.Now())
+
+		// skip until drift detection is due, and a drift detection slot is free
+		var deferred *driftDetectionDeferredError
+		if errors.As(driftDetectionErr, &deferred) {
+			log.Info("drift detection deferred", "reason", deferred.Error(), "requeueAfter", deferred.requeueAfter.String())
+			return &terraform, deferred
+		}
 
 		// immediately return if no drift - reconciliation will retry normally
 		if driftDetectionErr == nil {
@@ -167,6 +189,12 @@ func (r *TerraformReconciler) reconcile(ctx context.Context, runnerClient runner
 			return &terraform, driftDetectionErr
 		}
 
+		// immediately return if the drift policy only alerts on the detected drift
+		if !shouldCorrectDrift(terraform) {
+			log.Error(driftDetectionErr, "drift policy does not correct the detected drift")
+			return &terraform, driftDetectionErr
+		}
+
 		// ok Drift Detected, patch and continue
 		if err := r.patchStatus(ctx, objectKey, terraform.Status); err != nil {
 			log.Error(err, "unable to update status after drift detection")
@@ -184,7 +212,9 @@ func (r *TerraformReconciler) reconcile(ctx context.Context, runnerClient runner
 
 	// if we should plan this Terraform CR, do so
 	if r.shouldPlan(terraform) {
-		terraform, err = r.plan(ctx, terraform, tfInstance, runnerClient, revision, tmpDir)
+		planCtx, span := tracing.Start(ctx, "plan", terraform.Namespace, terraform.Name)
+		terraform, err = r.plan(planCtx, terraform, tfInstance, runnerClient, revision, tmpDir)
+		tracing.End(span, err)
 		if err != nil {
 			log.Error(err, "error planning")
 			return &terraform, err
@@ -200,7 +230,47 @@ func (r *TerraformReconciler) reconcile(ctx context.Context, runnerClient runner
 
 	// if we should apply the generated plan, do so
 	if r.shouldApply(terraform) {
-		terraform, err = r.apply(ctx, terraform, tfInstance, runnerClient, revision)
+		var approved bool
+		terraform, approved, err = r.reconcileApprovals(ctx, terraform, revision)
+		if err != nil {
+			log.Error(err, "error checking the plan approvals")
+			return &terraform, err
+		}
+		if !approved {
+			if err := r.patchStatus(ctx, objectKey, terraform.Status); err != nil {
+				log.Error(err, "unable to update status after checking the plan approvals")
+				return &terraform, err
+			}
+			log.Info("apply awaiting plan approvals", "plan", terraform.Status.Plan.Pending)
+			return &terraform, nil
+		}
+
+		hold, holdErr := r.checkApplyHold(ctx, terraform, time.Now())
+		if holdErr != nil {
+			log.Error(holdErr, "error checking the schedule")
+			terraform = infrav1.TerraformNotReady(terraform, revision, infrav1.ScheduleInvalidReason, holdErr.Error())
+			return &terraform, holdErr
+		}
+		if hold != nil {
+			held := apimeta.FindStatusCondition(terraform.Status.Conditions, infrav1.ConditionTypeSchedule)
+			terraform = infrav1.TerraformApplyHeld(terraform, revision, hold.reason, hold.message)
+			if err := r.patchStatus(ctx, objectKey, terraform.Status); err != nil {
+				log.Error(err, "unable to update status after holding the apply")
+				return &terraform, err
+			}
+			if held == nil || held.Status != metav1.ConditionFalse || held.Reason != hold.reason {
+				r.event(ctx, terraform, revision, eventv1.EventSeverityInfo, hold.message, nil)
+			}
+			log.Info("apply held", "reason", hold.reason)
+			return &terraform, nil
+		}
+		if terraform.Spec.Schedule != nil || r.FreezeConfigMap.Name != "" {
+			terraform = infrav1.TerraformApplyAllowed(terraform, "Plan can be applied")
+		}
+
+		applyCtx, span := tracing.Start(ctx, "apply", terraform.Namespace, terraform.Name)
+		terraform, err = r.apply(applyCtx, terraform, tfInstance, runnerClient, revision)
+		tracing.End(span, err)
 		if err != nil {
 			log.Error(err, "error applying")
 			return &terraform, err
@@ -212,20 +282,38 @@ func (r *TerraformReconciler) reconcile(ctx context.Context, runnerClient runner
 		}
 
 		lastKnownAction = "Applied"
+		applied = true
 	} else {
 		log.Info("should apply == false")
+		if isApplyHeld(terraform) {
+			apimeta.RemoveStatusCondition(&terraform.Status.Conditions, infrav1.ConditionTypeSchedule)
+		}
 	}
 
-	terraform, err = r.processOutputs(ctx, runnerClient, terraform, tfInstance, revision)
+	outputsCtx, span := tracing.Start(ctx, "outputs", terraform.Namespace, terraform.Name)
+	terraform, err = r.processOutputs(outputsCtx, runnerClient, terraform, tfInstance, revision)
+	tracing.End(span, err)
 	if err != nil {
 		log.Error(err, "error process outputs")
 		return &terraform, err
 	}
 	lastKnownAction = "Outputs Processed"
 
+	// the post-apply webhooks receive the outputs once they are written
+	if applied && shouldProcessWebhooks(terraform, infrav1.PostApplyWebhook) {
+		log.Info("calling post-apply webhooks")
+		terraform = r.processPostApplyWebhooks(ctx, terraform, runnerClient, revision, tfInstance)
+		if err := r.patchStatus(ctx, objectKey, terraform.Status); err != nil {
+			log.Error(err, "unable to update status after calling the post-apply webhooks")
+			return &terraform, err
+		}
+	}
+
 	if r.shouldDoHealthChecks(terraform) {
 
-		terraform, err = r.doHealthChecks(ctx, terraform, revision, runnerClient)
+		healthCheckCtx, span := tracing.Start(ctx, "health-checks", terraform.Namespace, terraform.Name)
+		terraform, err = r.doHealthChecks(healthCheckCtx, terraform, revision, runnerClient)
+		tracing.End(span, err)
 		if err != nil {
 			log.Error(err, "error with health check")
 			return &terraform, err
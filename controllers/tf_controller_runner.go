@@ -21,6 +21,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/internal/tracing"
 	"github.com/flux-iac/tofu-controller/mtls"
 	"github.com/flux-iac/tofu-controller/runner"
 )
@@ -157,6 +158,7 @@ func (r *TerraformReconciler) getRunnerConnection(ctx context.Context, tlsSecret
 		grpc.WithTransportCredentials(credentials),
 		grpc.WithBlock(),
 		grpc.WithDefaultServiceConfig(retryPolicy),
+		grpc.WithUnaryInterceptor(tracing.UnaryClientInterceptor()),
 	)
 }
 
@@ -301,6 +303,13 @@ func (r *TerraformReconciler) runnerPodSpec(terraform infrav1.Terraform, tlsSecr
 		podSpec.Subdomain = "tf-runner"
 	}
 
+	if r.Tracing.Endpoint != "" {
+		podSpec.Containers[0].Args = append(podSpec.Containers[0].Args, "--otlp-endpoint", r.Tracing.Endpoint)
+		if r.Tracing.Insecure {
+			podSpec.Containers[0].Args = append(podSpec.Containers[0].Args, "--otlp-insecure")
+		}
+	}
+
 	return podSpec
 }
 
@@ -325,6 +334,7 @@ func (r *TerraformReconciler) reconcileRunnerPod(ctx context.Context, terraform
 	traceLog.Info("Set tlsSecretName", "tlsSecretName", tlsSecretName)
 
 	traceLog.Info("Setup create new pod function")
+	var createdAt time.Time
 	createNewPod := func() error {
 		runnerPodTemplate, err := runnerPodTemplate(terraform, tlsSecretName, revision)
 		if err != nil {
@@ -336,6 +346,7 @@ func (r *TerraformReconciler) reconcileRunnerPod(ctx context.Context, terraform
 		if err := r.Create(ctx, &newRunnerPod); err != nil {
 			return err
 		}
+		createdAt = time.Now()
 		return nil
 	}
 
@@ -487,6 +498,9 @@ func (r *TerraformReconciler) reconcileRunnerPod(ctx context.Context, terraform
 				traceLog.Info("Check if the pod has an IP")
 				if runnerPod.Status.PodIP != "" {
 					traceLog.Info("Success, pod has an IP")
+					if !createdAt.IsZero() {
+						r.MetricsRecorder.RecordRunnerCreation(createdAt)
+					}
 					return runnerPod.Status.PodIP, nil
 				}
 
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+	_ "time/tzdata"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/robfig/cron/v3"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+// cronParser parses the five field cron expressions of the maintenance windows.
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// applyHold is the reason why a plan must not be applied now.
+type applyHold struct {
+	reason  string
+	message string
+	// until is when the hold is expected to be lifted, zero if unknown.
+	until time.Time
+}
+
+// requeueAfter returns when to reconcile again to apply the held plan,
+// no later than the interval of the object.
+func (h applyHold) requeueAfter(now time.Time, interval time.Duration) time.Duration {
+	if h.until.IsZero() {
+		return interval
+	}
+	if d := h.until.Sub(now); d < interval {
+		if d < time.Second {
+			return time.Second
+		}
+		return d
+	}
+	return interval
+}
+
+// checkApplyHold returns the hold of the pending plan, or nil if it can be applied now.
+// A change freeze holds every apply, while the schedule of the object only holds the automatic ones.
+func (r *TerraformReconciler) checkApplyHold(ctx context.Context, terraform infrav1.Terraform, now time.Time) (*applyHold, error) {
+	hold, err := r.checkChangeFreeze(ctx, now)
+	if err != nil || hold != nil {
+		return hold, err
+	}
+
+	if terraform.Spec.Schedule == nil || !r.forceOrAutoApply(terraform) {
+		return nil, nil
+	}
+	return checkSchedule(*terraform.Spec.Schedule, now)
+}
+
+// checkChangeFreeze holds all applies across namespaces while the freeze ConfigMap exists,
+// until the optional end of the freeze.
+func (r *TerraformReconciler) checkChangeFreeze(ctx context.Context, now time.Time) (*applyHold, error) {
+	if r.FreezeConfigMap.Name == "" {
+		return nil, nil
+	}
+
+	cm := corev1.ConfigMap{}
+	if err := r.Get(ctx, r.FreezeConfigMap, &cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to get the change freeze ConfigMap %s: %w", r.FreezeConfigMap, err)
+	}
+
+	hold := &applyHold{
+		reason:  infrav1.ChangeFreezeReason,
+		message: "Plan generated, apply held by a change freeze",
+	}
+	if value, ok := cm.Data[infrav1.ChangeFreezeUntilKey]; ok && value != "" {
+		until, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in the change freeze ConfigMap %s: %w", infrav1.ChangeFreezeUntilKey, r.FreezeConfigMap, err)
+		}
+		if !now.Before(until) {
+			return nil, nil
+		}
+		hold.until = until
+		hold.message = fmt.Sprintf("%s until %s", hold.message, until.Format(time.RFC3339))
+	}
+	if reason := cm.Data[infrav1.ChangeFreezeReasonKey]; reason != "" {
+		hold.message = fmt.Sprintf("%s: %s", hold.message, reason)
+	}
+	return hold, nil
+}
+
+// checkSchedule holds the plan during a blackout period, or outside the maintenance windows.
+func checkSchedule(spec infrav1.ScheduleSpec, now time.Time) (*applyHold, error) {
+	for _, b := range spec.Blackouts {
+		if !now.Before(b.Start.Time) && now.Before(b.End.Time) {
+			msg := fmt.Sprintf("Plan generated, apply held by a blackout period until %s", b.End.Time.Format(time.RFC3339))
+			if b.Reason != "" {
+				msg = fmt.Sprintf("%s: %s", msg, b.Reason)
+			}
+			return &applyHold{reason: infrav1.BlackoutPeriodReason, message: msg, until: b.End.Time}, nil
+		}
+	}
+
+	if len(spec.Windows) == 0 {
+		return nil, nil
+	}
+
+	loc := time.UTC
+	if spec.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(spec.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid schedule time zone %q: %w", spec.TimeZone, err)
+		}
+	}
+	now = now.In(loc)
+
+	var next time.Time
+	for _, w := range spec.Windows {
+		cron, err := cronParser.Parse(w.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", w.Cron, err)
+		}
+
+		// the window is open if it opened less than its duration ago
+		if start := cron.Next(now.Add(-w.Duration.Duration)); !start.IsZero() && !start.After(now) {
+			return nil, nil
+		}
+
+		if opens := cron.Next(now); !opens.IsZero() && (next.IsZero() || opens.Before(next)) {
+			next = opens
+		}
+	}
+
+	msg := "Plan generated, apply held outside the maintenance windows"
+	if !next.IsZero() {
+		msg = fmt.Sprintf("%s, the next window opens at %s", msg, next.Format(time.RFC3339))
+	}
+	return &applyHold{reason: infrav1.OutsideMaintenanceWindowReason, message: msg, until: next}, nil
+}
+
+// isApplyHeld returns true if the pending plan was held by the schedule or a change freeze.
+func isApplyHeld(terraform infrav1.Terraform) bool {
+	c := apimeta.FindStatusCondition(terraform.Status.Conditions, infrav1.ConditionTypeSchedule)
+	return c != nil && c.Status == metav1.ConditionFalse
+}
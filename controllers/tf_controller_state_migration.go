@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/runtime/logger"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/runner"
+)
+
+// backendChanged returns true when the backend of the spec differs from the backend
+// the state was last initialized with.
+func backendChanged(terraform infrav1.Terraform) bool {
+	if terraform.Status.Backend == nil {
+		return false
+	}
+	return !apiequality.Semantic.DeepEqual(terraform.Status.Backend.Config, terraform.Spec.BackendConfig)
+}
+
+// migrateState migrates the state from the backend recorded in the status to the backend of the spec,
+// when requested with the migrate-state annotation. A backend change without a migration request, or
+// after a failed migration, blocks the reconciliation until a migration is requested, the migration is
+// explicitly skipped, or the backend is reverted, so that Terraform never plans against a backend that
+// does not hold the state.
+func (r *TerraformReconciler) migrateState(ctx context.Context, runnerClient runner.RunnerClient, terraform infrav1.Terraform, tfInstance string, workingDir string, revision string, objectKey types.NamespacedName) (infrav1.Terraform, error) {
+	log := ctrl.LoggerFrom(ctx)
+	traceLog := log.V(logger.TraceLevel).WithValues("function", "TerraformReconciler.migrateState")
+
+	if !terraform.IsMigrateStateRequested() {
+		traceLog.Info("No state migration requested")
+		if !backendChanged(terraform) {
+			return terraform, nil
+		}
+
+		if terraform.IsSkipStateMigrationRequested() {
+			requestedAt := terraform.GetAnnotations()[infrav1.SkipStateMigrationAnnotation]
+			msg := "State migration skipped, the new backend is used without the state of the previous backend"
+			log.Info(msg)
+
+			terraform = infrav1.TerraformStateMigrationSkipped(terraform, requestedAt, msg)
+			terraform.Status.Backend = &infrav1.BackendStatus{Config: terraform.Spec.BackendConfig.DeepCopy()}
+			if err := r.patchStatus(ctx, objectKey, terraform.Status); err != nil {
+				log.Error(err, "unable to update status after skipping the state migration")
+				return terraform, err
+			}
+			r.event(ctx, terraform, revision, eventv1.EventSeverityInfo, msg, nil)
+			return terraform, nil
+		}
+
+		c := apimeta.FindStatusCondition(terraform.Status.Conditions, infrav1.ConditionTypeStateMigration)
+		if c != nil && c.Reason == infrav1.StateMigrationFailedReason {
+			err := fmt.Errorf("the last state migration failed, update the %s annotation to retry: %s", infrav1.MigrateStateAnnotation, c.Message)
+			return infrav1.TerraformNotReady(
+				terraform,
+				revision,
+				infrav1.StateMigrationFailedReason,
+				err.Error(),
+			), err
+		}
+
+		err := fmt.Errorf("the backend changed, set the %s annotation to migrate the state to the new backend, or the %s annotation to use the new backend without the state",
+			infrav1.MigrateStateAnnotation, infrav1.SkipStateMigrationAnnotation)
+		terraform = infrav1.TerraformStateMigrationRequired(terraform, err.Error())
+		return infrav1.TerraformNotReady(
+			terraform,
+			revision,
+			infrav1.StateMigrationRequiredReason,
+			err.Error(),
+		), err
+	}
+
+	requestedAt := terraform.GetAnnotations()[infrav1.MigrateStateAnnotation]
+
+	reply, err := r.doMigrateState(ctx, runnerClient, terraform, tfInstance, workingDir)
+	if err != nil {
+		err = fmt.Errorf("error migrating state: %w", err)
+		log.Error(err, "state migration failed")
+		terraform = infrav1.TerraformStateMigrationFailed(terraform, requestedAt, err.Error())
+		if err := r.patchStatus(ctx, objectKey, terraform.Status); err != nil {
+			log.Error(err, "unable to update status after state migration")
+			return terraform, err
+		}
+		r.event(ctx, terraform, revision, eventv1.EventSeverityError, err.Error(), nil)
+		return infrav1.TerraformNotReady(
+			terraform,
+			revision,
+			infrav1.StateMigrationFailedReason,
+			err.Error(),
+		), err
+	}
+
+	msg := reply.Message
+	if reply.BackupSecretName != "" {
+		msg = fmt.Sprintf("%s, the old state is backed up in Secret %s", msg, reply.BackupSecretName)
+	}
+	log.Info(msg)
+
+	terraform = infrav1.TerraformStateMigrationSucceeded(terraform, requestedAt, msg)
+	terraform.Status.Backend = &infrav1.BackendStatus{Config: terraform.Spec.BackendConfig.DeepCopy()}
+	if err := r.patchStatus(ctx, objectKey, terraform.Status); err != nil {
+		log.Error(err, "unable to update status after state migration")
+		return terraform, err
+	}
+	r.event(ctx, terraform, revision, eventv1.EventSeverityInfo, msg, nil)
+
+	return terraform, nil
+}
+
+func (r *TerraformReconciler) doMigrateState(ctx context.Context, runnerClient runner.RunnerClient, terraform infrav1.Terraform, tfInstance string, workingDir string) (*runner.MigrateStateReply, error) {
+	if r.backendCompletelyDisable(terraform) || terraform.Spec.Cloud != nil {
+		return nil, fmt.Errorf("state migration is not supported when the backend is disabled or Terraform Cloud is used")
+	}
+	if terraform.Status.Backend == nil {
+		return nil, fmt.Errorf("no previous backend recorded in the status")
+	}
+	if !backendChanged(terraform) {
+		return &runner.MigrateStateReply{Message: "backend unchanged, nothing to migrate"}, nil
+	}
+
+	oldTerraform := *terraform.DeepCopy()
+	oldTerraform.Spec.BackendConfig = terraform.Status.Backend.Config.DeepCopy()
+	oldBackendConfig, err := r.getBackendConfig(ctx, oldTerraform)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering the previous backend config: %w", err)
+	}
+
+	newBackendConfig, err := r.getBackendConfig(ctx, terraform)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering backend config: %w", err)
+	}
+
+	return runnerClient.MigrateState(ctx, &runner.MigrateStateRequest{
+		TfInstance:       tfInstance,
+		DirPath:          workingDir,
+		OldBackendConfig: []byte(oldBackendConfig),
+		NewBackendConfig: []byte(newBackendConfig),
+	})
+}
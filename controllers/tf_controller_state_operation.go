@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/runtime/logger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/runner"
+)
+
+// handleStateOperation executes the state operation requested with the state operation annotation,
+// records its result in the status, and emits an event recording who did what. The user is taken from
+// the requested-by annotation, which the admission policy verifies, never from the request itself.
+// A failed operation does not fail the reconciliation.
+func (r *TerraformReconciler) handleStateOperation(ctx context.Context, runnerClient runner.RunnerClient, terraform infrav1.Terraform, tfInstance string, revision string) infrav1.Terraform {
+	log := ctrl.LoggerFrom(ctx)
+	traceLog := log.V(logger.TraceLevel).WithValues("function", "TerraformReconciler.handleStateOperation")
+
+	req, err := terraform.GetStateOperationRequest()
+	if err != nil {
+		// the rejected request is recorded, so that it is reported once
+		log.Error(err, "invalid state operation request")
+		terraform.Status.StateOperation = &infrav1.StateOperationStatus{
+			Succeeded:       false,
+			Message:         err.Error(),
+			HandledAt:       &metav1.Time{Time: time.Now()},
+			RejectedRequest: terraform.GetAnnotations()[infrav1.StateOperationAnnotation],
+		}
+		r.event(ctx, terraform, revision, eventv1.EventSeverityError, err.Error(), nil)
+		return terraform
+	}
+	if req == nil {
+		traceLog.Info("No state operation requested")
+		return terraform
+	}
+
+	user := terraform.GetAnnotations()[infrav1.StateOperationRequestedByAnnotation]
+	requestedBy := user
+	if requestedBy == "" {
+		requestedBy = "unknown user"
+	}
+
+	result := &infrav1.StateOperationStatus{
+		ID:          req.ID,
+		Operation:   req.Operation,
+		Args:        req.Args,
+		RequestedBy: user,
+	}
+
+	message, output, err := r.doStateOperation(ctx, runnerClient, tfInstance, *req, user)
+	if err == nil && output != "" {
+		result.OutputSecretName = infrav1.StateOperationOutputSecretName(terraform.Name)
+		_, err = runnerClient.WriteOutputs(ctx, &runner.WriteOutputsRequest{
+			Namespace:  terraform.Namespace,
+			Name:       terraform.Name,
+			SecretName: result.OutputSecretName,
+			Uuid:       string(terraform.UID),
+			Data:       map[string][]byte{infrav1.StateOperationOutputDataKey: []byte(output)},
+		})
+		if err != nil {
+			err = fmt.Errorf("unable to write the output of the state operation: %w", err)
+		}
+	}
+
+	metadata := map[string]string{
+		infrav1.GroupVersion.Group + "/requested-by":    requestedBy,
+		infrav1.GroupVersion.Group + "/state-operation": req.Operation,
+	}
+	severity := eventv1.EventSeverityInfo
+	if err != nil {
+		result.Succeeded = false
+		result.Message = err.Error()
+		severity = eventv1.EventSeverityError
+		log.Error(err, "state operation failed", "operation", req.String(), "requested-by", requestedBy)
+	} else {
+		result.Succeeded = true
+		result.Message = message
+		log.Info("state operation succeeded", "operation", req.String(), "requested-by", requestedBy)
+	}
+	result.HandledAt = &metav1.Time{Time: time.Now()}
+
+	terraform.Status.StateOperation = result
+	r.event(ctx, terraform, revision, severity, fmt.Sprintf("%s ran terraform %s: %s", requestedBy, req.String(), result.Message), metadata)
+
+	return terraform
+}
+
+// doStateOperation calls the runner RPC of the requested operation, and returns
+// a message describing the result, together with the output of read operations.
+func (r *TerraformReconciler) doStateOperation(ctx context.Context, runnerClient runner.RunnerClient, tfInstance string, req infrav1.StateOperationRequest, requestedBy string) (string, string, error) {
+	if err := req.Validate(); err != nil {
+		return "", "", err
+	}
+
+	switch req.Operation {
+	case infrav1.StateOperationList:
+		reply, err := runnerClient.StateList(ctx, &runner.StateListRequest{
+			TfInstance:  tfInstance,
+			Addresses:   req.Args,
+			RequestedBy: requestedBy,
+		})
+		if err != nil {
+			return "", "", err
+		}
+		return reply.Message, reply.Output, nil
+	case infrav1.StateOperationShow:
+		reply, err := runnerClient.StateShow(ctx, &runner.StateShowRequest{
+			TfInstance:  tfInstance,
+			Address:     req.Args[0],
+			RequestedBy: requestedBy,
+		})
+		if err != nil {
+			return "", "", err
+		}
+		return reply.Message, reply.Output, nil
+	case infrav1.StateOperationMv:
+		reply, err := runnerClient.StateMv(ctx, &runner.StateMvRequest{
+			TfInstance:  tfInstance,
+			Source:      req.Args[0],
+			Destination: req.Args[1],
+			RequestedBy: requestedBy,
+		})
+		if err != nil {
+			return "", "", err
+		}
+		return reply.Message, "", nil
+	case infrav1.StateOperationRm:
+		reply, err := runnerClient.StateRm(ctx, &runner.StateRmRequest{
+			TfInstance:  tfInstance,
+			Addresses:   req.Args,
+			RequestedBy: requestedBy,
+		})
+		if err != nil {
+			return "", "", err
+		}
+		return reply.Message, "", nil
+	case infrav1.StateOperationImport:
+		reply, err := runnerClient.Import(ctx, &runner.ImportRequest{
+			TfInstance:  tfInstance,
+			Address:     req.Args[0],
+			Id:          req.Args[1],
+			RequestedBy: requestedBy,
+		})
+		if err != nil {
+			return "", "", err
+		}
+		return reply.Message, "", nil
+	}
+
+	return "", "", fmt.Errorf("unknown state operation %q", req.Operation)
+}
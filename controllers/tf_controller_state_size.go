@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/runtime/logger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+// kubernetesBackendStateKey returns the key of the state Secret of the kubernetes backend,
+// or false when the state is stored elsewhere.
+func (r *TerraformReconciler) kubernetesBackendStateKey(terraform infrav1.Terraform) (types.NamespacedName, bool) {
+	if r.backendCompletelyDisable(terraform) || terraform.Spec.Cloud != nil {
+		return types.NamespacedName{}, false
+	}
+
+	suffix := terraform.Name
+	if spec := terraform.Spec.BackendConfig; spec != nil {
+		if spec.Sharded || spec.CustomConfiguration != "" || spec.S3 != nil || spec.GCS != nil || spec.AzureRM != nil || spec.PG != nil {
+			return types.NamespacedName{}, false
+		}
+		if spec.SecretSuffix != "" {
+			suffix = spec.SecretSuffix
+		}
+	} else if os.Getenv("DISABLE_TF_K8S_BACKEND") == "1" {
+		return types.NamespacedName{}, false
+	}
+
+	return types.NamespacedName{
+		Namespace: terraform.Namespace,
+		Name:      fmt.Sprintf("tfstate-%s-%s", terraform.WorkspaceName(), suffix),
+	}, true
+}
+
+// checkStateSize measures the state Secret of the kubernetes backend after an apply, and reports
+// when the gzipped state gets near the size limit of a Secret, before the backend fails to write it.
+func (r *TerraformReconciler) checkStateSize(ctx context.Context, terraform infrav1.Terraform, revision string) infrav1.Terraform {
+	log := ctrl.LoggerFrom(ctx)
+	traceLog := log.V(logger.TraceLevel).WithValues("function", "TerraformReconciler.checkStateSize")
+
+	key, ok := r.kubernetesBackendStateKey(terraform)
+	if !ok {
+		traceLog.Info("State not stored by the kubernetes backend")
+		return terraform
+	}
+
+	secret := corev1.Secret{}
+	if err := r.Get(ctx, key, &secret); err != nil {
+		log.Info("unable to get the state secret to check its size", "secret", key, "error", err.Error())
+		return terraform
+	}
+
+	size := 0
+	for _, value := range secret.Data {
+		size += len(value)
+	}
+	percent := size * 100 / infrav1.MaxStateSecretSize
+	msg := fmt.Sprintf("State secret %s is %s, %d%% of the %s size limit of a Secret",
+		key.Name, formatStateSize(size), percent, formatStateSize(infrav1.MaxStateSecretSize))
+
+	if percent < terraform.Spec.TFState.GetSizeWarningPercent() {
+		return infrav1.TerraformStateSize(terraform, metav1.ConditionTrue, infrav1.StateSizeWithinLimitReason, msg)
+	}
+
+	msg = msg + ", consider splitting the configuration or setting spec.backendConfig.sharded"
+	log.Info(msg)
+	r.event(ctx, terraform, revision, eventv1.EventSeverityError, msg, nil)
+	return infrav1.TerraformStateSize(terraform, metav1.ConditionFalse, infrav1.StateSizeNearLimitReason, msg)
+}
+
+func formatStateSize(size int) string {
+	return strconv.FormatFloat(float64(size)/1024, 'f', 1, 64) + " KiB"
+}
+
+// explainStateSizeError adds a hint to the opaque error returned by the API server
+// when the state no longer fits in a Secret.
+func explainStateSizeError(err error) error {
+	if strings.Contains(err.Error(), "Too long: must have at most") {
+		return fmt.Errorf("%w (the state exceeds the size limit of a Secret, consider setting spec.backendConfig.sharded)", err)
+	}
+	return err
+}
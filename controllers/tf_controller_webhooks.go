@@ -3,8 +3,11 @@ package controllers
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -13,21 +16,25 @@ import (
 	"os"
 	"strings"
 	"text/template"
+	"time"
 
 	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
 	"github.com/flux-iac/tofu-controller/runner"
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 	"github.com/hashicorp/go-cleanhttp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
-func shouldProcessPostPlanningWebhooks(terraform infrav1.Terraform) bool {
+func shouldProcessWebhooks(terraform infrav1.Terraform, stage string) bool {
 	if terraform.Spec.Webhooks == nil || len(terraform.Spec.Webhooks) < 1 {
 		return false
 	}
 
 	for _, webhook := range terraform.Spec.Webhooks {
-		if webhook.Stage == infrav1.PostPlanningWebhook {
+		if webhook.Stage == stage {
 			return true
 		}
 	}
@@ -37,6 +44,10 @@ func shouldProcessPostPlanningWebhooks(terraform infrav1.Terraform) bool {
 	return false
 }
 
+func shouldProcessPostPlanningWebhooks(terraform infrav1.Terraform) bool {
+	return shouldProcessWebhooks(terraform, infrav1.PostPlanningWebhook)
+}
+
 func (r *TerraformReconciler) prepareWebhookPayload(terraform infrav1.Terraform, runnerClient runner.RunnerClient, payloadType string, tfInstance string) ([]byte, error) {
 	toBytes, err := terraform.ToBytes(r.Scheme)
 	if err != nil {
@@ -98,24 +109,158 @@ func (r *TerraformReconciler) prepareWebhookPayload(terraform infrav1.Terraform,
 	return jsonBytes, nil
 }
 
+// preparePostApplyWebhookPayload builds the payload of the post-apply webhooks. Instead of the plan,
+// it carries the outputs and the inventory of the apply. Sensitive output values are redacted.
+func (r *TerraformReconciler) preparePostApplyWebhookPayload(terraform infrav1.Terraform, runnerClient runner.RunnerClient, payloadType string, tfInstance string) ([]byte, error) {
+	toBytes, err := terraform.ToBytes(r.Scheme)
+	if err != nil {
+		err = fmt.Errorf("failed to marshal Terraform resource: %w", err)
+		return nil, err
+	}
+
+	outputReply, err := runnerClient.Output(context.Background(), &runner.OutputRequest{
+		TfInstance: tfInstance,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to get outputs: %w", err)
+		return nil, err
+	}
+
+	outputs := map[string]interface{}{}
+	for name, meta := range outputReply.Outputs {
+		output := map[string]interface{}{
+			"sensitive": meta.Sensitive,
+			"type":      json.RawMessage(meta.Type),
+		}
+		if !meta.Sensitive && len(meta.Value) > 0 {
+			output["value"] = json.RawMessage(meta.Value)
+		}
+		outputs[name] = output
+	}
+
+	var inventory []infrav1.ResourceRef
+	if terraform.Status.Inventory != nil {
+		inventory = terraform.Status.Inventory.Entries
+	}
+
+	resultBytes, err := json.Marshal(map[string]interface{}{
+		"outputs":   outputs,
+		"inventory": inventory,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to marshal apply result: %w", err)
+		return nil, err
+	}
+
+	resultObj, err := yaml.ConvertJSONToYamlNode(string(resultBytes))
+	if err != nil {
+		err = fmt.Errorf("failed to convert apply result to YAML: %w", err)
+		return nil, err
+	}
+
+	obj, err := yaml.ConvertJSONToYamlNode(string(toBytes))
+	if err != nil {
+		err = fmt.Errorf("failed to convert Terraform resource to YAML: %w", err)
+		return nil, err
+	}
+
+	if payloadType == "SpecAndPlan" {
+		obj, err = obj.Pipe(
+			yaml.Tee(yaml.Clear("status")),
+			yaml.Tee(
+				yaml.LookupCreate(yaml.MappingNode, "status"),
+				yaml.SetField("outputs", resultObj.Field("outputs").Value),
+				yaml.SetField("inventory", resultObj.Field("inventory").Value),
+			),
+		)
+	} else if payloadType == "SpecOnly" {
+		obj, err = obj.Pipe(
+			yaml.Tee(yaml.Clear("status")),
+		)
+	} else if payloadType == "PlanOnly" {
+		obj = resultObj
+	} else {
+		return nil, fmt.Errorf("unknown payload type: %s", payloadType)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("failed to add apply result to Terraform resource: %w", err)
+		return nil, err
+	}
+
+	jsonBytes, err := obj.MarshalJSON()
+	if err != nil {
+		err = fmt.Errorf("failed to marshal Terraform resource with apply result: %w", err)
+		return nil, err
+	}
+
+	return jsonBytes, nil
+}
+
 func (r *TerraformReconciler) processPostPlanningWebhooks(ctx context.Context, terraform infrav1.Terraform, runnerClient runner.RunnerClient, revision string, tfInstance string) (infrav1.Terraform, error) {
+	rejected, err := r.processWebhooks(ctx, terraform, infrav1.PostPlanningWebhook, func(payloadType string) ([]byte, error) {
+		return r.prepareWebhookPayload(terraform, runnerClient, payloadType, tfInstance)
+	})
+	if rejected {
+		terraform = infrav1.TerraformPostPlanningWebhookFailed(terraform, revision, err.Error())
+	}
+
+	return terraform, err
+}
+
+// processPreApplyWebhooks sends the approved plan to the pre-apply webhooks, which can veto the apply.
+// The plan stays pending, so it is applied once the webhooks accept it.
+func (r *TerraformReconciler) processPreApplyWebhooks(ctx context.Context, terraform infrav1.Terraform, runnerClient runner.RunnerClient, revision string, tfInstance string) (infrav1.Terraform, error) {
+	rejected, err := r.processWebhooks(ctx, terraform, infrav1.PreApplyWebhook, func(payloadType string) ([]byte, error) {
+		return r.prepareWebhookPayload(terraform, runnerClient, payloadType, tfInstance)
+	})
+	if rejected {
+		terraform = infrav1.TerraformPreApplyWebhookFailed(terraform, revision, err.Error())
+	}
+
+	return terraform, err
+}
+
+// processPostApplyWebhooks sends the outputs and the inventory of a successful apply to the post-apply webhooks.
+// It is called once the outputs are written. A failure of the webhooks does not fail the reconciliation,
+// as the apply succeeded: it is recorded on the PostApplyWebhook condition and as a warning event.
+func (r *TerraformReconciler) processPostApplyWebhooks(ctx context.Context, terraform infrav1.Terraform, runnerClient runner.RunnerClient, revision string, tfInstance string) infrav1.Terraform {
+	log := ctrl.LoggerFrom(ctx)
+
+	_, err := r.processWebhooks(ctx, terraform, infrav1.PostApplyWebhook, func(payloadType string) ([]byte, error) {
+		return r.preparePostApplyWebhookPayload(terraform, runnerClient, payloadType, tfInstance)
+	})
+	if err != nil {
+		log.Error(err, "failed during process post-apply webhooks")
+		terraform = infrav1.TerraformPostApplyWebhookFailed(terraform, err.Error())
+		r.event(ctx, terraform, revision, eventv1.EventSeverityError, fmt.Sprintf("Post-apply webhooks failed: %s", err), nil)
+		return terraform
+	}
+
+	return infrav1.TerraformPostApplyWebhookSucceeded(terraform, "Post-apply webhooks succeeded")
+}
+
+// processWebhooks calls every enabled webhook of the given stage, in order.
+// It returns rejected=true when a webhook reply fails its TestExpression, in which case
+// the error carries the message rendered from the ErrorMessageTemplate.
+func (r *TerraformReconciler) processWebhooks(ctx context.Context, terraform infrav1.Terraform, stage string, preparePayload func(payloadType string) ([]byte, error)) (bool, error) {
 	log := ctrl.LoggerFrom(ctx)
 
 	hooks := []infrav1.Webhook{}
 	for _, webhook := range terraform.Spec.Webhooks {
-		if webhook.Stage == infrav1.PostPlanningWebhook {
+		if webhook.Stage == stage {
 			hooks = append(hooks, webhook)
 		}
 	}
 
 	if len(hooks) == 0 {
-		return terraform, nil
+		return false, nil
 	}
 
 	disableWebhookTLSVerification := os.Getenv("DISABLE_WEBHOOK_TLS_VERIFY") == "1"
 
 	for _, webhook := range hooks {
-		log.Info("processing post-planning webhook", "webhook", webhook.URL)
+		log.Info(fmt.Sprintf("processing %s webhook", stage), "webhook", webhook.URL)
 
 		// We skip webhook if it's not enabled
 		if webhook.IsEnabled() == false {
@@ -124,76 +269,26 @@ func (r *TerraformReconciler) processPostPlanningWebhooks(ctx context.Context, t
 
 		log.Info("webhook is enabled, processing")
 
-		payloadBytes, err := r.prepareWebhookPayload(terraform, runnerClient, webhook.PayloadType, tfInstance)
+		payloadBytes, err := preparePayload(webhook.PayloadType)
 		if err != nil {
 			err = fmt.Errorf("failed to prepare webhook payload: %w", err)
-			return terraform, err
+			return false, err
 		}
-
 		log.Info("webhook payload prepared")
 
-		cli := cleanhttp.DefaultClient()
-
-		if disableWebhookTLSVerification == false {
-
-			log.Info("webhook TLS verification is enabled")
-
-			// parse webhook.URL and get the server name
-			u, err := url.Parse(webhook.URL)
-			if err != nil {
-				err = fmt.Errorf("failed to parse webhook URL: %w", err)
-				return terraform, err
-			}
-
-			log.Info("webhook URL parsed", "host", u.Host)
-
-			caCertPath := "/etc/certs/" + u.Hostname() + "/ca.crt"
-			caCertPool := x509.NewCertPool()
-			caCert, err := ioutil.ReadFile(caCertPath)
-			if err == nil {
-				caCertPool.AppendCertsFromPEM(caCert)
-			}
-
-			log.Info("webhook CA cert loaded", "path", caCertPath)
-
-			tlsCertPath := "/etc/certs/" + u.Hostname() + "/tls.crt"
-			tlsKeyPath := "/etc/certs/" + u.Hostname() + "/tls.key"
-			certificate, err := tls.LoadX509KeyPair(tlsCertPath, tlsKeyPath)
-			if err != nil {
-				err = fmt.Errorf("failed to load webhook TLS certificate: %w", err)
-				return terraform, err
-			}
-
-			log.Info("webhook TLS cert loaded", "path", tlsCertPath, "keypath", tlsKeyPath)
-
-			cli.Transport.(*http.Transport).TLSClientConfig = &tls.Config{
-				RootCAs:      caCertPool,
-				Certificates: []tls.Certificate{certificate},
-			}
-
-			log.Info("webhook TLS config set")
-		}
-
-		post, err := cli.Post(webhook.URL, "application/json", bytes.NewReader(payloadBytes))
+		credentials, err := r.getWebhookCredentials(ctx, terraform, webhook)
 		if err != nil {
-			err = fmt.Errorf("failed to send webhook: %w", err)
-			return terraform, err
+			return false, err
 		}
 
-		log.Info("webhook sent")
-
-		if post.StatusCode != 200 {
-			return terraform, fmt.Errorf("webhook %s returned %d: %s", webhook.URL, post.StatusCode, post.Status)
+		cli, err := newWebhookHTTPClient(ctx, webhook, credentials, disableWebhookTLSVerification)
+		if err != nil {
+			return false, err
 		}
 
-		log.Info(fmt.Sprintf("webhook returned %d: %s", post.StatusCode, post.Status))
-
-		// read json from post.Body, unmarshall to map[string]interface{}
-		jsonReply := map[string]interface{}{}
-		err = json.NewDecoder(post.Body).Decode(&jsonReply)
+		jsonReply, err := postWebhook(ctx, cli, webhook, credentials, payloadBytes)
 		if err != nil {
-			err = fmt.Errorf("failed to decode webhook reply: %w", err)
-			return terraform, err
+			return false, err
 		}
 
 		log.Info("webhook reply decoded")
@@ -205,7 +300,7 @@ func (r *TerraformReconciler) processPostPlanningWebhooks(ctx context.Context, t
 			Parse(webhook.TestExpression)
 		if err != nil {
 			err = fmt.Errorf("failed to parse webhook test expression: %w", err)
-			return terraform, err
+			return false, err
 		}
 
 		log.Info("webhook test expression parsed")
@@ -214,7 +309,7 @@ func (r *TerraformReconciler) processPostPlanningWebhooks(ctx context.Context, t
 		err = testExprTpl.Execute(&testExprBuf, jsonReply)
 		if err != nil {
 			err = fmt.Errorf("failed to execute webhook test expression: %w", err)
-			return terraform, err
+			return false, err
 		}
 
 		log.Info("webhook test expression executed")
@@ -226,7 +321,7 @@ func (r *TerraformReconciler) processPostPlanningWebhooks(ctx context.Context, t
 		} else if testResult == "false" || testResult == "no" {
 			// do nothing
 		} else {
-			return terraform, fmt.Errorf("webhook test expression %q returned unexpected result: %s", webhook.TestExpression, testResult)
+			return false, fmt.Errorf("webhook test expression %q returned unexpected result: %s", webhook.TestExpression, testResult)
 		}
 
 		log.Info("webhook test expression returned false, webhook is not successful - prepare error message")
@@ -238,7 +333,7 @@ func (r *TerraformReconciler) processPostPlanningWebhooks(ctx context.Context, t
 			Parse(webhook.ErrorMessageTemplate)
 		if err != nil {
 			err = fmt.Errorf("failed to parse webhook error message template: %w", err)
-			return terraform, err
+			return false, err
 		}
 
 		log.Info("webhook error message template parsed")
@@ -247,15 +342,192 @@ func (r *TerraformReconciler) processPostPlanningWebhooks(ctx context.Context, t
 		err = errMsgTpl.Execute(&errorMessage, jsonReply)
 		if err != nil {
 			err = fmt.Errorf("failed to execute webhook error message template: %w", err)
-			return terraform, err
+			return false, err
 		}
 
 		log.Info("webhook error message template executed")
 
-		terraform = infrav1.TerraformPostPlanningWebhookFailed(terraform, revision, errorMessage.String())
 		webhookErr := fmt.Errorf(errorMessage.String())
-		return terraform, webhookErr
+		return true, webhookErr
+	}
+
+	return false, nil
+}
+
+const (
+	webhookSignatureHeader = "X-Signature-256"
+
+	webhookHMACKey      = "hmacKey"
+	webhookTokenKey     = "token"
+	webhookTLSCertKey   = "tls.crt"
+	webhookTLSKeyKey    = "tls.key"
+	webhookTLSCACertKey = "ca.crt"
+)
+
+// getWebhookCredentials returns the data of the Secret referenced by the webhook, if any.
+func (r *TerraformReconciler) getWebhookCredentials(ctx context.Context, terraform infrav1.Terraform, webhook infrav1.Webhook) (map[string][]byte, error) {
+	if webhook.SecretRef == nil {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	secretLookupKey := types.NamespacedName{
+		Namespace: terraform.Namespace,
+		Name:      webhook.SecretRef.Name,
+	}
+	if err := r.Get(ctx, secretLookupKey, secret); err != nil {
+		return nil, fmt.Errorf("failed to get webhook secret %s: %w", secretLookupKey, err)
+	}
+
+	return secret.Data, nil
+}
+
+// newWebhookHTTPClient returns an HTTP client for the webhook. The client certificate and the CA
+// are taken from the webhook Secret, and fall back to the files in /etc/certs/<hostname>/.
+func newWebhookHTTPClient(ctx context.Context, webhook infrav1.Webhook, credentials map[string][]byte, disableTLSVerification bool) (*http.Client, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	cli := cleanhttp.DefaultClient()
+	cli.Timeout = webhook.GetTimeout()
+
+	if disableTLSVerification {
+		return cli, nil
+	}
+
+	log.Info("webhook TLS verification is enabled")
+
+	// parse webhook.URL and get the server name
+	u, err := url.Parse(webhook.URL)
+	if err != nil {
+		err = fmt.Errorf("failed to parse webhook URL: %w", err)
+		return nil, err
+	}
+
+	log.Info("webhook URL parsed", "host", u.Host)
+
+	caCertPool := x509.NewCertPool()
+	if caCert, ok := credentials[webhookTLSCACertKey]; ok {
+		caCertPool.AppendCertsFromPEM(caCert)
+		log.Info("webhook CA cert loaded from secret")
+	} else {
+		caCertPath := "/etc/certs/" + u.Hostname() + "/ca.crt"
+		caCert, err := ioutil.ReadFile(caCertPath)
+		if err == nil {
+			caCertPool.AppendCertsFromPEM(caCert)
+		}
+		log.Info("webhook CA cert loaded", "path", caCertPath)
+	}
+
+	tlsConfig := &tls.Config{
+		RootCAs: caCertPool,
+	}
+
+	if certPEM, ok := credentials[webhookTLSCertKey]; ok {
+		certificate, err := tls.X509KeyPair(certPEM, credentials[webhookTLSKeyKey])
+		if err != nil {
+			err = fmt.Errorf("failed to load webhook TLS certificate from secret: %w", err)
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+		log.Info("webhook TLS cert loaded from secret")
+	} else {
+		tlsCertPath := "/etc/certs/" + u.Hostname() + "/tls.crt"
+		tlsKeyPath := "/etc/certs/" + u.Hostname() + "/tls.key"
+		certificate, err := tls.LoadX509KeyPair(tlsCertPath, tlsKeyPath)
+		if err == nil {
+			tlsConfig.Certificates = []tls.Certificate{certificate}
+			log.Info("webhook TLS cert loaded", "path", tlsCertPath, "keypath", tlsKeyPath)
+		} else if webhook.SecretRef == nil {
+			// without a secret, the client certificate is the only way to authenticate
+			err = fmt.Errorf("failed to load webhook TLS certificate: %w", err)
+			return nil, err
+		}
+	}
+
+	cli.Transport.(*http.Transport).TLSClientConfig = tlsConfig
+
+	log.Info("webhook TLS config set")
+
+	return cli, nil
+}
+
+// signWebhookPayload returns the HMAC-SHA256 signature of the payload, in the "sha256=<hex>" format.
+func signWebhookPayload(key []byte, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// postWebhook sends the payload to the webhook and decodes its JSON reply.
+// Connection errors, 429 and 5xx responses are retried with an exponential backoff.
+func postWebhook(ctx context.Context, cli *http.Client, webhook infrav1.Webhook, credentials map[string][]byte, payload []byte) (map[string]interface{}, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// the retries are bounded by the webhook timeout, so that they do not hold the worker
+	// for the whole exponential backoff
+	deadline := time.Now().Add(webhook.GetTimeout())
+	interval := webhook.GetRetryInterval()
+	for attempt := 0; ; attempt++ {
+		jsonReply, retryable, err := doPostWebhook(ctx, cli, webhook, credentials, payload)
+		if err == nil || !retryable || attempt >= webhook.Retries {
+			return jsonReply, err
+		}
+		if time.Now().Add(interval).After(deadline) {
+			log.Info("webhook call failed, giving up retrying after the webhook timeout", "attempt", attempt+1, "error", err.Error())
+			return nil, err
+		}
+
+		log.Info("webhook call failed, retrying", "attempt", attempt+1, "retryIn", interval.String(), "error", err.Error())
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+		interval = interval * 2
+	}
+}
+
+func doPostWebhook(ctx context.Context, cli *http.Client, webhook infrav1.Webhook, credentials map[string][]byte, payload []byte) (map[string]interface{}, bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		err = fmt.Errorf("failed to create webhook request: %w", err)
+		return nil, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if key, ok := credentials[webhookHMACKey]; ok {
+		req.Header.Set(webhookSignatureHeader, signWebhookPayload(key, payload))
+	}
+
+	if token, ok := credentials[webhookTokenKey]; ok {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	post, err := cli.Do(req)
+	if err != nil {
+		err = fmt.Errorf("failed to send webhook: %w", err)
+		return nil, true, err
+	}
+	defer post.Body.Close()
+
+	log.Info("webhook sent")
+
+	if post.StatusCode != 200 {
+		retryable := post.StatusCode == http.StatusTooManyRequests || post.StatusCode >= 500
+		return nil, retryable, fmt.Errorf("webhook %s returned %d: %s", webhook.URL, post.StatusCode, post.Status)
+	}
+
+	log.Info(fmt.Sprintf("webhook returned %d: %s", post.StatusCode, post.Status))
+
+	// read json from post.Body, unmarshall to map[string]interface{}
+	jsonReply := map[string]interface{}{}
+	err = json.NewDecoder(post.Body).Decode(&jsonReply)
+	if err != nil {
+		err = fmt.Errorf("failed to decode webhook reply: %w", err)
+		return nil, false, err
 	}
 
-	return terraform, nil
+	return jsonReply, false, nil
 }
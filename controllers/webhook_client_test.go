@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/hashicorp/go-cleanhttp"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	g := NewWithT(t)
+
+	// echo -n '{"hello":"world"}' | openssl dgst -sha256 -hmac secret
+	g.Expect(signWebhookPayload([]byte("secret"), []byte(`{"hello":"world"}`))).
+		To(Equal("sha256=2677ad3e7c090b2fa2c0fb13020d66d5420879b8316eb356a2d60fb9073bc778"))
+}
+
+func TestPostWebhookSignsAndRetries(t *testing.T) {
+	g := NewWithT(t)
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(webhookSignatureHeader) != signWebhookPayload([]byte("secret"), body) ||
+			r.Header.Get("Authorization") != "Bearer my-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"passed": true}`))
+	}))
+	defer srv.Close()
+
+	webhook := infrav1.Webhook{
+		URL:           srv.URL,
+		Retries:       2,
+		RetryInterval: &metav1.Duration{Duration: time.Millisecond},
+	}
+	credentials := map[string][]byte{
+		webhookHMACKey:  []byte("secret"),
+		webhookTokenKey: []byte("my-token\n"),
+	}
+
+	reply, err := postWebhook(context.TODO(), cleanhttp.DefaultClient(), webhook, credentials, []byte(`{"hello":"world"}`))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(reply).To(HaveKeyWithValue("passed", true))
+	g.Expect(calls.Load()).To(Equal(int32(3)))
+}
+
+func TestPostWebhookDoesNotRetryClientErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	webhook := infrav1.Webhook{
+		URL:           srv.URL,
+		Retries:       3,
+		RetryInterval: &metav1.Duration{Duration: time.Millisecond},
+	}
+
+	_, err := postWebhook(context.TODO(), cleanhttp.DefaultClient(), webhook, nil, []byte(`{}`))
+	g.Expect(err).To(MatchError(ContainSubstring("returned 401")))
+	g.Expect(calls.Load()).To(Equal(int32(1)))
+}
+
+func TestPostWebhookStopsRetryingAfterTimeout(t *testing.T) {
+	g := NewWithT(t)
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	webhook := infrav1.Webhook{
+		URL:           srv.URL,
+		Timeout:       &metav1.Duration{Duration: 100 * time.Millisecond},
+		Retries:       10,
+		RetryInterval: &metav1.Duration{Duration: 20 * time.Millisecond},
+	}
+
+	start := time.Now()
+	_, err := postWebhook(context.TODO(), cleanhttp.DefaultClient(), webhook, nil, []byte(`{}`))
+	g.Expect(err).To(MatchError(ContainSubstring("returned 503")))
+	g.Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+	// retried after 20ms and 40ms, the next retry after 80ms more would be past the timeout
+	g.Expect(calls.Load()).To(Equal(int32(3)))
+}
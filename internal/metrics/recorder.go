@@ -0,0 +1,203 @@
+// Package metrics records the Terraform specific metrics of the controller, next to the
+// generic reconciliation metrics of the Flux runtime.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/prometheus/client_golang/prometheus"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+const (
+	StageInit           = "init"
+	StagePlan           = "plan"
+	StageApply          = "apply"
+	StageDriftDetection = "drift_detection"
+)
+
+// Recorder records the Terraform specific metrics. A nil Recorder records nothing.
+type Recorder struct {
+	stageDuration          *prometheus.HistogramVec
+	resourceChanges        *prometheus.GaugeVec
+	driftDetected          *prometheus.GaugeVec
+	stateLocked            *prometheus.GaugeVec
+	runnerCreationDuration prometheus.Histogram
+	outputWriteFailures    *prometheus.CounterVec
+	pendingPlans           *pendingPlanCollector
+}
+
+// NewRecorder returns a Recorder with its collectors, to be registered with Collectors.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		stageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "tofu_controller_stage_duration_seconds",
+			Help:    "The duration in seconds of the init, plan, apply and drift detection of a Terraform object.",
+			Buckets: []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+		}, []string{"namespace", "name", "stage"}),
+		resourceChanges: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "tofu_controller_plan_resource_changes",
+			Help: "The number of resources to add, change and destroy in the last plan of a Terraform object.",
+		}, []string{"namespace", "name", "action"}),
+		driftDetected: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "tofu_controller_drift_detected",
+			Help: "Whether drift was detected on a Terraform object and is not corrected yet.",
+		}, []string{"namespace", "name"}),
+		stateLocked: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "tofu_controller_state_locked",
+			Help: "Whether the state of a Terraform object was locked by another process at the last reconciliation.",
+		}, []string{"namespace", "name"}),
+		runnerCreationDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "tofu_controller_runner_creation_duration_seconds",
+			Help:    "The duration in seconds from the creation of a runner pod until it gets an IP.",
+			Buckets: []float64{1, 2, 5, 10, 20, 30, 60, 120, 300},
+		}),
+		outputWriteFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tofu_controller_output_write_failures_total",
+			Help: "The number of failures to write the outputs of a Terraform object.",
+		}, []string{"namespace", "name"}),
+		pendingPlans: newPendingPlanCollector(),
+	}
+}
+
+// Collectors returns the collectors of the recorder.
+func (r *Recorder) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		r.stageDuration,
+		r.resourceChanges,
+		r.driftDetected,
+		r.stateLocked,
+		r.runnerCreationDuration,
+		r.outputWriteFailures,
+		r.pendingPlans,
+	}
+}
+
+// RecordStageDuration records the duration of a stage of a Terraform object started at start.
+func (r *Recorder) RecordStageDuration(terraform infrav1.Terraform, stage string, start time.Time) {
+	if r == nil {
+		return
+	}
+	r.stageDuration.WithLabelValues(terraform.Namespace, terraform.Name, stage).Observe(time.Since(start).Seconds())
+}
+
+// RecordResourceChanges records the number of resources to add, change and destroy in the last plan.
+func (r *Recorder) RecordResourceChanges(terraform infrav1.Terraform, add, change, destroy int) {
+	if r == nil {
+		return
+	}
+	r.resourceChanges.WithLabelValues(terraform.Namespace, terraform.Name, "add").Set(float64(add))
+	r.resourceChanges.WithLabelValues(terraform.Namespace, terraform.Name, "change").Set(float64(change))
+	r.resourceChanges.WithLabelValues(terraform.Namespace, terraform.Name, "destroy").Set(float64(destroy))
+}
+
+// RecordRunnerCreation records the duration of the creation of a runner pod started at start.
+func (r *Recorder) RecordRunnerCreation(start time.Time) {
+	if r == nil {
+		return
+	}
+	r.runnerCreationDuration.Observe(time.Since(start).Seconds())
+}
+
+// RecordOutputWriteFailure counts a failure to write the outputs of a Terraform object.
+func (r *Recorder) RecordOutputWriteFailure(terraform infrav1.Terraform) {
+	if r == nil {
+		return
+	}
+	r.outputWriteFailures.WithLabelValues(terraform.Namespace, terraform.Name).Inc()
+}
+
+// RecordStatus records the metrics derived from the status of a Terraform object:
+// drift, lock and pending plan.
+func (r *Recorder) RecordStatus(terraform infrav1.Terraform) {
+	if r == nil {
+		return
+	}
+
+	drifted, locked := 0.0, 0.0
+	if ready := apimeta.FindStatusCondition(terraform.Status.Conditions, meta.ReadyCondition); ready != nil {
+		switch ready.Reason {
+		case infrav1.DriftDetectedReason:
+			drifted = 1
+		case infrav1.TFExecLockHeldReason:
+			locked = 1
+		}
+	}
+	r.driftDetected.WithLabelValues(terraform.Namespace, terraform.Name).Set(drifted)
+	r.stateLocked.WithLabelValues(terraform.Namespace, terraform.Name).Set(locked)
+
+	if terraform.Status.Plan.Pending == "" {
+		r.pendingPlans.delete(terraform.Namespace, terraform.Name)
+		return
+	}
+	since := time.Now()
+	if plan := apimeta.FindStatusCondition(terraform.Status.Conditions, infrav1.ConditionTypePlan); plan != nil &&
+		plan.Reason == infrav1.PlannedWithChangesReason {
+		since = plan.LastTransitionTime.Time
+	}
+	r.pendingPlans.set(terraform.Namespace, terraform.Name, since)
+}
+
+// Delete removes the metrics of a deleted Terraform object.
+func (r *Recorder) Delete(terraform infrav1.Terraform) {
+	if r == nil {
+		return
+	}
+	labels := prometheus.Labels{"namespace": terraform.Namespace, "name": terraform.Name}
+	r.stageDuration.DeletePartialMatch(labels)
+	r.resourceChanges.DeletePartialMatch(labels)
+	r.driftDetected.Delete(labels)
+	r.stateLocked.Delete(labels)
+	r.outputWriteFailures.Delete(labels)
+	r.pendingPlans.delete(terraform.Namespace, terraform.Name)
+}
+
+// pendingPlanCollector reports the age of the pending plans at scrape time.
+type pendingPlanCollector struct {
+	desc *prometheus.Desc
+	now  func() time.Time
+
+	mu    sync.Mutex
+	since map[[2]string]time.Time
+}
+
+func newPendingPlanCollector() *pendingPlanCollector {
+	return &pendingPlanCollector{
+		desc: prometheus.NewDesc(
+			"tofu_controller_plan_pending_age_seconds",
+			"The time in seconds since the pending plan of a Terraform object was generated.",
+			[]string{"namespace", "name"}, nil,
+		),
+		now:   time.Now,
+		since: map[[2]string]time.Time{},
+	}
+}
+
+func (c *pendingPlanCollector) set(namespace, name string, since time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.since[[2]string{namespace, name}] = since
+}
+
+func (c *pendingPlanCollector) delete(namespace, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.since, [2]string{namespace, name})
+}
+
+func (c *pendingPlanCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *pendingPlanCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for key, since := range c.since {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, now.Sub(since).Seconds(), key[0], key[1])
+	}
+}
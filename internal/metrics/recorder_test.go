@@ -0,0 +1,81 @@
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	gm "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/internal/metrics"
+)
+
+func Test_Recorder(t *testing.T) {
+	g := gm.NewWithT(t)
+
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder()
+	registry.MustRegister(recorder.Collectors()...)
+
+	terraform := infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-resource", Namespace: "default"},
+		Status: infrav1.TerraformStatus{
+			Plan: infrav1.PlanStatus{Pending: "plan-main-1234"},
+			Conditions: []metav1.Condition{
+				{Type: meta.ReadyCondition, Status: metav1.ConditionFalse, Reason: infrav1.DriftDetectedReason},
+				{
+					Type:               infrav1.ConditionTypePlan,
+					Status:             metav1.ConditionTrue,
+					Reason:             infrav1.PlannedWithChangesReason,
+					LastTransitionTime: metav1.NewTime(time.Now().Add(-25 * time.Hour)),
+				},
+			},
+		},
+	}
+
+	recorder.RecordResourceChanges(terraform, 1, 2, 3)
+	recorder.RecordStatus(terraform)
+	recorder.RecordOutputWriteFailure(terraform)
+	recorder.RecordStageDuration(terraform, metrics.StagePlan, time.Now().Add(-time.Minute))
+
+	g.Expect(testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP tofu_controller_plan_resource_changes The number of resources to add, change and destroy in the last plan of a Terraform object.
+# TYPE tofu_controller_plan_resource_changes gauge
+tofu_controller_plan_resource_changes{action="add",name="my-resource",namespace="default"} 1
+tofu_controller_plan_resource_changes{action="change",name="my-resource",namespace="default"} 2
+tofu_controller_plan_resource_changes{action="destroy",name="my-resource",namespace="default"} 3
+# HELP tofu_controller_drift_detected Whether drift was detected on a Terraform object and is not corrected yet.
+# TYPE tofu_controller_drift_detected gauge
+tofu_controller_drift_detected{name="my-resource",namespace="default"} 1
+# HELP tofu_controller_state_locked Whether the state of a Terraform object was locked by another process at the last reconciliation.
+# TYPE tofu_controller_state_locked gauge
+tofu_controller_state_locked{name="my-resource",namespace="default"} 0
+# HELP tofu_controller_output_write_failures_total The number of failures to write the outputs of a Terraform object.
+# TYPE tofu_controller_output_write_failures_total counter
+tofu_controller_output_write_failures_total{name="my-resource",namespace="default"} 1
+`), "tofu_controller_plan_resource_changes", "tofu_controller_drift_detected", "tofu_controller_state_locked",
+		"tofu_controller_output_write_failures_total")).To(gm.Succeed())
+	g.Expect(testutil.CollectAndCount(registry, "tofu_controller_stage_duration_seconds")).To(gm.Equal(1))
+
+	// the age of the pending plan follows the Plan condition
+	families, err := registry.Gather()
+	g.Expect(err).ToNot(gm.HaveOccurred())
+	var age float64
+	for _, family := range families {
+		if family.GetName() == "tofu_controller_plan_pending_age_seconds" {
+			age = family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	g.Expect(age).To(gm.BeNumerically(">=", (25 * time.Hour).Seconds()))
+
+	// the series of a deleted object are removed
+	recorder.Delete(terraform)
+	g.Expect(testutil.CollectAndCount(registry, "tofu_controller_stage_duration_seconds", "tofu_controller_plan_resource_changes",
+		"tofu_controller_drift_detected", "tofu_controller_state_locked", "tofu_controller_output_write_failures_total",
+		"tofu_controller_plan_pending_age_seconds")).To(gm.Equal(0))
+}
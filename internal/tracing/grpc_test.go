@@ -0,0 +1,61 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gm "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/flux-iac/tofu-controller/internal/tracing"
+)
+
+func Test_Interceptors(t *testing.T) {
+	g := gm.NewWithT(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	_, err := tracing.Setup(context.Background(), "test", tracing.Options{})
+	g.Expect(err).ToNot(gm.HaveOccurred())
+
+	server := tracing.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/runner.Runner/Plan"}
+	handlerErr := errors.New("plan failed")
+
+	// the invoker hands the outgoing metadata of the controller over to the runner
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		_, err := server(metadata.NewIncomingContext(context.Background(), md), req, info,
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, handlerErr
+			})
+		return err
+	}
+
+	ctx, parent := tracing.Start(context.Background(), "plan", "default", "my-resource")
+	err = tracing.UnaryClientInterceptor()(ctx, info.FullMethod, nil, nil, nil, invoker)
+	g.Expect(err).To(gm.MatchError(handlerErr))
+	tracing.End(parent, err)
+
+	spans := recorder.Ended()
+	g.Expect(spans).To(gm.HaveLen(3))
+	runnerSpan, clientSpan, planSpan := spans[0], spans[1], spans[2]
+
+	// the spans of the runner belong to the trace of the controller
+	g.Expect(planSpan.Name()).To(gm.Equal("plan"))
+	g.Expect(clientSpan.Parent().SpanID()).To(gm.Equal(planSpan.SpanContext().SpanID()))
+	g.Expect(runnerSpan.Parent().SpanID()).To(gm.Equal(clientSpan.SpanContext().SpanID()))
+	g.Expect(runnerSpan.Parent().IsRemote()).To(gm.BeTrue())
+	g.Expect(runnerSpan.SpanContext().TraceID()).To(gm.Equal(planSpan.SpanContext().TraceID()))
+
+	// the errors are recorded on every span
+	for _, span := range spans {
+		g.Expect(span.Status().Code).To(gm.Equal(codes.Error))
+	}
+}
@@ -0,0 +1,77 @@
+// Package tracing exports the OpenTelemetry traces of the controller and the runners
+// over OTLP, and propagates the trace context from the controller to the runners.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/flux-iac/tofu-controller"
+
+// Options configures the export of the traces.
+type Options struct {
+	// Endpoint is the host and port of the OTLP/HTTP collector, e.g. localhost:4318.
+	// Traces are not exported when it is empty.
+	Endpoint string
+	// Insecure disables TLS to the collector.
+	Insecure bool
+}
+
+// Setup installs the global tracer provider exporting the traces of the service to the collector,
+// and the W3C trace context propagator. The returned function flushes and stops the export.
+func Setup(ctx context.Context, serviceName string, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporterOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start starts a span of a phase of the reconciliation of a Terraform object.
+func Start(ctx context.Context, name, namespace, objectName string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(
+		attribute.String("terraform.namespace", namespace),
+		attribute.String("terraform.name", objectName),
+	))
+}
+
+// End ends a span, recording the error if any.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/internal/tracing"
 	"github.com/flux-iac/tofu-controller/runner"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	"google.golang.org/grpc"
@@ -18,6 +19,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// RunnerServe serves the runner gRPC API until a signal is received on sigterm. The signal cancels
+// the running terraform commands, then stops the server once the pending calls have returned,
+// so that the caller can flush what is left, like the traces, before exiting.
 func RunnerServe(namespace, addr string, tlsSecretName string, sigterm chan os.Signal, maxMessageSizeInMiB int) error {
 	scheme := runtime.NewScheme()
 
@@ -40,11 +44,14 @@ func RunnerServe(namespace, addr string, tlsSecretName string, sigterm chan os.S
 		return fmt.Errorf("k8sClient cannot be nil")
 	}
 
+	// closed on SIGTERM, so that every running command is cancelled
+	done := make(chan os.Signal)
+
 	// local runner, use the same client as the manager
 	runnerServer := &runner.TerraformRunnerServer{
 		Client: k8sClient,
 		Scheme: scheme,
-		Done:   sigterm,
+		Done:   done,
 	}
 
 	listener, err := net.Listen("tcp", addr)
@@ -66,9 +73,20 @@ func RunnerServe(namespace, addr string, tlsSecretName string, sigterm chan os.S
 
 	// 30 MB is the maximum allowed payload size for gRPC.
 	maxMsgSize := maxMessageSizeInMiB * 1024 * 1024
-	grpcServer := grpc.NewServer(grpc.Creds(credentials), grpc.MaxRecvMsgSize(maxMsgSize), grpc.MaxSendMsgSize(maxMsgSize))
+	grpcServer := grpc.NewServer(
+		grpc.Creds(credentials),
+		grpc.MaxRecvMsgSize(maxMsgSize),
+		grpc.MaxSendMsgSize(maxMsgSize),
+		grpc.UnaryInterceptor(tracing.UnaryServerInterceptor()),
+	)
 	runner.RegisterRunnerServer(grpcServer, runnerServer)
 
+	go func() {
+		<-sigterm
+		close(done)
+		grpcServer.GracefulStop()
+	}()
+
 	if err := grpcServer.Serve(listener); err != nil {
 		return err
 	}
@@ -49,6 +49,13 @@ type RunnerClient interface {
 	Upload(ctx context.Context, in *UploadRequest, opts ...grpc.CallOption) (*UploadReply, error)
 	FinalizeSecrets(ctx context.Context, in *FinalizeSecretsRequest, opts ...grpc.CallOption) (*FinalizeSecretsReply, error)
 	ForceUnlock(ctx context.Context, in *ForceUnlockRequest, opts ...grpc.CallOption) (*ForceUnlockReply, error)
+	StateList(ctx context.Context, in *StateListRequest, opts ...grpc.CallOption) (*StateListReply, error)
+	StateShow(ctx context.Context, in *StateShowRequest, opts ...grpc.CallOption) (*StateShowReply, error)
+	StateMv(ctx context.Context, in *StateMvRequest, opts ...grpc.CallOption) (*StateMvReply, error)
+	StateRm(ctx context.Context, in *StateRmRequest, opts ...grpc.CallOption) (*StateRmReply, error)
+	Import(ctx context.Context, in *ImportRequest, opts ...grpc.CallOption) (*ImportReply, error)
+	MigrateState(ctx context.Context, in *MigrateStateRequest, opts ...grpc.CallOption) (*MigrateStateReply, error)
+	BackupState(ctx context.Context, in *BackupStateRequest, opts ...grpc.CallOption) (*BackupStateReply, error)
 	StartBreakTheGlassSession(ctx context.Context, in *BreakTheGlassRequest, opts ...grpc.CallOption) (*BreakTheGlassReply, error)
 	HasBreakTheGlassSessionDone(ctx context.Context, in *BreakTheGlassRequest, opts ...grpc.CallOption) (*BreakTheGlassReply, error)
 }
@@ -304,6 +311,69 @@ func (c *runnerClient) ForceUnlock(ctx context.Context, in *ForceUnlockRequest,
 	return out, nil
 }
 
+func (c *runnerClient) StateList(ctx context.Context, in *StateListRequest, opts ...grpc.CallOption) (*StateListReply, error) {
+	out := new(StateListReply)
+	err := c.cc.Invoke(ctx, "/runner.Runner/StateList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runnerClient) StateShow(ctx context.Context, in *StateShowRequest, opts ...grpc.CallOption) (*StateShowReply, error) {
+	out := new(StateShowReply)
+	err := c.cc.Invoke(ctx, "/runner.Runner/StateShow", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runnerClient) StateMv(ctx context.Context, in *StateMvRequest, opts ...grpc.CallOption) (*StateMvReply, error) {
+	out := new(StateMvReply)
+	err := c.cc.Invoke(ctx, "/runner.Runner/StateMv", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runnerClient) StateRm(ctx context.Context, in *StateRmRequest, opts ...grpc.CallOption) (*StateRmReply, error) {
+	out := new(StateRmReply)
+	err := c.cc.Invoke(ctx, "/runner.Runner/StateRm", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runnerClient) Import(ctx context.Context, in *ImportRequest, opts ...grpc.CallOption) (*ImportReply, error) {
+	out := new(ImportReply)
+	err := c.cc.Invoke(ctx, "/runner.Runner/Import", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runnerClient) MigrateState(ctx context.Context, in *MigrateStateRequest, opts ...grpc.CallOption) (*MigrateStateReply, error) {
+	out := new(MigrateStateReply)
+	err := c.cc.Invoke(ctx, "/runner.Runner/MigrateState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runnerClient) BackupState(ctx context.Context, in *BackupStateRequest, opts ...grpc.CallOption) (*BackupStateReply, error) {
+	out := new(BackupStateReply)
+	err := c.cc.Invoke(ctx, "/runner.Runner/BackupState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *runnerClient) StartBreakTheGlassSession(ctx context.Context, in *BreakTheGlassRequest, opts ...grpc.CallOption) (*BreakTheGlassReply, error) {
 	out := new(BreakTheGlassReply)
 	err := c.cc.Invoke(ctx, "/runner.Runner/StartBreakTheGlassSession", in, out, opts...)
@@ -353,6 +423,13 @@ type RunnerServer interface {
 	Upload(context.Context, *UploadRequest) (*UploadReply, error)
 	FinalizeSecrets(context.Context, *FinalizeSecretsRequest) (*FinalizeSecretsReply, error)
 	ForceUnlock(context.Context, *ForceUnlockRequest) (*ForceUnlockReply, error)
+	StateList(context.Context, *StateListRequest) (*StateListReply, error)
+	StateShow(context.Context, *StateShowRequest) (*StateShowReply, error)
+	StateMv(context.Context, *StateMvRequest) (*StateMvReply, error)
+	StateRm(context.Context, *StateRmRequest) (*StateRmReply, error)
+	Import(context.Context, *ImportRequest) (*ImportReply, error)
+	MigrateState(context.Context, *MigrateStateRequest) (*MigrateStateReply, error)
+	BackupState(context.Context, *BackupStateRequest) (*BackupStateReply, error)
 	StartBreakTheGlassSession(context.Context, *BreakTheGlassRequest) (*BreakTheGlassReply, error)
 	HasBreakTheGlassSessionDone(context.Context, *BreakTheGlassRequest) (*BreakTheGlassReply, error)
 	mustEmbedUnimplementedRunnerServer()
@@ -443,6 +520,27 @@ func (UnimplementedRunnerServer) FinalizeSecrets(context.Context, *FinalizeSecre
 func (UnimplementedRunnerServer) ForceUnlock(context.Context, *ForceUnlockRequest) (*ForceUnlockReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ForceUnlock not implemented")
 }
+func (UnimplementedRunnerServer) StateList(context.Context, *StateListRequest) (*StateListReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StateList not implemented")
+}
+func (UnimplementedRunnerServer) StateShow(context.Context, *StateShowRequest) (*StateShowReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StateShow not implemented")
+}
+func (UnimplementedRunnerServer) StateMv(context.Context, *StateMvRequest) (*StateMvReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StateMv not implemented")
+}
+func (UnimplementedRunnerServer) StateRm(context.Context, *StateRmRequest) (*StateRmReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StateRm not implemented")
+}
+func (UnimplementedRunnerServer) Import(context.Context, *ImportRequest) (*ImportReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Import not implemented")
+}
+func (UnimplementedRunnerServer) MigrateState(context.Context, *MigrateStateRequest) (*MigrateStateReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MigrateState not implemented")
+}
+func (UnimplementedRunnerServer) BackupState(context.Context, *BackupStateRequest) (*BackupStateReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BackupState not implemented")
+}
 func (UnimplementedRunnerServer) StartBreakTheGlassSession(context.Context, *BreakTheGlassRequest) (*BreakTheGlassReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StartBreakTheGlassSession not implemented")
 }
@@ -948,6 +1046,132 @@ func _Runner_ForceUnlock_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Runner_StateList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunnerServer).StateList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/runner.Runner/StateList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunnerServer).StateList(ctx, req.(*StateListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Runner_StateShow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateShowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunnerServer).StateShow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/runner.Runner/StateShow",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunnerServer).StateShow(ctx, req.(*StateShowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Runner_StateMv_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateMvRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunnerServer).StateMv(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/runner.Runner/StateMv",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunnerServer).StateMv(ctx, req.(*StateMvRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Runner_StateRm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateRmRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunnerServer).StateRm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/runner.Runner/StateRm",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunnerServer).StateRm(ctx, req.(*StateRmRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Runner_Import_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunnerServer).Import(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/runner.Runner/Import",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunnerServer).Import(ctx, req.(*ImportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Runner_MigrateState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrateStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunnerServer).MigrateState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/runner.Runner/MigrateState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunnerServer).MigrateState(ctx, req.(*MigrateStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Runner_BackupState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackupStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunnerServer).BackupState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/runner.Runner/BackupState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunnerServer).BackupState(ctx, req.(*BackupStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Runner_StartBreakTheGlassSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(BreakTheGlassRequest)
 	if err := dec(in); err != nil {
@@ -1099,6 +1323,34 @@ var Runner_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ForceUnlock",
 			Handler:    _Runner_ForceUnlock_Handler,
 		},
+		{
+			MethodName: "StateList",
+			Handler:    _Runner_StateList_Handler,
+		},
+		{
+			MethodName: "StateShow",
+			Handler:    _Runner_StateShow_Handler,
+		},
+		{
+			MethodName: "StateMv",
+			Handler:    _Runner_StateMv_Handler,
+		},
+		{
+			MethodName: "StateRm",
+			Handler:    _Runner_StateRm_Handler,
+		},
+		{
+			MethodName: "Import",
+			Handler:    _Runner_Import_Handler,
+		},
+		{
+			MethodName: "MigrateState",
+			Handler:    _Runner_MigrateState_Handler,
+		},
+		{
+			MethodName: "BackupState",
+			Handler:    _Runner_BackupState_Handler,
+		},
 		{
 			MethodName: "StartBreakTheGlassSession",
 			Handler:    _Runner_StartBreakTheGlassSession_Handler,
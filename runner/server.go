@@ -13,6 +13,8 @@ import (
 	securejoin "github.com/cyphar/filepath-securejoin"
 	"github.com/fluxcd/pkg/tar"
 	"github.com/go-logr/logr"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hclparse"
 	"github.com/hashicorp/terraform-exec/tfexec"
 	tfjson "github.com/hashicorp/terraform-json"
 	"google.golang.org/grpc/codes"
@@ -54,6 +56,11 @@ type TerraformRunnerServer struct {
 	Done       chan os.Signal
 	terraform  *infrav1.Terraform
 	InstanceID string
+	// envs are the environment variables of the terraform commands,
+	// kept for the commands which are not supported by tfexec.
+	envs map[string]string
+	// shardedStateAddress is the address of the sharded state backend, once started.
+	shardedStateAddress string
 }
 
 const loggerName = "runner.terraform"
@@ -121,6 +128,11 @@ func (r *TerraformRunnerServer) WriteBackendConfig(ctx context.Context, req *Wri
 		return nil, err
 	}
 
+	if err := validateBackendConfig(req.BackendConfig, backendConfigPath); err != nil {
+		log.Error(err, "invalid backend config")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	log.Info("write config to file", "filePath", filePath)
 	err = os.WriteFile(filePath, req.BackendConfig, 0644)
 	if err != nil {
@@ -131,6 +143,32 @@ func (r *TerraformRunnerServer) WriteBackendConfig(ctx context.Context, req *Wri
 	return &WriteBackendConfigReply{Message: "ok"}, nil
 }
 
+// validateBackendConfig rejects a backend config that is not valid HCL, or that contains
+// anything else than terraform blocks, before it reaches terraform init.
+func validateBackendConfig(config []byte, filename string) error {
+	file, diags := hclparse.NewParser().ParseHCL(config, filename)
+	if diags.HasErrors() {
+		return fmt.Errorf("malformed backend config: %s", diags.Error())
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return fmt.Errorf("malformed backend config: unexpected body type %T", file.Body)
+	}
+
+	for name := range body.Attributes {
+		return fmt.Errorf("malformed backend config: unexpected attribute %q, only terraform blocks are allowed", name)
+	}
+
+	for _, block := range body.Blocks {
+		if block.Type != "terraform" {
+			return fmt.Errorf("malformed backend config: unexpected block %q, only terraform blocks are allowed", block.Type)
+		}
+	}
+
+	return nil
+}
+
 func (r *TerraformRunnerServer) ProcessCliConfig(ctx context.Context, req *ProcessCliConfigRequest) (*ProcessCliConfigReply, error) {
 	log := ctrl.LoggerFrom(ctx, "instance-id", r.InstanceID).WithName(loggerName)
 	log.Info("processing configuration", "namespace", req.Namespace, "name", req.Name)
@@ -224,11 +262,25 @@ func (r *TerraformRunnerServer) SetEnv(ctx context.Context, req *SetEnvRequest)
 	for k, v := range req.Envs {
 		envs[k] = v
 	}
+
+	terraform := r.terraform
+	if usesShardedState(terraform.Spec.BackendConfig) || (terraform.Status.Backend != nil && usesShardedState(terraform.Status.Backend.Config)) {
+		address, err := r.startShardedStateBackend(ctx)
+		if err != nil {
+			log.Error(err, "unable to start the sharded state backend")
+			return nil, err
+		}
+		envs["TF_HTTP_ADDRESS"] = address
+		envs["TF_HTTP_LOCK_ADDRESS"] = address
+		envs["TF_HTTP_UNLOCK_ADDRESS"] = address
+	}
+
 	err := r.tf.SetEnv(envs)
 	if err != nil {
 		log.Error(err, "unable to set envvars", "envvars", envs)
 		return nil, err
 	}
+	r.envs = envs
 
 	return &SetEnvReply{Message: "ok"}, nil
 }
@@ -288,6 +340,18 @@ func (r *TerraformRunnerServer) SelectWorkspace(ctx context.Context, req *Worksp
 		return nil, err
 	}
 
+	// the http backend of the sharded state has no workspaces, the workspace is part of the name of the state
+	if !usesShardedState(r.terraform.Spec.BackendConfig) {
+		if err := r.selectWorkspace(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return &WorkspaceReply{Message: "ok"}, nil
+}
+
+func (r *TerraformRunnerServer) selectWorkspace(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName(loggerName)
 	terraform := r.terraform
 
 	if terraform.WorkspaceName() != infrav1.DefaultWorkspaceName {
@@ -299,11 +363,11 @@ func (r *TerraformRunnerServer) SelectWorkspace(ctx context.Context, req *Worksp
 		if err := r.tf.WorkspaceSelect(ctx, ws); err != nil {
 			err := fmt.Errorf("failed to select workspace %s", ws)
 			log.Error(err, "workspace select error")
-			return nil, err
+			return err
 		}
 	}
 
-	return &WorkspaceReply{Message: "ok"}, nil
+	return nil
 }
 
 func (r *TerraformRunnerServer) Destroy(ctx context.Context, req *DestroyRequest) (*DestroyReply, error) {
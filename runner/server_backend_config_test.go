@@ -0,0 +1,37 @@
+package runner
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_validateBackendConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(validateBackendConfig([]byte(`
+terraform {
+  backend "s3" {
+    bucket      = "my-bucket"
+    key         = "state.tfstate"
+    assume_role = { role_arn = "arn:aws:iam::123456789012:role/terraform" }
+  }
+}
+`), "backend_override.tf")).To(Succeed())
+
+	g.Expect(validateBackendConfig([]byte(`
+terraform {
+  backend "s3" {
+    bucket = "my-bucket
+  }
+}
+`), "backend_override.tf")).To(MatchError(ContainSubstring("malformed backend config")))
+
+	g.Expect(validateBackendConfig([]byte(`
+terraform {
+  backend "local" {}
+}
+
+resource "null_resource" "injected" {}
+`), "backend_override.tf")).To(MatchError(ContainSubstring(`unexpected block "resource"`)))
+}
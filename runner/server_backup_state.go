@@ -0,0 +1,83 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+// BackupState takes a snapshot of the state before an apply, and deletes the oldest
+// snapshots beyond the retention of the Terraform object.
+func (r *TerraformRunnerServer) BackupState(ctx context.Context, req *BackupStateRequest) (*BackupStateReply, error) {
+	log := ctrl.LoggerFrom(ctx, "instance-id", r.InstanceID).WithName(loggerName)
+	log.Info("backing up the state")
+	if req.TfInstance != r.InstanceID {
+		err := fmt.Errorf("no TF instance found")
+		log.Error(err, "no terraform")
+		return nil, err
+	}
+
+	state, err := r.pullState(ctx)
+	if err != nil {
+		log.Error(err, "unable to pull the state")
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if len(state) == 0 {
+		return &BackupStateReply{Message: "no state to back up"}, nil
+	}
+
+	resources, err := countStateResources(state)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	name := stateSnapshotName(r.terraform, time.Now())
+	if err := r.saveStateSnapshot(ctx, name, infrav1.StateBackupTriggerApply, req.Revision, state); err != nil {
+		log.Error(err, "unable to save the state snapshot")
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	store, err := newStateBackupStore(r.Client, r.terraform.Spec.StateBackup)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := pruneStateSnapshots(ctx, store, r.terraform, r.terraform.Spec.StateBackup.GetRetention()); err != nil {
+		log.Error(err, "unable to delete the old state snapshots")
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &BackupStateReply{
+		Message:      fmt.Sprintf("saved state snapshot %s with %d resources", name, resources),
+		SnapshotName: name,
+		Resources:    int32(resources),
+	}, nil
+}
+
+// saveStateSnapshot saves the raw state in the state backup store of the Terraform object.
+func (r *TerraformRunnerServer) saveStateSnapshot(ctx context.Context, name, trigger, revision string, state []byte) error {
+	store, err := newStateBackupStore(r.Client, r.terraform.Spec.StateBackup)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := parseState(state)
+	if err != nil {
+		return err
+	}
+
+	return store.Save(ctx, r.terraform, StateSnapshot{
+		Name:      name,
+		Workspace: r.terraform.WorkspaceName(),
+		Trigger:   trigger,
+		Revision:  revision,
+		Serial:    parsed.Serial,
+		CreatedAt: time.Now(),
+		State:     state,
+	})
+}
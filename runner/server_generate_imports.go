@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+const (
+	generatedImportsFile = "generated.imports.tf"
+	generatedMovesFile   = "generated.moves.tf"
+)
+
+// writeImportsAndMoves renders the pending imports and moves of the spec as import and moved blocks
+// in the working directory, next to the generated variables. Imports and moves already applied are
+// not rendered, so that they drop out of the configuration once they are part of the state.
+func writeImportsAndMoves(workingDir string, terraform infrav1.Terraform) error {
+	imports, err := renderImportBlocks(terraform.PendingImports())
+	if err != nil {
+		return err
+	}
+	if err := writeGeneratedFile(filepath.Join(workingDir, generatedImportsFile), imports); err != nil {
+		return err
+	}
+
+	moves, err := renderMovedBlocks(terraform.PendingMoves())
+	if err != nil {
+		return err
+	}
+	return writeGeneratedFile(filepath.Join(workingDir, generatedMovesFile), moves)
+}
+
+func writeGeneratedFile(path string, content []byte) error {
+	if len(content) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("error generating %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func renderImportBlocks(imports []infrav1.ImportSpec) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, i := range imports {
+		if err := validateAddress(i.Address); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "import {\n  to = %s\n  id = %s\n}\n\n", i.Address, quoteHCLString(i.ID))
+	}
+	return buf.Bytes(), nil
+}
+
+func renderMovedBlocks(moves []infrav1.MoveSpec) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, m := range moves {
+		if err := validateAddress(m.From); err != nil {
+			return nil, err
+		}
+		if err := validateAddress(m.To); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "moved {\n  from = %s\n  to   = %s\n}\n\n", m.From, m.To)
+	}
+	return buf.Bytes(), nil
+}
+
+// resourceAddressPattern matches the Terraform resource and module addresses, e.g.
+// module.app["eu"].aws_s3_bucket.data[0], where each name may be followed by an instance key.
+// An address matching it cannot hold a comment, a template sequence or a line break.
+var resourceAddressPattern = regexp.MustCompile(
+	`^[A-Za-z_][A-Za-z0-9_-]*(\[(\d+|"([^"\\\n\r{}]|\\["\\])*")\])*` +
+		`(\.[A-Za-z_][A-Za-z0-9_-]*(\[(\d+|"([^"\\\n\r{}]|\\["\\])*")\])*)*$`)
+
+// validateAddress rejects the addresses that do not follow the Terraform address grammar,
+// as they would break out of the generated block.
+func validateAddress(address string) error {
+	if !resourceAddressPattern.MatchString(address) {
+		return fmt.Errorf("invalid resource address %q", address)
+	}
+	return nil
+}
+
+// hclStringEscaper escapes a string with the HCL escape sequences, and escapes the
+// template sequences so that the value is used verbatim.
+var hclStringEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+	"${", "$${",
+	"%{", "%%{",
+)
+
+// quoteHCLString quotes a string as an HCL string literal.
+func quoteHCLString(s string) string {
+	return `"` + hclStringEscaper.Replace(s) + `"`
+}
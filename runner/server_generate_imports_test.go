@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+func TestWriteImportsAndMoves(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dir := t.TempDir()
+	terraform := infrav1.Terraform{
+		Spec: infrav1.TerraformSpec{
+			Imports: []infrav1.ImportSpec{
+				{Address: "aws_s3_bucket.logs", ID: "my-logs"},
+				{Address: `aws_s3_bucket.data["eu"]`, ID: "data-${region}"},
+			},
+			Moves: []infrav1.MoveSpec{
+				{From: "aws_s3_bucket.bucket", To: "module.storage.aws_s3_bucket.bucket"},
+			},
+		},
+		Status: infrav1.TerraformStatus{
+			AppliedImports: []infrav1.ImportSpec{
+				{Address: "aws_s3_bucket.logs", ID: "my-logs"},
+			},
+		},
+	}
+
+	// rendering only the pending imports, escaping template sequences
+	g.Expect(writeImportsAndMoves(dir, terraform)).To(Succeed())
+	imports, err := os.ReadFile(filepath.Join(dir, generatedImportsFile))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(imports)).To(Equal("import {\n  to = aws_s3_bucket.data[\"eu\"]\n  id = \"data-$${region}\"\n}\n\n"))
+
+	moves, err := os.ReadFile(filepath.Join(dir, generatedMovesFile))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(moves)).To(Equal("moved {\n  from = aws_s3_bucket.bucket\n  to   = module.storage.aws_s3_bucket.bucket\n}\n\n"))
+
+	// dropping the files once everything is applied
+	terraform.Status.AppliedImports = terraform.Spec.Imports
+	terraform.Status.AppliedMoves = terraform.Spec.Moves
+	g.Expect(writeImportsAndMoves(dir, terraform)).To(Succeed())
+	g.Expect(filepath.Join(dir, generatedImportsFile)).ToNot(BeAnExistingFile())
+	g.Expect(filepath.Join(dir, generatedMovesFile)).ToNot(BeAnExistingFile())
+
+	// rejecting an address breaking out of the block
+	terraform.Spec.Moves = []infrav1.MoveSpec{{From: "a.b\n}\nresource", To: "a.c"}}
+	g.Expect(writeImportsAndMoves(dir, terraform)).To(MatchError(ContainSubstring("invalid resource address")))
+}
+
+func TestValidateAddress(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, address := range []string{
+		"aws_s3_bucket.bucket",
+		"data.aws_iam_policy.admin",
+		"module.storage",
+		"aws_instance.web[0]",
+		`module.app["eu-west-1"].aws_s3_bucket.data["logs/2024"]`,
+		`aws_s3_bucket.data["say \"hi\""]`,
+	} {
+		g.Expect(validateAddress(address)).To(Succeed(), address)
+	}
+
+	for _, address := range []string{
+		"",
+		" ",
+		"aws_s3_bucket.bucket // comment",
+		"aws_s3_bucket.bucket/* comment */",
+		"aws_s3_bucket.bucket # comment",
+		"aws_s3_bucket..bucket",
+		"aws_s3_bucket.bucket\n}",
+		`aws_s3_bucket.data["${var.name}"]`,
+		`aws_s3_bucket.data["unterminated]`,
+		"aws_s3_bucket.data[var.key]",
+	} {
+		g.Expect(validateAddress(address)).To(MatchError(ContainSubstring("invalid resource address")), address)
+	}
+}
+
+func TestQuoteHCLString(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(quoteHCLString("my-bucket")).To(Equal(`"my-bucket"`))
+	g.Expect(quoteHCLString(`C:\path "quoted"`)).To(Equal(`"C:\\path \"quoted\""`))
+	g.Expect(quoteHCLString("${a} %{b}")).To(Equal(`"$${a} %%{b}"`))
+	g.Expect(quoteHCLString("line\nbreak")).To(Equal(`"line\nbreak"`))
+	// the other characters are kept as is, as HCL has no \x escapes
+	g.Expect(quoteHCLString("café\x7f")).To(Equal("\"café\x7f\""))
+}
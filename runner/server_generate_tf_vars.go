@@ -357,6 +357,12 @@ func (r *TerraformRunnerServer) GenerateVarsForTF(ctx context.Context, req *Gene
 		return nil, err
 	}
 
+	log.Info("rendering the Spec.Imports and Spec.Moves")
+	if err := writeImportsAndMoves(req.WorkingDir, terraform); err != nil {
+		log.Error(err, "unable to render the import and moved blocks")
+		return nil, err
+	}
+
 	return &GenerateVarsForTFReply{Message: "ok"}, nil
 }
 
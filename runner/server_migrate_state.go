@@ -0,0 +1,98 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+// MigrateState moves the state from the old backend to the new backend. The state is pulled from the
+// old backend and backed up in a Secret first, then terraform init copies it to the new backend,
+// and the number of resources in the new backend is checked against the old one.
+func (r *TerraformRunnerServer) MigrateState(ctx context.Context, req *MigrateStateRequest) (*MigrateStateReply, error) {
+	log := ctrl.LoggerFrom(ctx, "instance-id", r.InstanceID).WithName(loggerName)
+	log.Info("migrating state")
+	if req.TfInstance != r.InstanceID {
+		err := fmt.Errorf("no TF instance found")
+		log.Error(err, "no terraform")
+		return nil, err
+	}
+
+	log.Info("initializing the old backend")
+	if _, err := r.WriteBackendConfig(ctx, &WriteBackendConfigRequest{DirPath: req.DirPath, BackendConfig: req.OldBackendConfig}); err != nil {
+		return nil, err
+	}
+	if _, err := r.Init(ctx, &InitRequest{TfInstance: req.TfInstance, Upgrade: true}); err != nil {
+		log.Error(err, "unable to initialize the old backend")
+		return nil, err
+	}
+	if r.terraform.Status.Backend == nil || !usesShardedState(r.terraform.Status.Backend.Config) {
+		if err := r.selectWorkspace(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	state, err := r.pullState(ctx)
+	if err != nil {
+		log.Error(err, "unable to pull the state from the old backend")
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	before, err := countStateResources(state)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	backupSecretName := ""
+	if len(state) > 0 {
+		backupSecretName = req.BackupSecretName
+		if backupSecretName == "" {
+			backupSecretName = stateSnapshotName(r.terraform, time.Now())
+		}
+		log.Info("backing up the state", "secret", backupSecretName, "resources", before)
+		if err := r.saveStateSnapshot(ctx, backupSecretName, infrav1.StateBackupTriggerMigrateState, "", state); err != nil {
+			log.Error(err, "unable to back up the state")
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	log.Info("initializing the new backend with state migration")
+	if _, err := r.WriteBackendConfig(ctx, &WriteBackendConfigRequest{DirPath: req.DirPath, BackendConfig: req.NewBackendConfig}); err != nil {
+		return nil, err
+	}
+	if _, err := r.Init(ctx, &InitRequest{TfInstance: req.TfInstance, Upgrade: true, ForceCopy: true}); err != nil {
+		log.Error(err, "unable to migrate the state to the new backend")
+		return nil, err
+	}
+	if _, err := r.SelectWorkspace(ctx, &WorkspaceRequest{TfInstance: req.TfInstance}); err != nil {
+		return nil, err
+	}
+
+	state, err = r.pullState(ctx)
+	if err != nil {
+		log.Error(err, "unable to pull the state from the new backend")
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	after, err := countStateResources(state)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if before != after {
+		err := fmt.Errorf("state migration verification failed: %d resources before the migration, %d after", before, after)
+		log.Error(err, "resource count mismatch", "backup", backupSecretName)
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return &MigrateStateReply{
+		Message:          fmt.Sprintf("migrated %d resources to the new backend", after),
+		BackupSecretName: backupSecretName,
+		ResourcesBefore:  int32(before),
+		ResourcesAfter:   int32(after),
+	}, nil
+}
@@ -175,6 +175,7 @@ func (r *TerraformRunnerServer) Plan(ctx context.Context, req *PlanRequest) (*Pl
 	}
 
 	planCreated := false
+	var toAdd, toChange, toDestroy int32
 	if req.Out != "" {
 		planCreated = true
 
@@ -192,7 +193,37 @@ func (r *TerraformRunnerServer) Plan(ctx context.Context, req *PlanRequest) (*Pl
 			planCreated = false
 		}
 
+		toAdd, toChange, toDestroy = countResourceChanges(plan.ResourceChanges)
 	}
 
-	return &PlanReply{Message: "ok", Drifted: drifted, PlanCreated: planCreated}, nil
+	return &PlanReply{
+		Message:     "ok",
+		Drifted:     drifted,
+		PlanCreated: planCreated,
+		ToAdd:       toAdd,
+		ToChange:    toChange,
+		ToDestroy:   toDestroy,
+	}, nil
+}
+
+// countResourceChanges counts the resources to add, change and destroy like the plan summary of terraform,
+// where a replaced resource is both added and destroyed.
+func countResourceChanges(changes []*tfjson.ResourceChange) (toAdd, toChange, toDestroy int32) {
+	for _, rc := range changes {
+		if rc == nil || rc.Change == nil {
+			continue
+		}
+		switch {
+		case rc.Change.Actions.Replace():
+			toAdd++
+			toDestroy++
+		case rc.Change.Actions.Create():
+			toAdd++
+		case rc.Change.Actions.Update():
+			toChange++
+		case rc.Change.Actions.Delete():
+			toDestroy++
+		}
+	}
+	return toAdd, toChange, toDestroy
 }
@@ -2,6 +2,8 @@ package runner
 
 import (
 	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
 )
 
 func TestSanitizeLog(t *testing.T) {
@@ -48,3 +50,23 @@ func TestSanitizeLog(t *testing.T) {
 		})
 	}
 }
+
+func TestCountResourceChanges(t *testing.T) {
+	change := func(actions ...tfjson.Action) *tfjson.ResourceChange {
+		return &tfjson.ResourceChange{Change: &tfjson.Change{Actions: actions}}
+	}
+
+	toAdd, toChange, toDestroy := countResourceChanges([]*tfjson.ResourceChange{
+		change(tfjson.ActionCreate),
+		change(tfjson.ActionCreate),
+		change(tfjson.ActionUpdate),
+		change(tfjson.ActionDelete),
+		change(tfjson.ActionDelete, tfjson.ActionCreate),
+		change(tfjson.ActionNoop),
+		change(tfjson.ActionRead),
+	})
+
+	if toAdd != 3 || toChange != 1 || toDestroy != 2 {
+		t.Errorf("countResourceChanges() = %d to add, %d to change, %d to destroy, want 3, 1, 2", toAdd, toChange, toDestroy)
+	}
+}
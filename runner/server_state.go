@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// runTerraformCommand runs the terraform commands which are not supported by tfexec,
+// in the working directory and with the environment variables of the terraform instance.
+func (r *TerraformRunnerServer) runTerraformCommand(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, r.tf.ExecPath(), args...)
+	cmd.Dir = r.tf.WorkingDir()
+	if r.envs != nil {
+		cmd.Env = []string{}
+		for k, v := range r.envs {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("terraform %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// pullState returns the raw state of the current workspace from the backend.
+func (r *TerraformRunnerServer) pullState(ctx context.Context) ([]byte, error) {
+	return r.runTerraformCommand(ctx, "state", "pull")
+}
+
+type rawState struct {
+	Serial    int64 `json:"serial"`
+	Resources []struct {
+		Instances []json.RawMessage `json:"instances"`
+	} `json:"resources"`
+}
+
+func parseState(state []byte) (rawState, error) {
+	var s rawState
+	if len(bytes.TrimSpace(state)) == 0 {
+		return s, nil
+	}
+	if err := json.Unmarshal(state, &s); err != nil {
+		return s, fmt.Errorf("unable to parse the state: %w", err)
+	}
+	return s, nil
+}
+
+// countStateResources returns the number of resource instances in a raw state.
+func countStateResources(state []byte) (int, error) {
+	s, err := parseState(state)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, resource := range s.Resources {
+		count += len(resource.Instances)
+	}
+	return count, nil
+}
@@ -0,0 +1,126 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// stateOperationLogger returns the logger of a state operation. Every state operation is logged
+// with the user who requested it, before it is executed, so that the runner logs can be audited.
+// The arguments are given to terraform as is, so an argument which would be taken as a flag is refused.
+func (r *TerraformRunnerServer) stateOperationLogger(ctx context.Context, tfInstance string, requestedBy string, operation string, args ...string) (logr.Logger, error) {
+	log := ctrl.LoggerFrom(ctx, "instance-id", r.InstanceID).WithName(loggerName)
+	if tfInstance != r.InstanceID {
+		err := fmt.Errorf("no TF instance found")
+		log.Error(err, "no terraform")
+		return log, err
+	}
+
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			err := fmt.Errorf("invalid argument %q, state operations do not accept flags", arg)
+			log.Error(err, "refusing state operation", "operation", operation, "requested-by", requestedBy)
+			return log, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	log = log.WithValues("operation", operation, "args", args, "requested-by", requestedBy)
+	log.Info("executing state operation")
+	return log, nil
+}
+
+// StateList lists the resources in the state, optionally filtered by addresses.
+func (r *TerraformRunnerServer) StateList(ctx context.Context, req *StateListRequest) (*StateListReply, error) {
+	log, err := r.stateOperationLogger(ctx, req.TfInstance, req.RequestedBy, "state list", req.Addresses...)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"state", "list"}, req.Addresses...)
+	out, err := r.runTerraformCommand(ctx, args...)
+	if err != nil {
+		log.Error(err, "unable to list the state")
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	count := len(strings.Fields(string(out)))
+	return &StateListReply{
+		Message: fmt.Sprintf("listed %d resources", count),
+		Output:  string(out),
+	}, nil
+}
+
+// StateShow shows the attributes of a resource in the state.
+func (r *TerraformRunnerServer) StateShow(ctx context.Context, req *StateShowRequest) (*StateShowReply, error) {
+	log, err := r.stateOperationLogger(ctx, req.TfInstance, req.RequestedBy, "state show", req.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := r.runTerraformCommand(ctx, "state", "show", "-no-color", req.Address)
+	if err != nil {
+		log.Error(err, "unable to show the resource")
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &StateShowReply{
+		Message: fmt.Sprintf("showed %s", req.Address),
+		Output:  string(out),
+	}, nil
+}
+
+// StateMv moves a resource to another address in the state.
+func (r *TerraformRunnerServer) StateMv(ctx context.Context, req *StateMvRequest) (*StateMvReply, error) {
+	log, err := r.stateOperationLogger(ctx, req.TfInstance, req.RequestedBy, "state mv", req.Source, req.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.tf.StateMv(ctx, req.Source, req.Destination); err != nil {
+		log.Error(err, "unable to move the resource")
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	log.Info("moved resource in the state")
+	return &StateMvReply{Message: fmt.Sprintf("moved %s to %s", req.Source, req.Destination)}, nil
+}
+
+// StateRm removes resources from the state, without destroying them.
+func (r *TerraformRunnerServer) StateRm(ctx context.Context, req *StateRmRequest) (*StateRmReply, error) {
+	log, err := r.stateOperationLogger(ctx, req.TfInstance, req.RequestedBy, "state rm", req.Addresses...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, address := range req.Addresses {
+		if err := r.tf.StateRm(ctx, address); err != nil {
+			log.Error(err, "unable to remove the resource", "address", address)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	log.Info("removed resources from the state")
+	return &StateRmReply{Message: fmt.Sprintf("removed %s", strings.Join(req.Addresses, ", "))}, nil
+}
+
+// Import imports an existing resource into the state.
+func (r *TerraformRunnerServer) Import(ctx context.Context, req *ImportRequest) (*ImportReply, error) {
+	log, err := r.stateOperationLogger(ctx, req.TfInstance, req.RequestedBy, "import", req.Address, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.tf.Import(ctx, req.Address, req.Id); err != nil {
+		log.Error(err, "unable to import the resource")
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	log.Info("imported resource into the state")
+	return &ImportReply{Message: fmt.Sprintf("imported %s as %s", req.Id, req.Address)}, nil
+}
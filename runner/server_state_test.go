@@ -0,0 +1,29 @@
+package runner
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_countStateResources(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	count, err := countStateResources(nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(count).To(Equal(0))
+
+	count, err = countStateResources([]byte(`{
+  "version": 4,
+  "resources": [
+    {"mode": "managed", "type": "null_resource", "name": "single", "instances": [{"attributes": {}}]},
+    {"mode": "managed", "type": "null_resource", "name": "counted", "instances": [{"index_key": 0}, {"index_key": 1}]},
+    {"mode": "data", "type": "http", "name": "example", "instances": [{"attributes": {}}]}
+  ]
+}`))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(count).To(Equal(4))
+
+	_, err = countStateResources([]byte(`not a state`))
+	g.Expect(err).To(MatchError(ContainSubstring("unable to parse the state")))
+}
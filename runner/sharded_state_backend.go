@@ -0,0 +1,362 @@
+package runner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/utils"
+)
+
+const (
+	// shardedStateShardSize is the size of the gzipped state stored in each shard Secret,
+	// well below the size limit of a Secret.
+	shardedStateShardSize = 512 * 1024
+
+	ShardedStateShardsAnnotation   = "infra.contrib.fluxcd.io/state-shards"
+	ShardedStateChecksumAnnotation = "infra.contrib.fluxcd.io/state-checksum"
+	ShardedStateLockInfoAnnotation = "infra.contrib.fluxcd.io/lock-info"
+	ShardedStateShardOfLabel       = "infra.contrib.fluxcd.io/state-shard-of"
+
+	lockMethod   = "LOCK"
+	unlockMethod = "UNLOCK"
+)
+
+// usesShardedState returns true when the backend config enables the sharded kubernetes backend.
+func usesShardedState(spec *infrav1.BackendConfigSpec) bool {
+	return spec != nil && spec.Sharded
+}
+
+// shardedStateBackend serves the state of a Terraform object to Terraform with the protocol of the http backend.
+// The state is gzipped and split across shard Secrets. A head Secret lists the shards and the checksum of the
+// gzipped state, and is updated after the shards are written, so that a failed write leaves the previous state intact.
+// The lock is held in a Lease, like with the kubernetes backend.
+type shardedStateBackend struct {
+	client client.Client
+	// terraform returns the Terraform object which the state belongs to.
+	terraform func() *infrav1.Terraform
+}
+
+func (b *shardedStateBackend) headKey() types.NamespacedName {
+	terraform := b.terraform()
+	suffix := terraform.Name
+	if terraform.Spec.BackendConfig != nil && terraform.Spec.BackendConfig.SecretSuffix != "" {
+		suffix = terraform.Spec.BackendConfig.SecretSuffix
+	}
+	return types.NamespacedName{
+		Namespace: terraform.Namespace,
+		Name:      fmt.Sprintf("tfstate-sharded-%s-%s", terraform.WorkspaceName(), suffix),
+	}
+}
+
+func (b *shardedStateBackend) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	log := ctrl.LoggerFrom(ctx).WithName(loggerName)
+
+	var err error
+	switch req.Method {
+	case http.MethodGet:
+		var state []byte
+		state, err = b.get(ctx)
+		if err == nil && state == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err == nil {
+			w.Header().Set("Content-Type", "application/json")
+			_, err = w.Write(state)
+		}
+	case http.MethodPost:
+		var state []byte
+		state, err = io.ReadAll(req.Body)
+		if err == nil {
+			err = b.put(ctx, state)
+		}
+	case http.MethodDelete:
+		err = b.delete(ctx)
+	case lockMethod:
+		var lockInfo []byte
+		lockInfo, err = io.ReadAll(req.Body)
+		if err == nil {
+			var current []byte
+			current, err = b.lock(ctx, lockInfo)
+			if err == nil && current != nil {
+				w.WriteHeader(http.StatusLocked)
+				_, err = w.Write(current)
+				return
+			}
+		}
+	case unlockMethod:
+		var lockInfo []byte
+		lockInfo, err = io.ReadAll(req.Body)
+		if err == nil {
+			err = b.unlock(ctx, lockInfo)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err != nil {
+		log.Error(err, "sharded state backend error", "method", req.Method)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// get reassembles the state from its shards. It returns nil when there is no state.
+func (b *shardedStateBackend) get(ctx context.Context) ([]byte, error) {
+	head := &corev1.Secret{}
+	if err := b.client.Get(ctx, b.headKey(), head); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	encoded := []byte{}
+	for _, name := range shardNames(head) {
+		shard := &corev1.Secret{}
+		if err := b.client.Get(ctx, types.NamespacedName{Namespace: head.Namespace, Name: name}, shard); err != nil {
+			return nil, fmt.Errorf("unable to get state shard %s: %w", name, err)
+		}
+		encoded = append(encoded, shard.Data[infrav1.StateBackupDataKey]...)
+	}
+
+	sum := sha256.Sum256(encoded)
+	if checksum := head.Annotations[ShardedStateChecksumAnnotation]; checksum != hex.EncodeToString(sum[:]) {
+		return nil, fmt.Errorf("checksum mismatch of the state in %s", head.Name)
+	}
+
+	return utils.GzipDecode(encoded)
+}
+
+// put writes the shards of the state, then the head Secret, then deletes the shards of the previous state.
+func (b *shardedStateBackend) put(ctx context.Context, state []byte) error {
+	key := b.headKey()
+	encoded, err := utils.GzipEncode(state)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(encoded)
+	checksum := hex.EncodeToString(sum[:])
+
+	names := []string{}
+	for i := 0; i*shardedStateShardSize < len(encoded); i++ {
+		end := (i + 1) * shardedStateShardSize
+		if end > len(encoded) {
+			end = len(encoded)
+		}
+		name := fmt.Sprintf("%s-%s-%d", key.Name, checksum[:10], i)
+		shard := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: key.Namespace,
+				Labels:    map[string]string{ShardedStateShardOfLabel: key.Name},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{infrav1.StateBackupDataKey: encoded[i*shardedStateShardSize : end]},
+		}
+		if err := b.client.Create(ctx, shard); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("unable to create state shard %s: %w", name, err)
+		}
+		names = append(names, name)
+	}
+
+	head := &corev1.Secret{}
+	previous := []string{}
+	if err := b.client.Get(ctx, key, head); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		head = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+			},
+			Type: corev1.SecretTypeOpaque,
+		}
+	} else {
+		previous = shardNames(head)
+	}
+
+	if head.Annotations == nil {
+		head.Annotations = map[string]string{}
+	}
+	head.Annotations[ShardedStateShardsAnnotation] = strings.Join(names, ",")
+	head.Annotations[ShardedStateChecksumAnnotation] = checksum
+
+	if head.ResourceVersion == "" {
+		err = b.client.Create(ctx, head)
+	} else {
+		err = b.client.Update(ctx, head)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write state head %s: %w", key.Name, err)
+	}
+
+	current := map[string]bool{}
+	for _, name := range names {
+		current[name] = true
+	}
+	for _, name := range previous {
+		if !current[name] {
+			if err := b.deleteShard(ctx, key.Namespace, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *shardedStateBackend) delete(ctx context.Context) error {
+	key := b.headKey()
+	head := &corev1.Secret{}
+	if err := b.client.Get(ctx, key, head); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if err := b.client.Delete(ctx, head); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	for _, name := range shardNames(head) {
+		if err := b.deleteShard(ctx, key.Namespace, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *shardedStateBackend) deleteShard(ctx context.Context, namespace, name string) error {
+	shard := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if err := b.client.Delete(ctx, shard); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete state shard %s: %w", name, err)
+	}
+	return nil
+}
+
+type lockInfo struct {
+	ID string `json:"ID"`
+}
+
+// lock takes the lock. When the lock is held by another operation, it returns the info of that lock.
+func (b *shardedStateBackend) lock(ctx context.Context, info []byte) ([]byte, error) {
+	requested := lockInfo{}
+	if err := json.Unmarshal(info, &requested); err != nil {
+		return nil, fmt.Errorf("invalid lock info: %w", err)
+	}
+
+	key := b.headKey()
+	key.Name = "lock-" + key.Name
+	lease := &coordinationv1.Lease{}
+	if err := b.client.Get(ctx, key, lease); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        key.Name,
+				Namespace:   key.Namespace,
+				Annotations: map[string]string{ShardedStateLockInfoAnnotation: string(info)},
+			},
+			Spec: coordinationv1.LeaseSpec{HolderIdentity: &requested.ID},
+		}
+		if err := b.client.Create(ctx, lease); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				return b.lock(ctx, info)
+			}
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	if lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity != "" && *lease.Spec.HolderIdentity != requested.ID {
+		return []byte(lease.Annotations[ShardedStateLockInfoAnnotation]), nil
+	}
+
+	if lease.Annotations == nil {
+		lease.Annotations = map[string]string{}
+	}
+	lease.Annotations[ShardedStateLockInfoAnnotation] = string(info)
+	lease.Spec.HolderIdentity = &requested.ID
+	// the update fails on a concurrent change of the lease, so the lock is never taken twice
+	return nil, b.client.Update(ctx, lease)
+}
+
+// unlock releases the lock. An unlock without lock ID, as sent on force-unlock, releases any lock.
+func (b *shardedStateBackend) unlock(ctx context.Context, info []byte) error {
+	requested := lockInfo{}
+	if len(info) > 0 {
+		if err := json.Unmarshal(info, &requested); err != nil {
+			return fmt.Errorf("invalid lock info: %w", err)
+		}
+	}
+
+	key := b.headKey()
+	key.Name = "lock-" + key.Name
+	lease := &coordinationv1.Lease{}
+	if err := b.client.Get(ctx, key, lease); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if requested.ID != "" && lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity != requested.ID {
+		return fmt.Errorf("lock %s is held by %s", requested.ID, *lease.Spec.HolderIdentity)
+	}
+
+	lease.Spec.HolderIdentity = nil
+	delete(lease.Annotations, ShardedStateLockInfoAnnotation)
+	return b.client.Update(ctx, lease)
+}
+
+func shardNames(head *corev1.Secret) []string {
+	shards := head.Annotations[ShardedStateShardsAnnotation]
+	if shards == "" {
+		return nil
+	}
+	return strings.Split(shards, ",")
+}
+
+// startShardedStateBackend starts serving the sharded state on the loopback interface, once per runner,
+// and returns the address of the http backend.
+func (r *TerraformRunnerServer) startShardedStateBackend(ctx context.Context) (string, error) {
+	if r.shardedStateAddress != "" {
+		return r.shardedStateAddress, nil
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("unable to listen for the sharded state backend: %w", err)
+	}
+
+	backend := &shardedStateBackend{
+		client: r.Client,
+		terraform: func() *infrav1.Terraform {
+			return r.terraform
+		},
+	}
+	server := &http.Server{
+		Handler: backend,
+		BaseContext: func(net.Listener) context.Context {
+			return ctrl.LoggerInto(context.Background(), ctrl.LoggerFrom(ctx))
+		},
+	}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	r.shardedStateAddress = fmt.Sprintf("http://%s/state", listener.Addr().String())
+	return r.shardedStateAddress, nil
+}
@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+func Test_shardedStateBackend(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(coordinationv1.AddToScheme(scheme)).To(Succeed())
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	terraform := &infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-resource", Namespace: "default"},
+		Spec: infrav1.TerraformSpec{
+			Workspace:     "dev",
+			BackendConfig: &infrav1.BackendConfigSpec{Sharded: true},
+		},
+	}
+	srv := httptest.NewServer(&shardedStateBackend{
+		client:    kubeClient,
+		terraform: func() *infrav1.Terraform { return terraform },
+	})
+	defer srv.Close()
+
+	do := func(method string, body []byte) (int, []byte) {
+		req, err := http.NewRequest(method, srv.URL, bytes.NewReader(body))
+		g.Expect(err).ToNot(HaveOccurred())
+		resp, err := http.DefaultClient.Do(req)
+		g.Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		g.Expect(err).ToNot(HaveOccurred())
+		return resp.StatusCode, respBody
+	}
+
+	shards := func() int {
+		secrets := &corev1.SecretList{}
+		g.Expect(kubeClient.List(context.TODO(), secrets, client.MatchingLabels{ShardedStateShardOfLabel: "tfstate-sharded-dev-my-resource"})).To(Succeed())
+		return len(secrets.Items)
+	}
+
+	// reporting no state
+	code, _ := do(http.MethodGet, nil)
+	g.Expect(code).To(Equal(http.StatusNotFound))
+
+	// splitting a large state across shards
+	random := make([]byte, 2*shardedStateShardSize)
+	_, err := rand.Read(random)
+	g.Expect(err).ToNot(HaveOccurred())
+	large := []byte(`{"serial": 1, "data": "` + base64.StdEncoding.EncodeToString(random) + `"}`)
+
+	code, _ = do(http.MethodPost, large)
+	g.Expect(code).To(Equal(http.StatusOK))
+	g.Expect(shards()).To(BeNumerically(">", 2))
+
+	code, body := do(http.MethodGet, nil)
+	g.Expect(code).To(Equal(http.StatusOK))
+	g.Expect(body).To(Equal(large))
+
+	// replacing the shards of the previous state
+	small := []byte(`{"serial": 2}`)
+	code, _ = do(http.MethodPost, small)
+	g.Expect(code).To(Equal(http.StatusOK))
+	g.Expect(shards()).To(Equal(1))
+
+	code, body = do(http.MethodGet, nil)
+	g.Expect(code).To(Equal(http.StatusOK))
+	g.Expect(body).To(Equal(small))
+
+	// locking the state
+	code, _ = do(lockMethod, []byte(`{"ID": "lock-1"}`))
+	g.Expect(code).To(Equal(http.StatusOK))
+
+	code, body = do(lockMethod, []byte(`{"ID": "lock-2"}`))
+	g.Expect(code).To(Equal(http.StatusLocked))
+	g.Expect(string(body)).To(ContainSubstring("lock-1"))
+
+	code, _ = do(unlockMethod, []byte(`{"ID": "lock-2"}`))
+	g.Expect(code).To(Equal(http.StatusInternalServerError))
+
+	code, _ = do(unlockMethod, []byte(`{"ID": "lock-1"}`))
+	g.Expect(code).To(Equal(http.StatusOK))
+
+	code, _ = do(lockMethod, []byte(`{"ID": "lock-2"}`))
+	g.Expect(code).To(Equal(http.StatusOK))
+
+	// deleting the state
+	code, _ = do(http.MethodDelete, nil)
+	g.Expect(code).To(Equal(http.StatusOK))
+	g.Expect(shards()).To(Equal(0))
+}
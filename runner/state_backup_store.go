@@ -0,0 +1,179 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/flux-iac/tofu-controller/utils"
+)
+
+// StateSnapshot is a copy of the state of a Terraform object taken at a point in time.
+type StateSnapshot struct {
+	Name      string
+	Workspace string
+	Trigger   string
+	Revision  string
+	Serial    int64
+	CreatedAt time.Time
+	// State is the raw state. It is not loaded by List.
+	State []byte
+}
+
+// StateBackupStore keeps the state snapshots of the Terraform objects.
+// A new store is plugged in by implementing this interface and adding it to newStateBackupStore.
+type StateBackupStore interface {
+	// Save stores a new snapshot.
+	Save(ctx context.Context, terraform *infrav1.Terraform, snapshot StateSnapshot) error
+	// List returns the snapshots of the workspace of the Terraform object taken before the
+	// given operation, oldest first, without their state.
+	List(ctx context.Context, terraform *infrav1.Terraform, trigger string) ([]StateSnapshot, error)
+	// Delete removes a snapshot.
+	Delete(ctx context.Context, terraform *infrav1.Terraform, name string) error
+}
+
+func newStateBackupStore(c client.Client, spec *infrav1.StateBackupSpec) (StateBackupStore, error) {
+	switch store := spec.GetStore(); store {
+	case infrav1.StateBackupStoreSecret:
+		return &secretStateBackupStore{client: c}, nil
+	default:
+		return nil, fmt.Errorf("unsupported state backup store: %s", store)
+	}
+}
+
+// stateSnapshotName returns the name of a snapshot taken at the given time. The random suffix
+// keeps apart the snapshots taken within the same second.
+func stateSnapshotName(terraform *infrav1.Terraform, at time.Time) string {
+	return fmt.Sprintf("tfstate-backup-%s-%s-%s-%s", terraform.WorkspaceName(), terraform.Name, at.UTC().Format("20060102150405"), utilrand.String(5))
+}
+
+// pruneStateSnapshots deletes the oldest snapshots taken before an apply, keeping the given number of snapshots.
+func pruneStateSnapshots(ctx context.Context, store StateBackupStore, terraform *infrav1.Terraform, retention int) error {
+	snapshots, err := store.List(ctx, terraform, infrav1.StateBackupTriggerApply)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(snapshots)-retention; i++ {
+		if err := store.Delete(ctx, terraform, snapshots[i].Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// secretStateBackupStore stores each snapshot gzipped in a Secret in the namespace of the Terraform object.
+// The Secrets are not owned by the Terraform object, so that the snapshots survive its deletion.
+type secretStateBackupStore struct {
+	client client.Client
+}
+
+func (s *secretStateBackupStore) Save(ctx context.Context, terraform *infrav1.Terraform, snapshot StateSnapshot) error {
+	encoded, err := utils.GzipEncode(snapshot.State)
+	if err != nil {
+		return fmt.Errorf("unable to encode the state: %w", err)
+	}
+
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      snapshot.Name,
+			Namespace: terraform.Namespace,
+			Labels: map[string]string{
+				infrav1.StateBackupLabel:        infrav1.StateBackupLabelValue(terraform.Name),
+				infrav1.StateBackupTriggerLabel: snapshot.Trigger,
+			},
+			Annotations: map[string]string{
+				"encoding":                             "gzip",
+				infrav1.StateBackupNameAnnotation:      terraform.Name,
+				infrav1.StateBackupWorkspaceAnnotation: snapshot.Workspace,
+				infrav1.StateBackupRevisionAnnotation:  snapshot.Revision,
+				infrav1.StateBackupSerialAnnotation:    strconv.FormatInt(snapshot.Serial, 10),
+				infrav1.StateBackupCreatedAtAnnotation: snapshot.CreatedAt.UTC().Format(time.RFC3339),
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{infrav1.StateBackupDataKey: encoded},
+	}
+
+	if err := s.client.Create(ctx, &secret); err != nil {
+		return fmt.Errorf("unable to create the state snapshot secret %s: %w", snapshot.Name, err)
+	}
+	return nil
+}
+
+func (s *secretStateBackupStore) List(ctx context.Context, terraform *infrav1.Terraform, trigger string) ([]StateSnapshot, error) {
+	secrets := &corev1.SecretList{}
+	if err := s.client.List(ctx, secrets,
+		client.InNamespace(terraform.Namespace),
+		client.MatchingLabels{
+			infrav1.StateBackupLabel:        infrav1.StateBackupLabelValue(terraform.Name),
+			infrav1.StateBackupTriggerLabel: trigger,
+		},
+	); err != nil {
+		return nil, fmt.Errorf("unable to list the state snapshot secrets: %w", err)
+	}
+
+	snapshots := []StateSnapshot{}
+	for _, secret := range secrets.Items {
+		annotations := secret.GetAnnotations()
+		if !isStateSnapshotOf(secret, terraform) {
+			continue
+		}
+
+		createdAt, err := time.Parse(time.RFC3339, annotations[infrav1.StateBackupCreatedAtAnnotation])
+		if err != nil {
+			createdAt = secret.CreationTimestamp.Time
+		}
+		serial, _ := strconv.ParseInt(annotations[infrav1.StateBackupSerialAnnotation], 10, 64)
+
+		snapshots = append(snapshots, StateSnapshot{
+			Name:      secret.Name,
+			Workspace: annotations[infrav1.StateBackupWorkspaceAnnotation],
+			Trigger:   trigger,
+			Revision:  annotations[infrav1.StateBackupRevisionAnnotation],
+			Serial:    serial,
+			CreatedAt: createdAt,
+		})
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if snapshots[i].CreatedAt.Equal(snapshots[j].CreatedAt) {
+			return snapshots[i].Name < snapshots[j].Name
+		}
+		return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// isStateSnapshotOf returns true if the snapshot Secret belongs to the workspace of the Terraform object.
+// The name annotation tells apart the objects with the same truncated name in the label. The snapshots
+// taken before it was introduced have the full name in the label.
+func isStateSnapshotOf(secret corev1.Secret, terraform *infrav1.Terraform) bool {
+	annotations := secret.GetAnnotations()
+	if name, ok := annotations[infrav1.StateBackupNameAnnotation]; ok && name != terraform.Name {
+		return false
+	}
+	return annotations[infrav1.StateBackupWorkspaceAnnotation] == terraform.WorkspaceName()
+}
+
+func (s *secretStateBackupStore) Delete(ctx context.Context, terraform *infrav1.Terraform, name string) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: terraform.Namespace,
+		},
+	}
+	if err := s.client.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete the state snapshot secret %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+func Test_pruneStateSnapshots(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	terraform := &infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-resource", Namespace: "default"},
+		Spec: infrav1.TerraformSpec{
+			StateBackup: &infrav1.StateBackupSpec{Retention: 2},
+		},
+	}
+
+	store, err := newStateBackupStore(fake.NewClientBuilder().WithScheme(scheme).Build(), terraform.Spec.StateBackup)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		at := start.Add(time.Duration(i) * time.Hour)
+		g.Expect(store.Save(context.TODO(), terraform, StateSnapshot{
+			Name:      stateSnapshotName(terraform, at),
+			Workspace: terraform.WorkspaceName(),
+			Trigger:   infrav1.StateBackupTriggerApply,
+			Serial:    int64(i),
+			CreatedAt: at,
+			State:     []byte(`{"serial": 1}`),
+		})).To(Succeed())
+	}
+	g.Expect(store.Save(context.TODO(), terraform, StateSnapshot{
+		Name:      "tfstate-backup-default-my-resource-migration",
+		Workspace: terraform.WorkspaceName(),
+		Trigger:   infrav1.StateBackupTriggerMigrateState,
+		CreatedAt: start,
+		State:     []byte(`{"serial": 1}`),
+	})).To(Succeed())
+
+	g.Expect(pruneStateSnapshots(context.TODO(), store, terraform, terraform.Spec.StateBackup.GetRetention())).To(Succeed())
+
+	snapshots, err := store.List(context.TODO(), terraform, infrav1.StateBackupTriggerApply)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(snapshots).To(HaveLen(2))
+	g.Expect(snapshots[0].Name).To(HavePrefix("tfstate-backup-default-my-resource-20240101020000-"))
+	g.Expect(snapshots[0].Serial).To(Equal(int64(2)))
+	g.Expect(snapshots[1].Name).To(HavePrefix("tfstate-backup-default-my-resource-20240101030000-"))
+
+	snapshots, err = store.List(context.TODO(), terraform, infrav1.StateBackupTriggerMigrateState)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(snapshots).To(HaveLen(1))
+}
+
+func Test_secretStateBackupStoreLongNames(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	// both names are longer than a label value, and share the same truncated prefix
+	prefix := strings.Repeat("a", 70)
+	first := &infrav1.Terraform{ObjectMeta: metav1.ObjectMeta{Name: prefix + "-first", Namespace: "default"}}
+	second := &infrav1.Terraform{ObjectMeta: metav1.ObjectMeta{Name: prefix + "-second", Namespace: "default"}}
+
+	store, err := newStateBackupStore(fake.NewClientBuilder().WithScheme(scheme).Build(), &infrav1.StateBackupSpec{})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, terraform := range []*infrav1.Terraform{first, second} {
+		g.Expect(store.Save(context.TODO(), terraform, StateSnapshot{
+			Name:      stateSnapshotName(terraform, at),
+			Workspace: terraform.WorkspaceName(),
+			Trigger:   infrav1.StateBackupTriggerApply,
+			CreatedAt: at,
+			State:     []byte(`{"serial": 1}`),
+		})).To(Succeed())
+	}
+
+	snapshots, err := store.List(context.TODO(), first, infrav1.StateBackupTriggerApply)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(snapshots).To(HaveLen(1))
+	g.Expect(snapshots[0].Name).To(ContainSubstring("-first-"))
+}
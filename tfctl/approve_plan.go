@@ -1,14 +1,18 @@
 package tfctl
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -24,9 +28,15 @@ func (c *CLI) ApprovePlan(out io.Writer, resource string, yamlFile string) error
 		return fmt.Errorf("resource %s not found", resource)
 	}
 
+	result := commandResult{
+		Action:    "approve",
+		Namespace: c.namespace,
+		Name:      resource,
+		PlanID:    terraform.Status.Plan.Pending,
+	}
 	if terraform.Status.Plan.Pending == "" {
-		fmt.Fprintln(out, "no plan pending")
-		return nil
+		result.Message = "no plan pending"
+		return c.printResult(out, result)
 	}
 
 	//plan := terraform.Status.Plan.Pending
@@ -36,9 +46,83 @@ func (c *CLI) ApprovePlan(out io.Writer, resource string, yamlFile string) error
 		return err
 	}
 
-	fmt.Fprintln(out, " Plan approval set. Please commit and push to continue.")
+	result.Message = "Plan approval set. Please commit and push to continue."
+	return c.printResult(out, result)
+}
+
+// ApprovePlanInCluster approves the pending plan for a given terraform resource by patching the live object,
+// after showing the human readable plan and asking for confirmation. The plan is only approved if it is still
+// the plan shown when the object is patched, i.e. it is pending and stored in the same ConfigMap.
+func (c *CLI) ApprovePlanInCluster(ctx context.Context, out io.Writer, in io.Reader, resource string) error {
+	key := types.NamespacedName{
+		Name:      resource,
+		Namespace: c.namespace,
+	}
+	terraform := infrav1.Terraform{}
+	if err := c.client.Get(ctx, key, &terraform); err != nil {
+		return fmt.Errorf("resource %s not found", resource)
+	}
+
+	planID := terraform.Status.Plan.Pending
+	result := commandResult{
+		Action:    "approve",
+		Namespace: c.namespace,
+		Name:      resource,
+		PlanID:    planID,
+	}
+	if planID == "" {
+		result.Message = "no plan pending"
+		return c.printResult(out, result)
+	}
+
+	if terraform.Spec.StoreReadablePlan != "human" {
+		return fmt.Errorf("cannot show the plan %s for review, please set spec.storeReadablePlan to 'human'", planID)
+	}
+	plan, err := c.readHumanPlan(ctx, terraform)
+	if err != nil {
+		return err
+	}
+	if shownPlanID := plan.Annotations[savedPlanAnnotation]; shownPlanID != planID {
+		return fmt.Errorf("the stored plan %q does not match the pending plan %s, please retry once the plan is stored", shownPlanID, planID)
+	}
+
+	// the plan is reviewed on stderr when the result is machine readable
+	messages := c.messages(out)
+	fmt.Fprintf(messages, " Plan %s of %s:\n\n", planID, key)
+	fmt.Fprintln(messages, plan.Data["tfplan"])
+	fmt.Fprintf(messages, " Approve plan %s? [y/N] ", planID)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+		result.Message = "Approval cancelled"
+		return c.printResult(out, result)
+	}
+
+	if err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		terraform := &infrav1.Terraform{}
+		if err := c.client.Get(ctx, key, terraform); err != nil {
+			return err
+		}
+		if terraform.Status.Plan.Pending != planID {
+			return fmt.Errorf("plan %s is not pending anymore, the pending plan is %q: run tfctl approve again to review it",
+				planID, terraform.Status.Plan.Pending)
+		}
+		// a plan generated again at the same revision keeps its id, but the runner stores it in a new ConfigMap
+		stored, err := c.readHumanPlan(ctx, *terraform)
+		if err != nil {
+			return err
+		}
+		if stored.UID != plan.UID {
+			return fmt.Errorf("plan %s has been generated again since it was shown: run tfctl approve again to review it", planID)
+		}
+		patch := client.MergeFromWithOptions(terraform.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		terraform.Spec.ApprovePlan = planID
+		return c.client.Patch(ctx, terraform, patch)
+	}); err != nil {
+		return fmt.Errorf("failed to approve plan %s: %w", planID, err)
+	}
 
-	return nil
+	result.Message = fmt.Sprintf("Plan %s approved.", planID)
+	return c.printResult(out, result)
 }
 
 func approvePlan(terraform infrav1.Terraform, filename string) error {
@@ -0,0 +1,98 @@
+package tfctl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApprovePlanInCluster(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	terraform := &infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-resource", Namespace: "default"},
+		Spec:       infrav1.TerraformSpec{StoreReadablePlan: "human"},
+		Status:     infrav1.TerraformStatus{Plan: infrav1.PlanStatus{Pending: "plan-main-1234"}},
+	}
+	plan := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "tfplan-default-my-resource",
+			Namespace:   "default",
+			UID:         "plan-uid-1",
+			Annotations: map[string]string{savedPlanAnnotation: "plan-main-1234"},
+		},
+		Data: map[string]string{"tfplan": "Plan: 1 to add, 0 to change, 0 to destroy."},
+	}
+
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(terraform, plan).Build()
+	cli := &CLI{client: kubeClient, namespace: "default"}
+	key := types.NamespacedName{Namespace: "default", Name: "my-resource"}
+
+	// cancelling the approval when not confirmed
+	out := &bytes.Buffer{}
+	g.Expect(cli.ApprovePlanInCluster(context.TODO(), out, strings.NewReader("n\n"), "my-resource")).To(Succeed())
+	g.Expect(out.String()).To(ContainSubstring("Plan: 1 to add, 0 to change, 0 to destroy."))
+	g.Expect(out.String()).To(ContainSubstring("Approval cancelled"))
+
+	approved := &infrav1.Terraform{}
+	g.Expect(kubeClient.Get(context.TODO(), key, approved)).To(Succeed())
+	g.Expect(approved.Spec.ApprovePlan).To(BeEmpty())
+
+	// approving the plan shown
+	out.Reset()
+	g.Expect(cli.ApprovePlanInCluster(context.TODO(), out, strings.NewReader("y\n"), "my-resource")).To(Succeed())
+	g.Expect(out.String()).To(ContainSubstring("Plan plan-main-1234 approved."))
+	g.Expect(kubeClient.Get(context.TODO(), key, approved)).To(Succeed())
+	g.Expect(approved.Spec.ApprovePlan).To(Equal("plan-main-1234"))
+
+	// refusing to show a stored plan which is not the pending plan
+	approved.Spec.ApprovePlan = ""
+	approved.Status.Plan.Pending = "plan-main-5678"
+	g.Expect(kubeClient.Update(context.TODO(), approved)).To(Succeed())
+	err := cli.ApprovePlanInCluster(context.TODO(), out, strings.NewReader("y\n"), "my-resource")
+	g.Expect(err).To(MatchError(ContainSubstring(`the stored plan "plan-main-1234" does not match the pending plan plan-main-5678`)))
+
+	// refusing to approve a plan generated again under the same id while it was reviewed
+	approved.Status.Plan.Pending = "plan-main-1234"
+	g.Expect(kubeClient.Update(context.TODO(), approved)).To(Succeed())
+	replan := &replanReader{Reader: strings.NewReader("y\n"), replan: func() {
+		g.Expect(kubeClient.Delete(context.TODO(), plan)).To(Succeed())
+		regenerated := plan.DeepCopy()
+		regenerated.ResourceVersion = ""
+		regenerated.UID = "plan-uid-2"
+		regenerated.Data["tfplan"] = "Plan: 0 to add, 0 to change, 1 to destroy."
+		g.Expect(kubeClient.Create(context.TODO(), regenerated)).To(Succeed())
+	}}
+	err = cli.ApprovePlanInCluster(context.TODO(), out, replan, "my-resource")
+	g.Expect(err).To(MatchError(ContainSubstring("plan plan-main-1234 has been generated again since it was shown")))
+	g.Expect(kubeClient.Get(context.TODO(), key, approved)).To(Succeed())
+	g.Expect(approved.Spec.ApprovePlan).To(BeEmpty())
+}
+
+// replanReader regenerates the plan when the confirmation is read, as the controller would during a review.
+type replanReader struct {
+	io.Reader
+	replan func()
+}
+
+func (r *replanReader) Read(p []byte) (int, error) {
+	if r.replan != nil {
+		r.replan()
+		r.replan = nil
+	}
+	return r.Reader.Read(p)
+}
@@ -1,8 +1,10 @@
 package tfctl
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -10,27 +12,79 @@ import (
 	"time"
 
 	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/zclconf/go-cty/cty"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 )
 
-// Create generates a Terraform resource
-func (c *CLI) Create(
-	out io.Writer,
-	name string,
-	namespace string,
-	path string,
-	source string,
-	interval string,
-	export bool,
-) error {
-	sourceParams := strings.Split(source, "/")
+// The backend types of tfctl create.
+const (
+	BackendKubernetes = "kubernetes"
+	BackendS3         = "s3"
+	BackendGCS        = "gcs"
+	BackendAzureRM    = "azurerm"
+	BackendPG         = "pg"
+	BackendSharded    = "sharded"
+	BackendNone       = "none"
+)
+
+// CreateOptions are the options of a Terraform resource generated by tfctl create.
+type CreateOptions struct {
+	// Source is the source of the module, as Kind/name.
+	Source   string
+	Path     string
+	Interval string
+
+	// Vars are the variables, as name=value.
+	Vars []string
+	// VarsFrom are the Secrets and ConfigMaps to read the variables from, as Kind/name or Kind/name:key,key.
+	VarsFrom []string
+	// ModulePath is a local checkout of the module, which variables are added to the vars
+	// with their default values.
+	ModulePath string
+
+	// OutputsSecret is the Secret to write the outputs to, all of them or the Outputs.
+	OutputsSecret string
+	Outputs       []string
+
+	// BackendType is one of the backend types, the kubernetes backend by default.
+	BackendType string
+	// BackendConfig are the settings of the backend, as key=value.
+	BackendConfig []string
+	Workspace     string
+	ApprovePlan   string
+	// RunnerPodTemplate is a YAML file with the runner pod template.
+	RunnerPodTemplate string
+	// DependsOn are the Terraform resources to depend on, as name or namespace/name.
+	DependsOn []string
+
+	// Interactive asks for the source, path, interval, approval mode and the variables of the module.
+	Interactive bool
+	// Export prints the Terraform resource instead of creating it.
+	Export bool
+}
+
+// Create generates a Terraform resource, validated against the schema of the Terraform CRD.
+func (c *CLI) Create(in io.Reader, out io.Writer, name string, opts CreateOptions) error {
+	namespace := c.namespace
+
+	var prompt *prompter
+	if opts.Interactive {
+		prompt = &prompter{in: bufio.NewReader(in), out: c.messages(out)}
+		opts.Source = prompt.ask("Source, as GitRepository, Bucket or OCIRepository/name", opts.Source)
+		opts.Path = prompt.ask("Path of the module in the source", opts.Path)
+		opts.Interval = prompt.ask("Reconciliation interval", opts.Interval)
+		opts.ApprovePlan = prompt.ask(`Approve the plans automatically with "auto", or leave empty to approve them manually`, opts.ApprovePlan)
+	}
+
+	sourceParams := strings.Split(opts.Source, "/")
 	if len(sourceParams) != 2 ||
 		!(sourceParams[0] == "GitRepository" || sourceParams[0] == "Bucket" || sourceParams[0] == "OCIRepository") {
 		return fmt.Errorf("source must be of kind GitRepository or Bucket or OCIRepository")
 	}
 
-	intervalParsed, err := time.ParseDuration(interval)
+	intervalParsed, err := time.ParseDuration(opts.Interval)
 	if err != nil {
 		return err
 	}
@@ -51,14 +105,62 @@ func (c *CLI) Create(
 				Name:      sourceParams[1],
 				Namespace: namespace,
 			},
-			Path: path,
+			Path: opts.Path,
 			Interval: metav1.Duration{
 				Duration: intervalParsed,
 			},
+			ApprovePlan: opts.ApprovePlan,
+			Workspace:   opts.Workspace,
 		},
 	}
 
-	if export {
+	if terraform.Spec.Vars, err = c.createVars(out, opts, prompt); err != nil {
+		return err
+	}
+
+	for _, ref := range opts.VarsFrom {
+		varsFrom, err := parseVarsFrom(ref)
+		if err != nil {
+			return err
+		}
+		terraform.Spec.VarsFrom = append(terraform.Spec.VarsFrom, varsFrom)
+	}
+
+	if opts.OutputsSecret != "" {
+		terraform.Spec.WriteOutputsToSecret = &infrav1.WriteOutputsToSecretSpec{
+			Name:    opts.OutputsSecret,
+			Outputs: opts.Outputs,
+		}
+	} else if len(opts.Outputs) > 0 {
+		return fmt.Errorf("the outputs require a Secret to write them to")
+	}
+
+	if terraform.Spec.BackendConfig, err = parseBackendConfig(opts.BackendType, opts.BackendConfig); err != nil {
+		return err
+	}
+
+	if opts.RunnerPodTemplate != "" {
+		if terraform.Spec.RunnerPodTemplate, err = readRunnerPodTemplate(opts.RunnerPodTemplate); err != nil {
+			return err
+		}
+	}
+
+	for _, dependency := range opts.DependsOn {
+		ref := meta.NamespacedObjectReference{Name: dependency}
+		if ns, name, ok := strings.Cut(dependency, "/"); ok {
+			ref = meta.NamespacedObjectReference{Namespace: ns, Name: name}
+		}
+		terraform.Spec.DependsOn = append(terraform.Spec.DependsOn, ref)
+	}
+
+	if err := validateTerraform(&terraform); err != nil {
+		return err
+	}
+
+	if opts.Export {
+		if c.output == OutputJSON {
+			return c.printObject(out, &terraform)
+		}
 		return printTerraform(&terraform)
 	}
 
@@ -66,9 +168,200 @@ func (c *CLI) Create(
 		return err
 	}
 
-	fmt.Fprintf(out, " created Terraform resource %s/%s\n", namespace, name)
+	return c.printResult(out, commandResult{
+		Action:    "create",
+		Namespace: namespace,
+		Name:      name,
+		Message:   fmt.Sprintf("created Terraform resource %s/%s", namespace, name),
+	})
+}
+
+// createVars returns the variables of the Terraform resource: the variables of the module, with their
+// default values unless given, then the other variables given.
+func (c *CLI) createVars(out io.Writer, opts CreateOptions, prompt *prompter) ([]infrav1.Variable, error) {
+	values := map[string]string{}
+	var names []string
+	for _, v := range opts.Vars {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable %q, must be name=value", v)
+		}
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+		values[name] = value
+	}
+
+	var declared []moduleVariable
+	if opts.ModulePath != "" {
+		var err error
+		if declared, err = readModuleVariables(opts.ModulePath); err != nil {
+			return nil, err
+		}
+	}
+
+	var vars []infrav1.Variable
+	var missing []string
+	isDeclared := map[string]bool{}
+	for _, variable := range declared {
+		isDeclared[variable.Name] = true
+		value, ok := values[variable.Name]
+		if !ok && prompt != nil {
+			value = prompt.ask(variable.question(), "")
+			ok = value != ""
+		}
+
+		switch {
+		case ok:
+			json, err := variableValue(variable.Type, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid variable %q: %w", variable.Name, err)
+			}
+			vars = append(vars, infrav1.Variable{Name: variable.Name, Value: json})
+		case variable.Default != nil:
+			vars = append(vars, infrav1.Variable{Name: variable.Name, Value: variable.Default})
+		case !variable.Nullable:
+			missing = append(missing, variable.Name)
+		}
+	}
+
+	for _, name := range names {
+		if isDeclared[name] {
+			continue
+		}
+		if opts.ModulePath != "" {
+			return nil, fmt.Errorf("variable %q is not declared by the module in %s", name, opts.ModulePath)
+		}
+		json, err := variableValue(cty.DynamicPseudoType, values[name])
+		if err != nil {
+			return nil, err
+		}
+		vars = append(vars, infrav1.Variable{Name: name, Value: json})
+	}
+
+	if len(missing) > 0 && len(opts.VarsFrom) == 0 {
+		fmt.Fprintf(c.messages(out), " The variables %s have no default value, please set them with --var or --vars-from\n",
+			strings.Join(missing, ", "))
+	}
+
+	return vars, nil
+}
+
+// question returns the question asking for the value of the variable in interactive mode.
+func (v moduleVariable) question() string {
+	question := "Value of variable " + v.Name
+	if v.TypeExpr != "" {
+		question += " (" + v.TypeExpr + ")"
+	}
+	if v.Description != "" {
+		question += ", " + v.Description
+	}
+	if v.Default != nil {
+		question += ", default " + string(v.Default.Raw)
+	}
+	return question
+}
+
+// parseVarsFrom parses a reference to the variables of a Secret or a ConfigMap, as Kind/name or Kind/name:key,key.
+func parseVarsFrom(ref string) (infrav1.VarsReference, error) {
+	kindName, keys, _ := strings.Cut(ref, ":")
+	kind, name, ok := strings.Cut(kindName, "/")
+	if !ok || kind == "" || name == "" {
+		return infrav1.VarsReference{}, fmt.Errorf("invalid vars reference %q, must be Secret/name or ConfigMap/name, with :key,key to select keys", ref)
+	}
 
-	return nil
+	varsFrom := infrav1.VarsReference{Kind: kind, Name: name}
+	if keys != "" {
+		varsFrom.VarsKeys = strings.Split(keys, ",")
+	}
+	return varsFrom, nil
+}
+
+// parseBackendConfig returns the backend configuration of a backend type, with the settings given as key=value
+// using the field names of the backend, e.g. bucket=my-state for s3. A settings ending with SecretRef names a Secret.
+func parseBackendConfig(backendType string, settings []string) (*infrav1.BackendConfigSpec, error) {
+	fields := map[string]any{}
+	for _, setting := range settings {
+		key, value, ok := strings.Cut(setting, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid backend setting %q, must be key=value", setting)
+		}
+		switch {
+		case strings.HasSuffix(key, "SecretRef"):
+			fields[key] = map[string]string{"name": value}
+		case value == "true" || value == "false":
+			fields[key] = value == "true"
+		default:
+			fields[key] = value
+		}
+	}
+
+	config := map[string]any{}
+	switch backendType {
+	case "", BackendKubernetes:
+		if len(fields) == 0 {
+			return nil, nil
+		}
+		config = fields
+	case BackendSharded:
+		config = fields
+		config["sharded"] = true
+	case BackendNone:
+		if len(fields) > 0 {
+			return nil, fmt.Errorf("the backend settings cannot be used without a backend")
+		}
+		config["disable"] = true
+	case BackendS3, BackendGCS, BackendAzureRM, BackendPG:
+		config[backendType] = fields
+	default:
+		return nil, fmt.Errorf("invalid backend type %q, must be one of: %s", backendType, strings.Join([]string{
+			BackendKubernetes, BackendS3, BackendGCS, BackendAzureRM, BackendPG, BackendSharded, BackendNone}, ", "))
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	backendConfig := &infrav1.BackendConfigSpec{}
+	if err := decoder.Decode(backendConfig); err != nil {
+		return nil, fmt.Errorf("invalid %s backend settings: %w", backendType, err)
+	}
+	return backendConfig, nil
+}
+
+// readRunnerPodTemplate reads a runner pod template, with its metadata and spec, from a YAML file.
+func readRunnerPodTemplate(filename string) (infrav1.RunnerPodTemplate, error) {
+	template := infrav1.RunnerPodTemplate{}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return template, err
+	}
+	if err := yaml.UnmarshalStrict(data, &template); err != nil {
+		return template, fmt.Errorf("invalid runner pod template %s: %w", filename, err)
+	}
+	return template, nil
+}
+
+// prompter asks for the options of tfctl create in interactive mode.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask returns the answer to a question, or the default answer when the answer is empty.
+func (p *prompter) ask(question string, defaultAnswer string) string {
+	if defaultAnswer != "" {
+		fmt.Fprintf(p.out, " %s [%s]: ", question, defaultAnswer)
+	} else {
+		fmt.Fprintf(p.out, " %s: ", question)
+	}
+	answer, _ := p.in.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return defaultAnswer
+	}
+	return answer
 }
 
 func printTerraform(terraform interface{}) error {
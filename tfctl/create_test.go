@@ -0,0 +1,197 @@
+package tfctl
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/fluxcd/pkg/apis/meta"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreate(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	cli := &CLI{client: kubeClient, namespace: "dev"}
+
+	podTemplate := filepath.Join(t.TempDir(), "pod.yaml")
+	g.Expect(os.WriteFile(podTemplate, []byte(`
+metadata:
+  labels:
+    team: platform
+spec:
+  image: ghcr.io/flux-iac/tf-runner:custom
+`), 0o600)).To(Succeed())
+
+	var out bytes.Buffer
+	g.Expect(cli.Create(nil, &out, "bucket", CreateOptions{
+		Source:            "GitRepository/infra",
+		Path:              "./bucket",
+		Interval:          "10m",
+		Vars:              []string{"region=eu-west-1", "replicas=3"},
+		VarsFrom:          []string{"Secret/credentials", "ConfigMap/settings:a,b"},
+		ModulePath:        "testdata/module",
+		OutputsSecret:     "bucket-outputs",
+		Outputs:           []string{"arn"},
+		BackendType:       BackendS3,
+		BackendConfig:     []string{"bucket=state", "key=bucket.tfstate", "region=eu-west-1", "encrypt=true", "credentialsSecretRef=aws"},
+		Workspace:         "dev",
+		ApprovePlan:       "auto",
+		RunnerPodTemplate: podTemplate,
+		DependsOn:         []string{"network", "shared/dns"},
+	})).To(Succeed())
+	g.Expect(out.String()).To(ContainSubstring("created Terraform resource dev/bucket"))
+
+	terraform := &infrav1.Terraform{}
+	g.Expect(kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: "dev", Name: "bucket"}, terraform)).To(Succeed())
+
+	vars := map[string]string{}
+	for _, v := range terraform.Spec.Vars {
+		vars[v.Name] = string(v.Value.Raw)
+	}
+	// the variables of the module with their default values, except the null ones
+	g.Expect(vars).To(Equal(map[string]string{
+		"region":   `"eu-west-1"`,
+		"replicas": `3`,
+		"tags":     `{"team":"platform"}`,
+		"settings": `{}`,
+	}))
+	g.Expect(terraform.Spec.VarsFrom).To(Equal([]infrav1.VarsReference{
+		{Kind: "Secret", Name: "credentials"},
+		{Kind: "ConfigMap", Name: "settings", VarsKeys: []string{"a", "b"}},
+	}))
+	g.Expect(terraform.Spec.WriteOutputsToSecret).To(Equal(&infrav1.WriteOutputsToSecretSpec{Name: "bucket-outputs", Outputs: []string{"arn"}}))
+	g.Expect(terraform.Spec.BackendConfig.S3).To(Equal(&infrav1.S3BackendSpec{
+		Bucket:               "state",
+		Key:                  "bucket.tfstate",
+		Region:               "eu-west-1",
+		Encrypt:              true,
+		CredentialsSecretRef: &meta.LocalObjectReference{Name: "aws"},
+	}))
+	g.Expect(terraform.Spec.Workspace).To(Equal("dev"))
+	g.Expect(terraform.Spec.ApprovePlan).To(Equal("auto"))
+	g.Expect(terraform.Spec.RunnerPodTemplate.Metadata.Labels).To(HaveKeyWithValue("team", "platform"))
+	g.Expect(terraform.Spec.RunnerPodTemplate.Spec.Image).To(Equal("ghcr.io/flux-iac/tf-runner:custom"))
+	g.Expect(terraform.Spec.DependsOn).To(Equal([]meta.NamespacedObjectReference{
+		{Name: "network"},
+		{Namespace: "shared", Name: "dns"},
+	}))
+}
+
+func TestCreateInteractive(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	cli := &CLI{client: kubeClient, namespace: "dev"}
+
+	// answering the source, path, interval, approval mode, then the variables of the module
+	in := strings.NewReader("GitRepository/infra\n./bucket\n\n\neu-west-1\n\n\n\n\n")
+	var out bytes.Buffer
+	g.Expect(cli.Create(in, &out, "bucket", CreateOptions{
+		Interval:    "10m",
+		ModulePath:  "testdata/module",
+		Interactive: true,
+	})).To(Succeed())
+	g.Expect(out.String()).To(ContainSubstring("Reconciliation interval [10m]: "))
+	g.Expect(out.String()).To(ContainSubstring("Value of variable region (string), The region of the bucket: "))
+
+	terraform := &infrav1.Terraform{}
+	g.Expect(kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: "dev", Name: "bucket"}, terraform)).To(Succeed())
+	g.Expect(terraform.Spec.SourceRef).To(Equal(infrav1.CrossNamespaceSourceReference{Kind: "GitRepository", Name: "infra", Namespace: "dev"}))
+	g.Expect(terraform.Spec.Path).To(Equal("./bucket"))
+	g.Expect(terraform.Spec.Interval.Duration.String()).To(Equal("10m0s"))
+	g.Expect(terraform.Spec.ApprovePlan).To(BeEmpty())
+	g.Expect(terraform.Spec.Vars).To(HaveLen(4))
+	g.Expect(string(terraform.Spec.Vars[0].Value.Raw)).To(Equal(`"eu-west-1"`))
+}
+
+func TestCreateValidation(t *testing.T) {
+	cli := &CLI{namespace: "dev"}
+	valid := CreateOptions{Source: "GitRepository/infra", Path: "./", Interval: "1m", Export: true}
+
+	tests := []struct {
+		name   string
+		update func(*CreateOptions)
+		err    string
+	}{
+		{
+			name:   "missing backend settings",
+			update: func(o *CreateOptions) { o.BackendType = BackendS3; o.BackendConfig = []string{"bucket=state"} },
+			err:    "spec.backendConfig.s3.key in body should be at least 1 chars long",
+		},
+		{
+			name: "unknown backend setting",
+			update: func(o *CreateOptions) {
+				o.BackendType = BackendGCS
+				o.BackendConfig = []string{"bucket=state", "region=eu"}
+			},
+			err: `unknown field "region"`,
+		},
+		{
+			name:   "unknown backend type",
+			update: func(o *CreateOptions) { o.BackendType = "consul" },
+			err:    `invalid backend type "consul"`,
+		},
+		{
+			name:   "invalid vars reference kind",
+			update: func(o *CreateOptions) { o.VarsFrom = []string{"Pod/runner"} },
+			err:    "spec.varsFrom[0].kind",
+		},
+		{
+			name:   "invalid variable value",
+			update: func(o *CreateOptions) { o.ModulePath = "testdata/module"; o.Vars = []string{"replicas=many"} },
+			err:    `invalid variable "replicas"`,
+		},
+		{
+			name:   "undeclared variable",
+			update: func(o *CreateOptions) { o.ModulePath = "testdata/module"; o.Vars = []string{"zone=a"} },
+			err:    `variable "zone" is not declared by the module in testdata/module`,
+		},
+		{
+			name:   "outputs without a secret",
+			update: func(o *CreateOptions) { o.Outputs = []string{"arn"} },
+			err:    "the outputs require a Secret",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			opts := valid
+			tt.update(&opts)
+			g.Expect(cli.Create(nil, &bytes.Buffer{}, "bucket", opts)).To(MatchError(ContainSubstring(tt.err)))
+		})
+	}
+}
+
+func TestReadModuleVariables(t *testing.T) {
+	g := NewWithT(t)
+
+	variables, err := readModuleVariables("testdata/module")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(variables).To(HaveLen(5))
+
+	g.Expect(variables[0].Name).To(Equal("region"))
+	g.Expect(variables[0].Description).To(Equal("The region of the bucket"))
+	g.Expect(variables[0].Default).To(BeNil())
+	g.Expect(variables[0].question()).To(Equal("Value of variable region (string), The region of the bucket"))
+
+	g.Expect(variables[1].question()).To(Equal("Value of variable replicas (number), default 2"))
+	g.Expect(variables[2].TypeExpr).To(Equal("map(string)"))
+	g.Expect(variables[3].Nullable).To(BeTrue())
+
+	_, err = readModuleVariables(t.TempDir())
+	g.Expect(err).To(MatchError(ContainSubstring("no Terraform files found")))
+}
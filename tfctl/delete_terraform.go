@@ -25,7 +25,10 @@ func (c *CLI) DeleteTerraform(out io.Writer, resource string) error {
 		return err
 	}
 
-	fmt.Fprintf(out, " deleted Terraform resource %s/%s\n", c.namespace, resource)
-
-	return nil
+	return c.printResult(out, commandResult{
+		Action:    "delete",
+		Namespace: c.namespace,
+		Name:      resource,
+		Message:   fmt.Sprintf("deleted Terraform resource %s/%s", c.namespace, resource),
+	})
 }
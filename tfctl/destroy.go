@@ -0,0 +1,177 @@
+package tfctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/fluxcd/pkg/apis/meta"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Destroy deletes the given Terraform resource and destroys the resources it manages.
+// With cascade, every object depending on it is destroyed as well, leaves first.
+func (c *CLI) Destroy(ctx context.Context, out io.Writer, resource string, cascade bool, timeout time.Duration) error {
+	key := types.NamespacedName{
+		Name:      resource,
+		Namespace: c.namespace,
+	}
+
+	terraformList := &infrav1.TerraformList{}
+	if err := c.client.List(ctx, terraformList); err != nil {
+		return err
+	}
+
+	order, err := destroyOrder(key, terraformList.Items)
+	if err != nil {
+		return err
+	}
+
+	if len(order) > 1 && !cascade {
+		dependants := []string{}
+		for _, k := range order[:len(order)-1] {
+			dependants = append(dependants, k.String())
+		}
+		return fmt.Errorf("%s has dependants: %s, use --cascade to destroy them too", key, strings.Join(dependants, ", "))
+	}
+
+	// the progress is written to stderr when the result is machine readable
+	messages := c.messages(out)
+	fmt.Fprintln(messages, " Destroy order:")
+	for i, k := range order {
+		fmt.Fprintf(messages, "  %d. %s\n", i+1, k)
+	}
+
+	if err := requestCascadeDestroy(ctx, c.client, key); err != nil {
+		return err
+	}
+
+	terraform := &infrav1.Terraform{}
+	if err := c.client.Get(ctx, key, terraform); err != nil {
+		return err
+	}
+	if err := c.client.Delete(ctx, terraform); err != nil {
+		return err
+	}
+	fmt.Fprintf(messages, " Destroy requested for %s\n", key)
+
+	lastMessages := map[types.NamespacedName]string{}
+	err = wait.PollUntilContextTimeout(ctx, 5*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		done := true
+		for _, k := range order {
+			if lastMessages[k] == "destroyed" {
+				continue
+			}
+
+			tf := &infrav1.Terraform{}
+			if err := c.client.Get(ctx, k, tf); err != nil {
+				if apierrors.IsNotFound(err) {
+					fmt.Fprintf(messages, " ✔ %s destroyed\n", k)
+					lastMessages[k] = "destroyed"
+					continue
+				}
+				return false, err
+			}
+
+			done = false
+			if cond := apimeta.FindStatusCondition(tf.Status.Conditions, meta.ReadyCondition); cond != nil && cond.Message != lastMessages[k] {
+				fmt.Fprintf(messages, " ◎ %s: %s\n", k, cond.Message)
+				lastMessages[k] = cond.Message
+			}
+		}
+
+		return done, nil
+	})
+	if err != nil || !c.machineReadable() {
+		return err
+	}
+
+	destroyed := make([]string, 0, len(order))
+	for _, k := range order {
+		destroyed = append(destroyed, k.String())
+	}
+	return c.printObject(out, commandResult{
+		Action:    "destroy",
+		Namespace: c.namespace,
+		Name:      resource,
+		Message:   fmt.Sprintf("destroyed %s", strings.Join(destroyed, ", ")),
+	})
+}
+
+// destroyOrder returns the given object and all of its transitive dependants,
+// ordered so that every object comes after the objects depending on it.
+func destroyOrder(root types.NamespacedName, terraforms []infrav1.Terraform) ([]types.NamespacedName, error) {
+	dependants := map[types.NamespacedName][]types.NamespacedName{}
+	for _, tf := range terraforms {
+		for _, d := range tf.Spec.DependsOn {
+			dependency := types.NamespacedName{Namespace: d.Namespace, Name: d.Name}
+			if dependency.Namespace == "" {
+				dependency.Namespace = tf.Namespace
+			}
+			dependants[dependency] = append(dependants[dependency], client.ObjectKeyFromObject(&tf))
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[types.NamespacedName]int{}
+	order := []types.NamespacedName{}
+
+	var visit func(k types.NamespacedName) error
+	visit = func(k types.NamespacedName) error {
+		switch state[k] {
+		case visiting:
+			return fmt.Errorf("circular dependency detected at %s", k)
+		case visited:
+			return nil
+		}
+
+		state[k] = visiting
+		next := dependants[k]
+		sort.Slice(next, func(i, j int) bool { return next[i].String() < next[j].String() })
+		for _, d := range next {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		state[k] = visited
+		order = append(order, k)
+		return nil
+	}
+
+	if err := visit(root); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func requestCascadeDestroy(ctx context.Context, kubeClient client.Client, namespacedName types.NamespacedName) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		terraform := &infrav1.Terraform{}
+		if err := kubeClient.Get(ctx, namespacedName, terraform); err != nil {
+			return err
+		}
+		patch := client.MergeFrom(terraform.DeepCopy())
+		if ann := terraform.GetAnnotations(); ann == nil {
+			terraform.SetAnnotations(map[string]string{
+				infrav1.CascadeDestroyAnnotation: time.Now().Format(time.RFC3339Nano),
+			})
+		} else {
+			ann[infrav1.CascadeDestroyAnnotation] = time.Now().Format(time.RFC3339Nano)
+			terraform.SetAnnotations(ann)
+		}
+		return kubeClient.Patch(ctx, terraform, patch)
+	})
+}
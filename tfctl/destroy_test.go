@@ -0,0 +1,90 @@
+package tfctl
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/fluxcd/pkg/apis/meta"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newDependantTerraform(namespace, name string, dependsOn ...meta.NamespacedObjectReference) infrav1.Terraform {
+	return infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: infrav1.TerraformSpec{
+			DependsOn: dependsOn,
+		},
+	}
+}
+
+func TestDestroyOrder(t *testing.T) {
+	g := NewWithT(t)
+
+	// network <- cluster <- app
+	//         <- dns (in another namespace)
+	terraforms := []infrav1.Terraform{
+		newDependantTerraform("infra", "network"),
+		newDependantTerraform("infra", "cluster", meta.NamespacedObjectReference{Name: "network"}),
+		newDependantTerraform("apps", "app", meta.NamespacedObjectReference{Name: "cluster", Namespace: "infra"}),
+		newDependantTerraform("dns", "dns", meta.NamespacedObjectReference{Name: "network", Namespace: "infra"}),
+		newDependantTerraform("infra", "unrelated"),
+	}
+
+	order, err := destroyOrder(types.NamespacedName{Namespace: "infra", Name: "network"}, terraforms)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(order).To(Equal([]types.NamespacedName{
+		{Namespace: "dns", Name: "dns"},
+		{Namespace: "apps", Name: "app"},
+		{Namespace: "infra", Name: "cluster"},
+		{Namespace: "infra", Name: "network"},
+	}))
+
+	order, err = destroyOrder(types.NamespacedName{Namespace: "apps", Name: "app"}, terraforms)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(order).To(Equal([]types.NamespacedName{{Namespace: "apps", Name: "app"}}))
+}
+
+func TestDestroyOrderCircularDependency(t *testing.T) {
+	g := NewWithT(t)
+
+	terraforms := []infrav1.Terraform{
+		newDependantTerraform("default", "a", meta.NamespacedObjectReference{Name: "b"}),
+		newDependantTerraform("default", "b", meta.NamespacedObjectReference{Name: "a"}),
+	}
+
+	_, err := destroyOrder(types.NamespacedName{Namespace: "default", Name: "a"}, terraforms)
+	g.Expect(err).To(MatchError(ContainSubstring("circular dependency")))
+}
+
+func TestDestroyWithDependantsRequiresCascade(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	network := newDependantTerraform("default", "network")
+	cluster := newDependantTerraform("default", "cluster", meta.NamespacedObjectReference{Name: "network"})
+
+	cli := &CLI{
+		client:    fake.NewClientBuilder().WithScheme(scheme).WithObjects(&network, &cluster).Build(),
+		namespace: "default",
+	}
+
+	var out bytes.Buffer
+	err := cli.Destroy(context.TODO(), &out, "network", false, time.Second)
+	g.Expect(err).To(MatchError(ContainSubstring("default/cluster")))
+
+	var tf infrav1.Terraform
+	g.Expect(cli.client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "network"}, &tf)).To(Succeed())
+	g.Expect(tf.IsCascadeDestroyRequested()).To(BeFalse())
+}
@@ -0,0 +1,301 @@
+package tfctl
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+var (
+	diffPollInterval = 2 * time.Second
+	diffTimeout      = 10 * time.Minute
+)
+
+// diffArtifactMaxSize keeps the packaged configuration and the metadata of its Secret under the size limit of a Secret.
+const diffArtifactMaxSize = 1000 * 1024
+
+// sourceIgnoreFile is the file of the ignore patterns of the packaged directory.
+const sourceIgnoreFile = ".sourceignore"
+
+// defaultIgnorePatterns are the files excluded from the artifacts by source-controller,
+// and the local files of Terraform.
+var defaultIgnorePatterns = []string{
+	// VCS
+	".git/", ".gitignore", ".gitmodules", ".gitattributes",
+	// extensions
+	"*.jpg", "*.jpeg", "*.gif", "*.png", "*.wmv", "*.flv", "*.tar.gz", "*.zip",
+	// CI
+	".github/", ".circleci/", ".travis.yml", ".gitlab-ci.yml", "appveyor.yml", ".drone.yml",
+	"cloudbuild.yaml", "codeship-services.yml", "codeship-steps.yml",
+	// extra
+	"**/.goreleaser.yml", "**/.sops.yaml", "**/.flux.yaml",
+	// Terraform
+	".terraform/", "*.tfstate", "*.tfstate.backup", ".terraform.tfstate.lock.info",
+}
+
+// Diff packages the local directory as a source artifact, and requests the controller to plan it with the
+// vars, backend and workspace of the given Terraform resource. The human readable plan is printed once
+// the runner has planned it. The plan is neither saved nor applied.
+func (c *CLI) Diff(ctx context.Context, out io.Writer, resource string, dir string) error {
+	key := types.NamespacedName{
+		Name:      resource,
+		Namespace: c.namespace,
+	}
+
+	terraform := &infrav1.Terraform{}
+	if err := c.client.Get(ctx, key, terraform); err != nil {
+		return fmt.Errorf("resource %s not found", resource)
+	}
+	if terraform.Spec.Suspend {
+		return fmt.Errorf("resource %s/%s is suspended, resume it to run a diff", c.namespace, resource)
+	}
+
+	artifact, err := packageSource(dir)
+	if err != nil {
+		return fmt.Errorf("unable to package %s: %w", dir, err)
+	}
+	if len(artifact) > diffArtifactMaxSize {
+		return fmt.Errorf("the packaged %s is %d bytes, more than the %d bytes a diff supports", dir, len(artifact), diffArtifactMaxSize)
+	}
+
+	// the progress is written to stderr when the result is machine readable
+	messages := c.messages(out)
+
+	req := infrav1.DiffRequest{
+		ID: strconv.FormatInt(time.Now().UnixNano(), 10),
+	}
+	req.ArtifactSecretName = infrav1.DiffArtifactSecretName(resource, req.ID)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      req.ArtifactSecretName,
+			Namespace: c.namespace,
+			Labels:    map[string]string{infrav1.DiffLabel: resource},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: infrav1.GroupVersion.String(),
+				Kind:       infrav1.TerraformKind,
+				Name:       terraform.Name,
+				UID:        terraform.UID,
+			}},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{infrav1.DiffArtifactDataKey: artifact},
+	}
+	if err := c.client.Create(ctx, secret); err != nil {
+		return fmt.Errorf("unable to upload the configuration: %w", err)
+	}
+	// the controller deletes the configuration once planned
+	defer func() {
+		if err := c.client.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			fmt.Fprintf(messages, " Unable to delete the configuration %s: %s\n", secret.Name, err)
+		}
+	}()
+
+	if err := requestDiff(ctx, c.client, key, req, c.whoAmI(ctx)); err != nil {
+		return err
+	}
+	if err := requestReconciliation(ctx, c.client, key); err != nil {
+		return err
+	}
+	fmt.Fprintf(messages, " Requested a diff of %s with %s/%s, waiting for the runner\n", dir, c.namespace, resource)
+
+	var result *infrav1.DiffStatus
+	err = wait.PollUntilContextTimeout(ctx, diffPollInterval, diffTimeout, true, func(ctx context.Context) (bool, error) {
+		if err := c.client.Get(ctx, key, terraform); err != nil {
+			return false, nil
+		}
+		if s := terraform.Status.Diff; s != nil && s.ID == req.ID {
+			result = s
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("timed out waiting for the diff: %w", err)
+	}
+
+	if !result.Succeeded {
+		return fmt.Errorf("diff failed: %s", result.Message)
+	}
+
+	output := &corev1.Secret{}
+	if err := c.client.Get(ctx, types.NamespacedName{Namespace: c.namespace, Name: result.OutputSecretName}, output); err != nil {
+		return fmt.Errorf("unable to get the plan of the diff: %w", err)
+	}
+	if err := c.client.Delete(ctx, output); err != nil && !apierrors.IsNotFound(err) {
+		fmt.Fprintf(messages, " Unable to delete the plan %s: %s\n", output.Name, err)
+	}
+
+	if c.machineReadable() {
+		if err := c.printObject(out, diffResult{
+			Namespace:  c.namespace,
+			Name:       resource,
+			ID:         result.ID,
+			HasChanges: result.HasChanges,
+			Message:    result.Message,
+			Plan:       string(output.Data[infrav1.DiffOutputDataKey]),
+		}); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintln(out, string(output.Data[infrav1.DiffOutputDataKey]))
+		fmt.Fprintf(out, " %s\n", result.Message)
+	}
+	return c.changesPending(result.HasChanges)
+}
+
+// diffResult is the result of a diff printed with --output.
+type diffResult struct {
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	ID         string `json:"id"`
+	HasChanges bool   `json:"hasChanges"`
+	Message    string `json:"message"`
+	Plan       string `json:"plan"`
+}
+
+func requestDiff(ctx context.Context, kubeClient client.Client, namespacedName types.NamespacedName, req infrav1.DiffRequest, requestedBy string) error {
+	value, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		terraform := &infrav1.Terraform{}
+		if err := kubeClient.Get(ctx, namespacedName, terraform); err != nil {
+			return err
+		}
+		patch := client.MergeFrom(terraform.DeepCopy())
+		ann := terraform.GetAnnotations()
+		if ann == nil {
+			ann = map[string]string{}
+		}
+		ann[infrav1.DiffAnnotation] = string(value)
+		ann[infrav1.DiffRequestedByAnnotation] = requestedBy
+		terraform.SetAnnotations(ann)
+		return kubeClient.Patch(ctx, terraform, patch)
+	})
+}
+
+// packageSource archives the regular files of a directory as a tar.gz, the way source-controller
+// packages its artifacts. The files matching the default exclusions of source-controller, the local
+// files of Terraform, and the patterns of the .sourceignore file at the root are left out.
+func packageSource(dir string) ([]byte, error) {
+	patterns := defaultIgnorePatterns
+	ignoreFile, err := os.ReadFile(filepath.Join(dir, sourceIgnoreFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(ignoreFile))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			patterns = append(patterns, line)
+		}
+	}
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	err = filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+
+		if isIgnored(patterns, rel, fi.IsDir()) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		header.Name = rel
+		header.ModTime = time.Time{}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// isIgnored matches a slash separated path relative to the packaged directory against the ignore patterns.
+// A pattern ending with a slash only matches directories, a pattern with another slash matches the path
+// from the root, and any other pattern matches the name of a file or directory at any depth.
+// Negated patterns are not supported, and are left out.
+func isIgnored(patterns []string, rel string, isDir bool) bool {
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		if strings.HasSuffix(pattern, "/") {
+			if !isDir {
+				continue
+			}
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+
+		pattern = strings.TrimPrefix(pattern, "**/")
+		if strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(strings.TrimPrefix(pattern, "/"), rel); ok {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package tfctl
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// handleDiffs plays the controller: it answers every diff request with the given plan,
+// after checking that the configuration was uploaded, until the context is cancelled.
+func handleDiffs(ctx context.Context, kubeClient client.Client, key types.NamespacedName, plan string) {
+	for ctx.Err() == nil {
+		terraform := &infrav1.Terraform{}
+		if err := kubeClient.Get(ctx, key, terraform); err == nil {
+			if req, err := terraform.GetDiffRequest(); err == nil && req != nil {
+				artifact := &corev1.Secret{}
+				result := infrav1.DiffStatus{ID: req.ID, Succeeded: false, Message: "no configuration"}
+				if err := kubeClient.Get(ctx, types.NamespacedName{Namespace: key.Namespace, Name: req.ArtifactSecretName}, artifact); err == nil &&
+					artifact.Labels[infrav1.DiffLabel] == key.Name {
+					_ = kubeClient.Create(ctx, &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: infrav1.DiffOutputSecretName(key.Name), Namespace: key.Namespace},
+						Data:       map[string][]byte{infrav1.DiffOutputDataKey: []byte(plan)},
+					})
+					result = infrav1.DiffStatus{ID: req.ID, Succeeded: true, HasChanges: true, Message: "1 to add, 0 to change, 0 to destroy",
+						OutputSecretName: infrav1.DiffOutputSecretName(key.Name)}
+				}
+				terraform.Status.Diff = &result
+				_ = kubeClient.Update(ctx, terraform)
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	g := NewWithT(t)
+
+	diffPollInterval = 10 * time.Millisecond
+	diffTimeout = 5 * time.Second
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	terraform := &infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-resource", Namespace: "default"},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(terraform).Build()
+	key := types.NamespacedName{Namespace: "default", Name: "my-resource"}
+	c := &CLI{client: kubeClient, namespace: "default"}
+
+	dir := t.TempDir()
+	g.Expect(os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`resource "null_resource" "a" {}`), 0644)).To(Succeed())
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go handleDiffs(ctx, kubeClient, key, "  # null_resource.a will be created")
+
+	// printing the plan, and leaving nothing behind
+	out := &bytes.Buffer{}
+	g.Expect(c.Diff(context.TODO(), out, "my-resource", dir)).To(Succeed())
+	g.Expect(out.String()).To(ContainSubstring("Requested a diff of " + dir + " with default/my-resource"))
+	g.Expect(out.String()).To(ContainSubstring("# null_resource.a will be created"))
+	g.Expect(out.String()).To(HaveSuffix("1 to add, 0 to change, 0 to destroy\n"))
+
+	secrets := &corev1.SecretList{}
+	g.Expect(kubeClient.List(context.TODO(), secrets)).To(Succeed())
+	g.Expect(secrets.Items).To(BeEmpty())
+
+	// printing the result as JSON, and exiting with changes pending
+	out.Reset()
+	c.output = OutputJSON
+	c.detailedExitCode = true
+	g.Expect(c.Diff(context.TODO(), out, "my-resource", dir)).To(MatchError(ErrChangesPending))
+	result := diffResult{}
+	g.Expect(json.Unmarshal(out.Bytes(), &result)).To(Succeed())
+	g.Expect(result.HasChanges).To(BeTrue())
+	g.Expect(result.Message).To(Equal("1 to add, 0 to change, 0 to destroy"))
+	g.Expect(result.Plan).To(Equal("  # null_resource.a will be created"))
+}
+
+func TestPackageSource(t *testing.T) {
+	g := NewWithT(t)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.tf":                       "",
+		"modules/vpc/main.tf":           "",
+		".terraform.lock.hcl":           "",
+		".terraform/providers/provider": "",
+		"terraform.tfstate":             "",
+		".git/HEAD":                     "",
+		"docs/diagram.png":              "",
+		"secrets/prod.tfvars":           "",
+		"envs/dev.tfvars":               "",
+		".sourceignore":                 "# local secrets\n/secrets/\n*.md\n",
+		"README.md":                     "",
+	}
+	for name, content := range files {
+		g.Expect(os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)).To(Succeed())
+	}
+
+	artifact, err := packageSource(dir)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	gr, err := gzip.NewReader(bytes.NewReader(artifact))
+	g.Expect(err).ToNot(HaveOccurred())
+	tr := tar.NewReader(gr)
+	names := []string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		g.Expect(err).ToNot(HaveOccurred())
+		names = append(names, header.Name)
+	}
+	g.Expect(names).To(ConsistOf(".sourceignore", ".terraform.lock.hcl", "envs/dev.tfvars", "main.tf", "modules/vpc/main.tf"))
+}
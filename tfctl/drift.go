@@ -0,0 +1,210 @@
+package tfctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/hako/durafmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Drift prints the report of the drift found by the last drift detection of a Terraform resource.
+func (c *CLI) Drift(out io.Writer, resource string) error {
+	key := types.NamespacedName{Namespace: c.namespace, Name: resource}
+	terraform := &infrav1.Terraform{}
+	if err := c.client.Get(context.TODO(), key, terraform); err != nil {
+		return err
+	}
+
+	report, err := c.getDriftReport(terraform)
+	if err != nil {
+		return err
+	}
+
+	if c.machineReadable() {
+		if err := c.printObject(out, driftResult{
+			Namespace: c.namespace,
+			Name:      resource,
+			Drifted:   report != nil,
+			Report:    report,
+		}); err != nil {
+			return err
+		}
+		return c.changesPending(report != nil)
+	}
+
+	if report == nil {
+		fmt.Fprintf(out, "No drift detected on %s\n", key)
+		return nil
+	}
+
+	fmt.Fprintf(out, "Drift detected on %s at %s, revision %s\n",
+		key, report.DetectedAt.Format(time.RFC3339), report.Revision)
+	for _, resource := range report.Resources {
+		fmt.Fprintf(out, "\n%s %s (%s)", actionSymbol(resource.Actions), resource.Address, strings.Join(resource.Actions, ", "))
+		if resource.PolicyAction != "" {
+			fmt.Fprintf(out, " [%s]", resource.PolicyAction)
+		}
+		fmt.Fprintln(out)
+		for _, attribute := range resource.Attributes {
+			fmt.Fprintf(out, "    %s: %s => %s\n", attribute.Name, orNull(attribute.Before), orNull(attribute.After))
+		}
+	}
+	if report.Truncated {
+		fmt.Fprintln(out, "\nThe report was truncated to bound its size.")
+	}
+	return c.changesPending(true)
+}
+
+// driftResult is the drift of a Terraform resource printed by drift with --output.
+type driftResult struct {
+	Namespace string               `json:"namespace"`
+	Name      string               `json:"name"`
+	Drifted   bool                 `json:"drifted"`
+	Report    *infrav1.DriftReport `json:"report,omitempty"`
+}
+
+// driftSummary is the summary of the drift of a Terraform resource printed by drift --all with --output.
+type driftSummary struct {
+	Namespace       string       `json:"namespace"`
+	Name            string       `json:"name"`
+	Drifted         string       `json:"drifted"`
+	Resources       int          `json:"resources"`
+	Truncated       bool         `json:"truncated,omitempty"`
+	LastDetectionAt *metav1.Time `json:"lastDetectionAt,omitempty"`
+}
+
+// DriftSummary prints a summary of the drift of all Terraform resources in the namespace,
+// or across all namespaces.
+func (c *CLI) DriftSummary(out io.Writer, allNamespaces bool) error {
+	opts := []client.ListOption{}
+	if !allNamespaces {
+		opts = append(opts, client.InNamespace(c.namespace))
+	}
+	terraformList := &infrav1.TerraformList{}
+	if err := c.client.List(context.TODO(), terraformList, opts...); err != nil {
+		return err
+	}
+
+	if c.machineReadable() {
+		summaries := []driftSummary{}
+		drifted := false
+		for i := range terraformList.Items {
+			terraform := &terraformList.Items[i]
+			summary := driftSummary{
+				Namespace:       terraform.Namespace,
+				Name:            terraform.Name,
+				Drifted:         "false",
+				LastDetectionAt: terraform.Status.LastDriftDetectionAt,
+			}
+			if report, err := c.getDriftReport(terraform); err != nil {
+				summary.Drifted = "unknown"
+			} else if report != nil {
+				summary.Drifted = "true"
+				summary.Resources = len(report.Resources)
+				summary.Truncated = report.Truncated
+				drifted = true
+			}
+			summaries = append(summaries, summary)
+		}
+		if err := c.printObject(out, summaries); err != nil {
+			return err
+		}
+		return c.changesPending(drifted)
+	}
+
+	if len(terraformList.Items) == 0 {
+		if allNamespaces {
+			fmt.Fprintln(out, "No resources found")
+		} else {
+			fmt.Fprintf(out, "No resources found in %s namespace\n", c.namespace)
+		}
+		return nil
+	}
+
+	var data [][]string
+	anyDrifted := false
+	for i := range terraformList.Items {
+		terraform := &terraformList.Items[i]
+
+		drifted, resources := "false", ""
+		if report, err := c.getDriftReport(terraform); err != nil {
+			drifted = "unknown"
+		} else if report != nil {
+			drifted = "true"
+			anyDrifted = true
+			resources = strconv.Itoa(len(report.Resources))
+			if report.Truncated {
+				resources += "+"
+			}
+		}
+
+		lastDetection := "never"
+		if t := terraform.Status.LastDriftDetectionAt; t != nil {
+			lastDetection = durafmt.Parse(time.Since(t.Time)).LimitFirstN(1).String() + " ago"
+		}
+
+		data = append(data, []string{
+			terraform.Namespace,
+			terraform.Name,
+			drifted,
+			resources,
+			lastDetection,
+		})
+	}
+
+	header := []string{"Namespace", "Name", "Drifted", "Resources", "Last Detection"}
+	table := newTablePrinter(out, header)
+	table.AppendBulk(data)
+	table.Render()
+
+	return c.changesPending(anyDrifted)
+}
+
+// getDriftReport returns the drift report referenced by the status, or nil if there was no drift.
+func (c *CLI) getDriftReport(terraform *infrav1.Terraform) (*infrav1.DriftReport, error) {
+	if terraform.Status.DriftReportConfigMapName == "" {
+		return nil, nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: terraform.Namespace, Name: terraform.Status.DriftReportConfigMapName}
+	if err := c.client.Get(context.TODO(), key, cm); err != nil {
+		return nil, fmt.Errorf("unable to get the drift report %s: %w", key, err)
+	}
+
+	report := &infrav1.DriftReport{}
+	if err := json.Unmarshal([]byte(cm.Data[infrav1.DriftReportDataKey]), report); err != nil {
+		return nil, fmt.Errorf("invalid drift report %s: %w", key, err)
+	}
+	return report, nil
+}
+
+func actionSymbol(actions []string) string {
+	switch {
+	case len(actions) > 1:
+		return "-/+"
+	case len(actions) == 1 && actions[0] == "create":
+		return "+"
+	case len(actions) == 1 && actions[0] == "delete":
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+func orNull(value string) string {
+	if value == "" {
+		return "null"
+	}
+	return value
+}
@@ -0,0 +1,107 @@
+package tfctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDrift(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	report, err := json.Marshal(infrav1.DriftReport{
+		Revision:   "main@sha1:1234",
+		DetectedAt: metav1.NewTime(time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)),
+		Resources: []infrav1.DriftedResource{
+			{
+				Address:      "aws_db_instance.main",
+				Actions:      []string{"update"},
+				PolicyAction: infrav1.DriftActionCorrect,
+				Attributes: []infrav1.DriftedAttribute{
+					{Name: "instance_class", Before: `"db.t3.large"`, After: `"db.t3.micro"`},
+					{Name: "password", Before: infrav1.DriftReportSensitiveValue, After: infrav1.DriftReportSensitiveValue},
+				},
+			},
+			{Address: "aws_iam_role.ci", Actions: []string{"create"}},
+		},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	drifted := &infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "drifted", Namespace: "default"},
+		Status: infrav1.TerraformStatus{
+			DriftReportConfigMapName: "tfdrift-report-drifted",
+			LastDriftDetectionAt:     &metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+		},
+	}
+	clean := &infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "clean", Namespace: "default"},
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "tfdrift-report-drifted", Namespace: "default"},
+		Data:       map[string]string{infrav1.DriftReportDataKey: string(report)},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(drifted, clean, cm).Build()
+	c := &CLI{client: kubeClient, namespace: "default"}
+
+	// printing the report of a drifted resource
+	out := &bytes.Buffer{}
+	g.Expect(c.Drift(out, "drifted")).To(Succeed())
+	g.Expect(out.String()).To(Equal(`Drift detected on default/drifted at 2024-01-10T12:00:00Z, revision main@sha1:1234
+
+~ aws_db_instance.main (update) [Correct]
+    instance_class: "db.t3.large" => "db.t3.micro"
+    password: (sensitive value) => (sensitive value)
+
++ aws_iam_role.ci (create)
+`))
+
+	// printing that there is no drift
+	out.Reset()
+	g.Expect(c.Drift(out, "clean")).To(Succeed())
+	g.Expect(out.String()).To(Equal("No drift detected on default/clean\n"))
+
+	// summarizing the drift of all resources
+	out.Reset()
+	g.Expect(c.DriftSummary(out, false)).To(Succeed())
+	g.Expect(out.String()).To(MatchRegexp(`NAMESPACE\s+NAME\s+DRIFTED\s+RESOURCES\s+LAST DETECTION`))
+	g.Expect(out.String()).To(MatchRegexp(`default\s+clean\s+false\s+never`))
+	g.Expect(out.String()).To(MatchRegexp(`default\s+drifted\s+true\s+2\s+2 hours ago`))
+
+	// exiting with changes pending only when drift is detected
+	c.detailedExitCode = true
+	g.Expect(c.Drift(io.Discard, "clean")).To(Succeed())
+	g.Expect(c.Drift(io.Discard, "drifted")).To(MatchError(ErrChangesPending))
+	g.Expect(c.DriftSummary(io.Discard, false)).To(MatchError(ErrChangesPending))
+
+	// printing the report as JSON
+	out.Reset()
+	c.output = OutputJSON
+	g.Expect(c.Drift(out, "drifted")).To(MatchError(ErrChangesPending))
+	result := driftResult{}
+	g.Expect(json.Unmarshal(out.Bytes(), &result)).To(Succeed())
+	g.Expect(result.Drifted).To(BeTrue())
+	g.Expect(result.Report.Resources).To(HaveLen(2))
+
+	out.Reset()
+	g.Expect(c.DriftSummary(out, false)).To(MatchError(ErrChangesPending))
+	summaries := []driftSummary{}
+	g.Expect(json.Unmarshal(out.Bytes(), &summaries)).To(Succeed())
+	g.Expect(summaries).To(ConsistOf(
+		HaveField("Drifted", "false"),
+		HaveField("Resources", 2),
+	))
+}
@@ -21,14 +21,18 @@ func (c *CLI) ForceUnlock(out io.Writer, resource, lockID string) error {
 		Namespace: c.namespace,
 	}
 
-	err := c.setForceUnlockAndReconcile(context.TODO(), c.client, out, key, lockID)
+	err := c.setForceUnlockAndReconcile(context.TODO(), c.client, c.messages(out), key, lockID)
 
 	if err != nil {
 		return err
 	}
 
-	fmt.Fprintf(out, " %s/%s Patched and Reconcile requested\n", c.namespace, resource)
-	return nil
+	return c.printResult(out, commandResult{
+		Action:    "force-unlock",
+		Namespace: c.namespace,
+		Name:      resource,
+		Message:   fmt.Sprintf("%s/%s Patched and Reconcile requested", c.namespace, resource),
+	})
 }
 
 func (c *CLI) setForceUnlockAndReconcile(ctx context.Context, kubeClient client.Client, out io.Writer, namespacedName types.NamespacedName, lockID string) error {
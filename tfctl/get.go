@@ -12,6 +12,7 @@ import (
 	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
 	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/hako/durafmt"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -23,6 +24,14 @@ func (c *CLI) Get(out io.Writer) error {
 		return err
 	}
 
+	if c.machineReadable() {
+		summaries := []terraformSummary{}
+		for _, terraform := range terraformList.Items {
+			summaries = append(summaries, summarize(terraform))
+		}
+		return c.printObject(out, summaries)
+	}
+
 	if len(terraformList.Items) == 0 {
 		fmt.Fprintf(out, "No resources found in %s namespace\n", c.namespace)
 		return nil
@@ -58,6 +67,41 @@ func (c *CLI) Get(out io.Writer) error {
 	return nil
 }
 
+// terraformSummary is a Terraform resource printed by get with --output.
+type terraformSummary struct {
+	Namespace           string                 `json:"namespace"`
+	Name                string                 `json:"name"`
+	Ready               metav1.ConditionStatus `json:"ready"`
+	Reason              string                 `json:"reason,omitempty"`
+	Message             string                 `json:"message,omitempty"`
+	PlanPending         bool                   `json:"planPending"`
+	PendingPlan         string                 `json:"pendingPlan,omitempty"`
+	DriftDetected       bool                   `json:"driftDetected"`
+	Suspended           bool                   `json:"suspended"`
+	LastAppliedRevision string                 `json:"lastAppliedRevision,omitempty"`
+	CreatedAt           metav1.Time            `json:"createdAt"`
+}
+
+func summarize(terraform infrav1.Terraform) terraformSummary {
+	summary := terraformSummary{
+		Namespace:           terraform.Namespace,
+		Name:                terraform.Name,
+		Ready:               metav1.ConditionUnknown,
+		PlanPending:         terraform.Status.Plan.Pending != "",
+		PendingPlan:         terraform.Status.Plan.Pending,
+		DriftDetected:       terraform.HasDrift(),
+		Suspended:           terraform.Spec.Suspend,
+		LastAppliedRevision: terraform.Status.LastAppliedRevision,
+		CreatedAt:           terraform.CreationTimestamp,
+	}
+	if ready := apimeta.FindStatusCondition(terraform.Status.Conditions, meta.ReadyCondition); ready != nil {
+		summary.Ready = ready.Status
+		summary.Reason = ready.Reason
+		summary.Message = ready.Message
+	}
+	return summary
+}
+
 func shorten(message string) string {
 	// get the last 40 characters of the message
 	var sha string
@@ -23,6 +23,10 @@ func (c *CLI) GetTerraform(out io.Writer, resource string) error {
 		return err
 	}
 
+	if c.machineReadable() {
+		return c.printObject(out, summarize(*terraform))
+	}
+
 	var readyCondition metav1.Condition
 	for _, cond := range *terraform.GetStatusConditions() {
 		if cond.Type == meta.ReadyCondition {
@@ -9,6 +9,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	appsv1 "k8s.io/api/apps/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -33,6 +35,9 @@ type CLI struct {
 	terraform      string
 	build          string
 	release        string
+
+	output           string
+	detailedExitCode bool
 }
 
 // New returns a new CLI instance
@@ -50,6 +55,8 @@ func (c *CLI) Init(kubeconfigArgs *genericclioptions.ConfigFlags, config *viper.
 	scheme := runtime.NewScheme()
 	cobra.CheckErr(corev1.AddToScheme(scheme))
 	cobra.CheckErr(appsv1.AddToScheme(scheme))
+	cobra.CheckErr(authenticationv1.AddToScheme(scheme))
+	cobra.CheckErr(coordinationv1.AddToScheme(scheme))
 	cobra.CheckErr(infrav1.AddToScheme(scheme))
 
 	client, err := client.NewWithWatch(k8sConfig, client.Options{
@@ -64,6 +71,12 @@ func (c *CLI) Init(kubeconfigArgs *genericclioptions.ConfigFlags, config *viper.
 	c.restConfig = k8sConfig
 	c.namespace = config.GetString("namespace")
 	c.terraform = config.GetString("terraform")
+	c.output = config.GetString("output")
+	c.detailedExitCode = config.GetBool("detailed-exitcode")
+
+	if err := validateOutput(c.output); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -0,0 +1,150 @@
+package tfctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// moduleVariable is a variable declared by a Terraform module.
+type moduleVariable struct {
+	Name        string
+	Description string
+	// Type is the type of the variable, or cty.DynamicPseudoType when it is any or not understood.
+	Type cty.Type
+	// TypeExpr is the type of the variable, as declared.
+	TypeExpr string
+	// Default is the default value of the variable, nil when the variable is required.
+	Default *apiextensionsv1.JSON
+	// Nullable is true when the default value is null, so that the variable is optional without a value.
+	Nullable bool
+}
+
+var moduleSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "variable", LabelNames: []string{"name"}},
+	},
+}
+
+var variableSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "type"},
+		{Name: "default"},
+		{Name: "description"},
+	},
+}
+
+// readModuleVariables returns the variables declared in the Terraform files of a local module,
+// such as its variables.tf.
+func readModuleVariables(dir string) ([]moduleVariable, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Terraform files found in %s", dir)
+	}
+
+	parser := hclparse.NewParser()
+	var variables []moduleVariable
+	for _, filename := range files {
+		file, diags := parser.ParseHCLFile(filename)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("cannot parse %s: %s", filename, diags.Error())
+		}
+
+		content, _, diags := file.Body.PartialContent(moduleSchema)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("cannot read %s: %s", filename, diags.Error())
+		}
+
+		for _, block := range content.Blocks {
+			variable, err := readModuleVariable(block, file.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read %s: %w", filename, err)
+			}
+			variables = append(variables, variable)
+		}
+	}
+
+	return variables, nil
+}
+
+func readModuleVariable(block *hcl.Block, src []byte) (moduleVariable, error) {
+	variable := moduleVariable{
+		Name: block.Labels[0],
+		Type: cty.DynamicPseudoType,
+	}
+
+	content, _, diags := block.Body.PartialContent(variableSchema)
+	if diags.HasErrors() {
+		return variable, fmt.Errorf("variable %q: %s", variable.Name, diags.Error())
+	}
+
+	if attr, ok := content.Attributes["type"]; ok {
+		variable.TypeExpr = string(attr.Expr.Range().SliceBytes(src))
+		// the types the parser does not understand, e.g. with optional attributes, are not checked
+		if ty, diags := typeexpr.TypeConstraint(attr.Expr); !diags.HasErrors() {
+			variable.Type = ty
+		}
+	}
+
+	if attr, ok := content.Attributes["description"]; ok {
+		if value, diags := attr.Expr.Value(nil); !diags.HasErrors() && value.Type() == cty.String && value.IsKnown() && !value.IsNull() {
+			variable.Description = value.AsString()
+		}
+	}
+
+	if attr, ok := content.Attributes["default"]; ok {
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return variable, fmt.Errorf("variable %q: cannot evaluate the default value: %s", variable.Name, diags.Error())
+		}
+		if value.IsNull() {
+			variable.Nullable = true
+		} else {
+			data, err := ctyjson.SimpleJSONValue{Value: value}.MarshalJSON()
+			if err != nil {
+				return variable, fmt.Errorf("variable %q: %w", variable.Name, err)
+			}
+			variable.Default = &apiextensionsv1.JSON{Raw: data}
+		}
+	}
+
+	return variable, nil
+}
+
+// variableValue converts the value of a variable given on the command line to JSON.
+// A string variable takes the value as is, and the other types a JSON value.
+// The value of a variable of unknown type is taken as JSON when valid, or as a string.
+func variableValue(ty cty.Type, value string) (*apiextensionsv1.JSON, error) {
+	switch {
+	case ty.Equals(cty.String):
+		return stringValue(value)
+	case ty.Equals(cty.DynamicPseudoType):
+		if json.Valid([]byte(value)) {
+			return &apiextensionsv1.JSON{Raw: []byte(value)}, nil
+		}
+		return stringValue(value)
+	}
+
+	if _, err := ctyjson.Unmarshal([]byte(value), ty); err != nil {
+		return nil, fmt.Errorf("%q is not a valid %s: %w", value, typeexpr.TypeString(ty), err)
+	}
+	return &apiextensionsv1.JSON{Raw: []byte(value)}, nil
+}
+
+func stringValue(value string) (*apiextensionsv1.JSON, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return &apiextensionsv1.JSON{Raw: data}, nil
+}
@@ -0,0 +1,111 @@
+package tfctl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// OutputJSON prints the result of a command as JSON.
+	OutputJSON = "json"
+	// OutputYAML prints the result of a command as YAML.
+	OutputYAML = "yaml"
+)
+
+// The exit codes of tfctl. With --detailed-exitcode, the commands reporting changes
+// exit with ExitCodeChangesPending when changes are pending, like terraform plan -detailed-exitcode.
+const (
+	ExitCodeNoChanges      = 0
+	ExitCodeError          = 1
+	ExitCodeChangesPending = 2
+)
+
+// ErrChangesPending is returned with --detailed-exitcode by the commands reporting changes,
+// when a plan is pending, a diff has changes or drift is detected.
+var ErrChangesPending = errors.New("changes pending")
+
+// commandResult is the result of a command changing a Terraform resource, printed with --output.
+type commandResult struct {
+	Action    string `json:"action"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	PlanID    string `json:"planId,omitempty"`
+	Message   string `json:"message"`
+	Output    string `json:"output,omitempty"`
+}
+
+func validateOutput(output string) error {
+	switch output {
+	case "", OutputJSON, OutputYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid output format %q, must be one of: %s, %s", output, OutputJSON, OutputYAML)
+}
+
+// machineReadable returns true if the results are printed as JSON or YAML.
+func (c *CLI) machineReadable() bool {
+	return c.output == OutputJSON || c.output == OutputYAML
+}
+
+// messages returns the writer of the human readable messages of a command. When the results are
+// machine readable, the messages are written to stderr so that out holds the result only.
+func (c *CLI) messages(out io.Writer) io.Writer {
+	if c.machineReadable() {
+		return os.Stderr
+	}
+	return out
+}
+
+// printObject prints an object in the output format.
+func (c *CLI) printObject(out io.Writer, obj any) error {
+	switch c.output {
+	case OutputYAML:
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	default:
+		data, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	}
+}
+
+// printJSON prints a JSON document in the output format.
+func (c *CLI) printJSON(out io.Writer, data []byte) error {
+	if c.output == OutputYAML {
+		var err error
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return err
+		}
+	}
+	_, err := out.Write(data)
+	return err
+}
+
+// printResult prints the result of a command, as its message or in the output format.
+func (c *CLI) printResult(out io.Writer, result commandResult) error {
+	if c.machineReadable() {
+		return c.printObject(out, result)
+	}
+	_, err := fmt.Fprintf(out, " %s\n", result.Message)
+	return err
+}
+
+// changesPending returns ErrChangesPending with --detailed-exitcode when changes are pending.
+func (c *CLI) changesPending(pending bool) error {
+	if pending && c.detailedExitCode {
+		return ErrChangesPending
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package tfctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/fluxcd/pkg/apis/meta"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestOutput(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	jsonPlan, err := gzipEncode([]byte(`{"format_version":"1.2","resource_changes":[{"address":"null_resource.a"}]}`))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&infrav1.Terraform{
+			ObjectMeta: metav1.ObjectMeta{Name: "planned", Namespace: "default"},
+			Spec:       infrav1.TerraformSpec{StoreReadablePlan: "json"},
+			Status: infrav1.TerraformStatus{
+				Conditions: []metav1.Condition{{Type: meta.ReadyCondition, Status: metav1.ConditionUnknown,
+					Reason: "TerraformPlannedWithChanges", Message: "Plan generated"}},
+				Plan:                infrav1.PlanStatus{Pending: "plan-main-1234"},
+				LastAppliedRevision: "main@sha1:1234",
+			},
+		},
+		&infrav1.Terraform{
+			ObjectMeta: metav1.ObjectMeta{Name: "applied", Namespace: "default"},
+			Spec:       infrav1.TerraformSpec{StoreReadablePlan: "json"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "tfplan-default-planned.json", Namespace: "default"},
+			Data:       map[string][]byte{"tfplan": jsonPlan},
+		},
+	).Build()
+	c := &CLI{client: kubeClient, namespace: "default", output: OutputJSON}
+
+	// printing a result object for the mutating commands
+	out := &bytes.Buffer{}
+	g.Expect(c.Reconcile(out, "applied")).To(Succeed())
+	g.Expect(out.String()).To(MatchJSON(`{"action":"reconcile","namespace":"default","name":"applied","message":"Reconcile requested for default/applied"}`))
+
+	c.output = OutputYAML
+	out.Reset()
+	g.Expect(c.Suspend(out, "applied")).To(Succeed())
+	g.Expect(out.String()).To(MatchYAML("action: suspend\nnamespace: default\nname: applied\nmessage: Reconciliation suspended for default/applied\n"))
+
+	// printing the summary of the resources
+	c.output = OutputJSON
+	out.Reset()
+	g.Expect(c.Get(out)).To(Succeed())
+	summaries := []terraformSummary{}
+	g.Expect(json.Unmarshal(out.Bytes(), &summaries)).To(Succeed())
+	g.Expect(summaries).To(HaveLen(2))
+
+	out.Reset()
+	g.Expect(c.GetTerraform(out, "planned")).To(Succeed())
+	summary := terraformSummary{}
+	g.Expect(json.Unmarshal(out.Bytes(), &summary)).To(Succeed())
+	g.Expect(summary).To(And(
+		HaveField("Ready", metav1.ConditionUnknown),
+		HaveField("PlanPending", true),
+		HaveField("PendingPlan", "plan-main-1234"),
+		HaveField("LastAppliedRevision", "main@sha1:1234"),
+	))
+
+	// printing the JSON plan, with the exit code of a pending plan
+	out.Reset()
+	g.Expect(c.ShowPlan(out, "planned")).To(Succeed())
+	g.Expect(out.String()).To(MatchJSON(`{"format_version":"1.2","resource_changes":[{"address":"null_resource.a"}]}`))
+
+	c.detailedExitCode = true
+	out.Reset()
+	g.Expect(c.ShowPlan(out, "planned")).To(MatchError(ErrChangesPending))
+	out.Reset()
+	g.Expect(c.ShowPlan(out, "applied")).To(Succeed())
+	g.Expect(out.String()).To(MatchJSON(`{"pending":false}`))
+
+	c.output = OutputYAML
+	out.Reset()
+	g.Expect(c.ShowPlan(out, "planned")).To(MatchError(ErrChangesPending))
+	g.Expect(out.String()).To(MatchYAML("format_version: \"1.2\"\nresource_changes:\n- address: null_resource.a\n"))
+	out.Reset()
+	g.Expect(c.ShowPlan(out, "applied")).To(Succeed())
+	g.Expect(out.String()).To(MatchYAML("pending: false\n"))
+
+	// rejecting unknown output formats
+	g.Expect(validateOutput("table")).To(MatchError(`invalid output format "table", must be one of: json, yaml`))
+}
@@ -25,9 +25,12 @@ func (c *CLI) Reconcile(out io.Writer, resource string) error {
 		return err
 	}
 
-	fmt.Fprintf(out, " Reconcile requested for %s/%s\n", c.namespace, resource)
-
-	return nil
+	return c.printResult(out, commandResult{
+		Action:    "reconcile",
+		Namespace: c.namespace,
+		Name:      resource,
+		Message:   fmt.Sprintf("Reconcile requested for %s/%s", c.namespace, resource),
+	})
 }
 
 func requestReconciliation(ctx context.Context, kubeClient client.Client, namespacedName types.NamespacedName) error {
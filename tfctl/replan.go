@@ -36,7 +36,7 @@ func (c *CLI) Replan(ctx context.Context, out io.Writer, resource string) error
 	if err := requestReconciliation(ctx, c.client, key); err != nil {
 		return err
 	}
-	fmt.Fprintf(out, " Replan requested for %s/%s\n", c.namespace, resource)
+	fmt.Fprintf(c.messages(out), " Replan requested for %s/%s\n", c.namespace, resource)
 
 	// wait for the plan to be ready, 4 loops, 30 seconds each
 	if err := wait.PollUntilContextTimeout(ctx, 1*time.Second, 120*time.Second, true, func(ctx context.Context) (bool, error) {
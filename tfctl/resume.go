@@ -18,9 +18,10 @@ func (c *CLI) Resume(out io.Writer, resource string) error {
 			return fmt.Errorf("failed to resume reconciliation for all Terraform resources: %w", err)
 		}
 
-		fmt.Fprint(out, " Reconciliation resumed for all Terraform resources\n")
-
-		return nil
+		return c.printResult(out, commandResult{
+			Action:  "resume",
+			Message: "Reconciliation resumed for all Terraform resources",
+		})
 	}
 
 	key := types.NamespacedName{
@@ -33,9 +34,12 @@ func (c *CLI) Resume(out io.Writer, resource string) error {
 		return err
 	}
 
-	fmt.Fprintf(out, " Reconciliation resumed for %s/%s\n", c.namespace, resource)
-
-	return nil
+	return c.printResult(out, commandResult{
+		Action:    "resume",
+		Namespace: c.namespace,
+		Name:      resource,
+		Message:   fmt.Sprintf("Reconciliation resumed for %s/%s", c.namespace, resource),
+	})
 }
 
 func resumeAllReconciliation(ctx context.Context, kubeClient client.Client) error {
@@ -15,6 +15,14 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 )
 
+// savedPlanAnnotation is the annotation of the plan id on the stored plans.
+const savedPlanAnnotation = "savedPlan"
+
+// noPlanPending is printed instead of the JSON plan when no plan is pending.
+type noPlanPending struct {
+	Pending bool `json:"pending"`
+}
+
 func gzipDecode(encodedPlan []byte) ([]byte, error) {
 	re := bytes.NewReader(encodedPlan)
 	gr, err := gzip.NewReader(re)
@@ -33,7 +41,8 @@ func gzipDecode(encodedPlan []byte) ([]byte, error) {
 	return o, nil
 }
 
-// ShowPlan displays the plan for the given Terraform resource
+// ShowPlan displays the plan for the given Terraform resource. With --output, the JSON plan
+// is printed, which requires spec.storeReadablePlan to be 'json'.
 func (c *CLI) ShowPlan(out io.Writer, resource string) error {
 	key := types.NamespacedName{
 		Name:      resource,
@@ -43,28 +52,42 @@ func (c *CLI) ShowPlan(out io.Writer, resource string) error {
 	if err := c.client.Get(context.TODO(), key, terraform); err != nil {
 		return fmt.Errorf("resource %s not found", resource)
 	}
+	pending := terraform.Status.Plan.Pending != ""
+
+	if c.machineReadable() {
+		if !pending {
+			return c.printObject(out, noPlanPending{})
+		}
+		if terraform.Spec.StoreReadablePlan != "json" {
+			return fmt.Errorf("no JSON plan available, please set spec.storeReadablePlan to 'json'")
+		}
+		data, err := c.readJSONPlan(context.TODO(), *terraform)
+		if err != nil {
+			return err
+		}
+		if err := c.printJSON(out, data); err != nil {
+			return err
+		}
+		return c.changesPending(pending)
+	}
 
 	if terraform.Spec.StoreReadablePlan == "" || terraform.Spec.StoreReadablePlan == "none" {
 		fmt.Fprintln(out, "no readable plan available")
 		fmt.Fprintln(out, "please set spec.storeReadablePlan to either 'human' or 'json'")
-		return nil
+		return c.changesPending(pending)
 	}
 
-	if terraform.Status.Plan.Pending == "" {
+	if !pending {
 		fmt.Fprintln(out, "There is no plan pending.")
 		return nil
 	}
 
 	if terraform.Spec.StoreReadablePlan == "human" {
-		planKey := types.NamespacedName{
-			Name:      fmt.Sprintf("tfplan-%s-%s", terraform.WorkspaceName(), resource),
-			Namespace: c.namespace,
-		}
-		var tfplanCM corev1.ConfigMap
-		if err := c.client.Get(context.TODO(), planKey, &tfplanCM); err != nil {
-			return fmt.Errorf("plan %s not found", planKey)
+		plan, err := c.readHumanPlan(context.TODO(), *terraform)
+		if err != nil {
+			return err
 		}
-		fmt.Fprintln(out, tfplanCM.Data["tfplan"])
+		fmt.Fprintln(out, plan.Data["tfplan"])
 
 		cond := apimeta.FindStatusCondition(terraform.Status.Conditions, meta.ReadyCondition)
 		if cond != nil {
@@ -73,25 +96,49 @@ func (c *CLI) ShowPlan(out io.Writer, resource string) error {
 				// do nothing
 			} else {
 				fmt.Fprintf(out, "To set the field, you can also run:\n\n  tfctl approve %s -f filename.yaml \n", resource)
+				fmt.Fprintf(out, "\nTo approve the plan in-cluster, without GitOps, run:\n\n  tfctl approve %s --in-cluster \n", resource)
 			}
 		}
 
 	} else if terraform.Spec.StoreReadablePlan == "json" {
-		planKey := types.NamespacedName{
-			Name:      fmt.Sprintf("tfplan-%s-%s.json", terraform.WorkspaceName(), resource),
-			Namespace: c.namespace,
-		}
-		planSecret := corev1.Secret{}
-		if err := c.client.Get(context.TODO(), planKey, &planSecret); err != nil {
-			return fmt.Errorf("plan for resource %s not found", resource)
-		}
-
-		data, err := gzipDecode(planSecret.Data["tfplan"])
+		data, err := c.readJSONPlan(context.TODO(), *terraform)
 		if err != nil {
-			return fmt.Errorf("failed to decode plan for resources %s: %s", resource, err)
+			return err
 		}
 		fmt.Fprint(out, string(data))
 	}
 
-	return nil
+	return c.changesPending(pending)
+}
+
+// readJSONPlan returns the JSON plan stored for the given Terraform resource.
+func (c *CLI) readJSONPlan(ctx context.Context, terraform infrav1.Terraform) ([]byte, error) {
+	planKey := types.NamespacedName{
+		Name:      fmt.Sprintf("tfplan-%s-%s.json", terraform.WorkspaceName(), terraform.Name),
+		Namespace: terraform.Namespace,
+	}
+	planSecret := corev1.Secret{}
+	if err := c.client.Get(ctx, planKey, &planSecret); err != nil {
+		return nil, fmt.Errorf("plan for resource %s not found", terraform.Name)
+	}
+
+	data, err := gzipDecode(planSecret.Data["tfplan"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode plan for resources %s: %s", terraform.Name, err)
+	}
+	return data, nil
+}
+
+// readHumanPlan returns the ConfigMap of the human readable plan stored for the given Terraform resource.
+// The plan id is in its savedPlan annotation.
+func (c *CLI) readHumanPlan(ctx context.Context, terraform infrav1.Terraform) (*corev1.ConfigMap, error) {
+	planKey := types.NamespacedName{
+		Name:      fmt.Sprintf("tfplan-%s-%s", terraform.WorkspaceName(), terraform.Name),
+		Namespace: terraform.Namespace,
+	}
+	tfplanCM := &corev1.ConfigMap{}
+	if err := c.client.Get(ctx, planKey, tfplanCM); err != nil {
+		return nil, fmt.Errorf("plan %s not found", planKey)
+	}
+	return tfplanCM, nil
 }
@@ -0,0 +1,144 @@
+package tfctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+)
+
+var (
+	stateOperationPollInterval = 2 * time.Second
+	stateOperationTimeout      = 10 * time.Minute
+)
+
+// StateOperation requests a state operation on a Terraform resource, waits for the runner
+// to execute it against the configured backend and workspace, and prints its output.
+func (c *CLI) StateOperation(ctx context.Context, out io.Writer, resource string, operation string, args []string) error {
+	key := types.NamespacedName{
+		Name:      resource,
+		Namespace: c.namespace,
+	}
+
+	req := infrav1.StateOperationRequest{
+		ID:        strconv.FormatInt(time.Now().UnixNano(), 10),
+		Operation: operation,
+		Args:      args,
+	}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	terraform := &infrav1.Terraform{}
+	if err := c.client.Get(ctx, key, terraform); err != nil {
+		return err
+	}
+	if terraform.Spec.Suspend {
+		return fmt.Errorf("resource %s/%s is suspended, resume it to run state operations", c.namespace, resource)
+	}
+
+	if err := requestStateOperation(ctx, c.client, key, req, c.whoAmI(ctx)); err != nil {
+		return err
+	}
+	if err := requestReconciliation(ctx, c.client, key); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.messages(out), " Requested terraform %s on %s/%s, waiting for the runner\n", req.String(), c.namespace, resource)
+
+	var result *infrav1.StateOperationStatus
+	err := wait.PollUntilContextTimeout(ctx, stateOperationPollInterval, stateOperationTimeout, true, func(ctx context.Context) (bool, error) {
+		if err := c.client.Get(ctx, key, terraform); err != nil {
+			return false, nil
+		}
+		if s := terraform.Status.StateOperation; s != nil && s.ID == req.ID {
+			result = s
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("timed out waiting for terraform %s: %w", req.String(), err)
+	}
+
+	if !result.Succeeded {
+		return fmt.Errorf("terraform %s failed: %s", req.String(), result.Message)
+	}
+
+	var output string
+	if result.OutputSecretName != "" {
+		secret := &corev1.Secret{}
+		if err := c.client.Get(ctx, types.NamespacedName{Namespace: c.namespace, Name: result.OutputSecretName}, secret); err != nil {
+			return fmt.Errorf("unable to get the output of terraform %s: %w", req.String(), err)
+		}
+		output = string(secret.Data[infrav1.StateOperationOutputDataKey])
+	}
+
+	if c.machineReadable() {
+		return c.printObject(out, commandResult{
+			Action:    "state " + operation,
+			Namespace: c.namespace,
+			Name:      resource,
+			Message:   result.Message,
+			Output:    output,
+		})
+	}
+	if output == "" {
+		fmt.Fprintf(out, " %s\n", result.Message)
+		return nil
+	}
+	fmt.Fprint(out, output)
+
+	return nil
+}
+
+// whoAmI returns the user name the API server authenticates the CLI as,
+// or an empty string if it cannot be determined.
+func (c *CLI) whoAmI(ctx context.Context) string {
+	review := &authenticationv1.SelfSubjectReview{}
+	if err := c.client.Create(ctx, review); err == nil {
+		return review.Status.UserInfo.Username
+	}
+
+	if c.restConfig != nil {
+		if c.restConfig.Impersonate.UserName != "" {
+			return c.restConfig.Impersonate.UserName
+		}
+		return c.restConfig.Username
+	}
+
+	return ""
+}
+
+func requestStateOperation(ctx context.Context, kubeClient client.Client, namespacedName types.NamespacedName, req infrav1.StateOperationRequest, requestedBy string) error {
+	value, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		terraform := &infrav1.Terraform{}
+		if err := kubeClient.Get(ctx, namespacedName, terraform); err != nil {
+			return err
+		}
+		patch := client.MergeFrom(terraform.DeepCopy())
+		ann := terraform.GetAnnotations()
+		if ann == nil {
+			ann = map[string]string{}
+		}
+		ann[infrav1.StateOperationAnnotation] = string(value)
+		ann[infrav1.StateOperationRequestedByAnnotation] = requestedBy
+		terraform.SetAnnotations(ann)
+		return kubeClient.Patch(ctx, terraform, patch)
+	})
+}
@@ -0,0 +1,94 @@
+package tfctl
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// handleStateOperations plays the controller: it answers every state operation
+// request with the given result, until the context is cancelled.
+func handleStateOperations(ctx context.Context, kubeClient client.Client, key types.NamespacedName, result infrav1.StateOperationStatus) {
+	for ctx.Err() == nil {
+		terraform := &infrav1.Terraform{}
+		if err := kubeClient.Get(ctx, key, terraform); err == nil {
+			if req, err := terraform.GetStateOperationRequest(); err == nil && req != nil {
+				result.ID = req.ID
+				result.Operation = req.Operation
+				result.Args = req.Args
+				terraform.Status.StateOperation = &result
+				_ = kubeClient.Update(ctx, terraform)
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStateOperation(t *testing.T) {
+	g := NewWithT(t)
+
+	stateOperationPollInterval = 10 * time.Millisecond
+	stateOperationTimeout = 5 * time.Second
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	terraform := &infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-resource", Namespace: "default"},
+	}
+	outputSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tfstate-operation-my-resource", Namespace: "default"},
+		Data:       map[string][]byte{infrav1.StateOperationOutputDataKey: []byte("null_resource.a\nnull_resource.b\n")},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(terraform, outputSecret).Build()
+	key := types.NamespacedName{Namespace: "default", Name: "my-resource"}
+
+	c := &CLI{client: kubeClient, namespace: "default"}
+
+	// printing the output of a list
+	ctx, cancel := context.WithCancel(context.TODO())
+	go handleStateOperations(ctx, kubeClient, key, infrav1.StateOperationStatus{
+		Succeeded:        true,
+		Message:          "listed 2 resources",
+		OutputSecretName: "tfstate-operation-my-resource",
+	})
+	out := &bytes.Buffer{}
+	g.Expect(c.StateOperation(context.TODO(), out, "my-resource", infrav1.StateOperationList, nil)).To(Succeed())
+	g.Expect(out.String()).To(ContainSubstring("Requested terraform state list on default/my-resource"))
+	g.Expect(out.String()).To(HaveSuffix("null_resource.a\nnull_resource.b\n"))
+	cancel()
+
+	updated := &infrav1.Terraform{}
+	g.Expect(kubeClient.Get(context.TODO(), key, updated)).To(Succeed())
+	g.Expect(updated.Annotations[infrav1.StateOperationAnnotation]).To(ContainSubstring(`"operation":"list"`))
+	g.Expect(updated.Annotations[infrav1.StateOperationAnnotation]).ToNot(ContainSubstring("requestedBy"))
+	g.Expect(updated.Annotations).To(HaveKey(infrav1.StateOperationRequestedByAnnotation))
+
+	// returning the error of a failed operation
+	ctx, cancel = context.WithCancel(context.TODO())
+	go handleStateOperations(ctx, kubeClient, key, infrav1.StateOperationStatus{
+		Succeeded: false,
+		Message:   "Invalid source address",
+	})
+	err := c.StateOperation(context.TODO(), &bytes.Buffer{}, "my-resource", infrav1.StateOperationMv, []string{"null_resource.a", "null_resource.c"})
+	g.Expect(err).To(MatchError("terraform state mv null_resource.a null_resource.c failed: Invalid source address"))
+	cancel()
+
+	// rejecting invalid arguments before sending the request
+	err = c.StateOperation(context.TODO(), &bytes.Buffer{}, "my-resource", infrav1.StateOperationImport, []string{"aws_s3_bucket.b"})
+	g.Expect(err).To(MatchError(ContainSubstring("import expects an address and an id")))
+
+	err = c.StateOperation(context.TODO(), &bytes.Buffer{}, "my-resource", infrav1.StateOperationRm, []string{"-lock=false"})
+	g.Expect(err).To(MatchError(`invalid argument "-lock=false", state operations do not accept flags`))
+}
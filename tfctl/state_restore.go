@@ -0,0 +1,401 @@
+package tfctl
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// stateSnapshot is a state snapshot Secret written by the runner before an apply.
+type stateSnapshot struct {
+	secret    corev1.Secret
+	revision  string
+	createdAt time.Time
+}
+
+// RestoreState restores the state of a Terraform resource using the Kubernetes backend
+// from a snapshot, selected by name, source revision or time. The difference in resources
+// between the snapshot and the current state is shown before asking for confirmation.
+func (c *CLI) RestoreState(ctx context.Context, out io.Writer, in io.Reader, resource string, to string, yes bool) error {
+	key := types.NamespacedName{
+		Name:      resource,
+		Namespace: c.namespace,
+	}
+	terraform := infrav1.Terraform{}
+	if err := c.client.Get(ctx, key, &terraform); err != nil {
+		return fmt.Errorf("resource %s not found", resource)
+	}
+
+	stateKey, err := kubernetesBackendStateKey(terraform)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := c.findStateSnapshot(ctx, terraform, to)
+	if err != nil {
+		return err
+	}
+
+	snapshotState, err := gzipDecode(snapshot.secret.Data[infrav1.StateBackupDataKey])
+	if err != nil {
+		return fmt.Errorf("failed to decode state snapshot %s: %w", snapshot.secret.Name, err)
+	}
+
+	stateSecret := corev1.Secret{}
+	if err := c.client.Get(ctx, stateKey, &stateSecret); err != nil {
+		return fmt.Errorf("state secret %s not found: %w", stateKey.Name, err)
+	}
+	currentState, err := gzipDecode(stateSecret.Data["tfstate"])
+	if err != nil {
+		return fmt.Errorf("failed to decode state %s: %w", stateKey.Name, err)
+	}
+
+	added, removed, err := diffStateResources(currentState, snapshotState)
+	if err != nil {
+		return err
+	}
+
+	// the restore is reviewed on stderr when the result is machine readable
+	messages := c.messages(out)
+	result := commandResult{
+		Action:    "state restore",
+		Namespace: c.namespace,
+		Name:      resource,
+	}
+
+	fmt.Fprintf(messages, " Restoring %s/%s from snapshot %s (revision: %s, taken at: %s)\n",
+		c.namespace, resource, snapshot.secret.Name, snapshot.revision, snapshot.createdAt.Format(time.RFC3339))
+	if len(added) == 0 && len(removed) == 0 {
+		fmt.Fprintln(messages, " No difference in resources")
+	}
+	for _, address := range added {
+		fmt.Fprintf(messages, " + %s\n", address)
+	}
+	for _, address := range removed {
+		fmt.Fprintf(messages, " - %s\n", address)
+	}
+
+	if !yes {
+		fmt.Fprint(messages, " Restore the state? [y/N] ")
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			if c.machineReadable() {
+				result.Message = "Restore cancelled"
+				return c.printObject(out, result)
+			}
+			fmt.Fprintln(out, " Restore cancelled")
+			return nil
+		}
+	}
+
+	if err := c.checkStateUnlocked(ctx, stateKey); err != nil {
+		return err
+	}
+
+	backupName, err := c.backupCurrentState(ctx, terraform, currentState)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(messages, " Current state backed up in snapshot %s\n", backupName)
+
+	restored, err := bumpStateSerial(snapshotState, currentState)
+	if err != nil {
+		return err
+	}
+	encoded, err := gzipEncode(restored)
+	if err != nil {
+		return err
+	}
+
+	if err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		stateSecret := &corev1.Secret{}
+		if err := c.client.Get(ctx, stateKey, stateSecret); err != nil {
+			return err
+		}
+		patch := client.MergeFrom(stateSecret.DeepCopy())
+		stateSecret.Data["tfstate"] = encoded
+		return c.client.Patch(ctx, stateSecret, patch)
+	}); err != nil {
+		return fmt.Errorf("failed to restore state %s: %w", stateKey.Name, err)
+	}
+
+	result.Message = fmt.Sprintf("State of %s/%s restored from snapshot %s", c.namespace, resource, snapshot.secret.Name)
+	if c.machineReadable() {
+		return c.printObject(out, result)
+	}
+	fmt.Fprintf(out, " %s\n", result.Message)
+	return nil
+}
+
+// kubernetesBackendStateKey returns the key of the state Secret of the Kubernetes backend.
+func kubernetesBackendStateKey(terraform infrav1.Terraform) (types.NamespacedName, error) {
+	suffix := terraform.Name
+	if spec := terraform.Spec.BackendConfig; spec != nil {
+		if spec.Disable || spec.Sharded || spec.CustomConfiguration != "" || spec.S3 != nil || spec.GCS != nil || spec.AzureRM != nil || spec.PG != nil {
+			return types.NamespacedName{}, fmt.Errorf("state restore is only supported with the Kubernetes backend")
+		}
+		if spec.SecretSuffix != "" {
+			suffix = spec.SecretSuffix
+		}
+	}
+	if terraform.Spec.Cloud != nil {
+		return types.NamespacedName{}, fmt.Errorf("state restore is only supported with the Kubernetes backend")
+	}
+
+	return types.NamespacedName{
+		Namespace: terraform.Namespace,
+		Name:      fmt.Sprintf("tfstate-%s-%s", terraform.WorkspaceName(), suffix),
+	}, nil
+}
+
+// findStateSnapshot returns the snapshot matching the name, or the latest snapshot taken at or before
+// the given RFC3339 time, or the snapshot of the given source revision. A revision matching several
+// snapshots is ambiguous, and the snapshot must then be given by its name.
+func (c *CLI) findStateSnapshot(ctx context.Context, terraform infrav1.Terraform, to string) (*stateSnapshot, error) {
+	secrets := &corev1.SecretList{}
+	if err := c.client.List(ctx, secrets,
+		client.InNamespace(terraform.Namespace),
+		client.MatchingLabels{infrav1.StateBackupLabel: infrav1.StateBackupLabelValue(terraform.Name)},
+	); err != nil {
+		return nil, err
+	}
+
+	snapshots := []stateSnapshot{}
+	for _, secret := range secrets.Items {
+		annotations := secret.GetAnnotations()
+		// the label only holds a truncated name for the long names
+		if name, ok := annotations[infrav1.StateBackupNameAnnotation]; ok && name != terraform.Name {
+			continue
+		}
+		if annotations[infrav1.StateBackupWorkspaceAnnotation] != terraform.WorkspaceName() {
+			continue
+		}
+		createdAt, err := time.Parse(time.RFC3339, annotations[infrav1.StateBackupCreatedAtAnnotation])
+		if err != nil {
+			createdAt = secret.CreationTimestamp.Time
+		}
+		snapshots = append(snapshots, stateSnapshot{
+			secret:    secret,
+			revision:  annotations[infrav1.StateBackupRevisionAnnotation],
+			createdAt: createdAt,
+		})
+	}
+
+	// latest first
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].createdAt.After(snapshots[j].createdAt)
+	})
+
+	for i := range snapshots {
+		if snapshots[i].secret.Name == to {
+			return &snapshots[i], nil
+		}
+	}
+
+	if at, err := time.Parse(time.RFC3339, to); err == nil {
+		for i := range snapshots {
+			if !snapshots[i].createdAt.After(at) {
+				return &snapshots[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no state snapshot of %s found for %s", terraform.Name, to)
+	}
+
+	// the revision matches exactly, or by a prefix of its digest
+	var matches []*stateSnapshot
+	for i := range snapshots {
+		if snapshots[i].revision != "" && revisionMatches(snapshots[i].revision, to) {
+			matches = append(matches, &snapshots[i])
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no state snapshot of %s found for %s", terraform.Name, to)
+	case 1:
+		return matches[0], nil
+	}
+
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, m.secret.Name)
+	}
+	return nil, fmt.Errorf("%s matches %d state snapshots of %s, please give the name of one of them: %s",
+		to, len(matches), terraform.Name, strings.Join(names, ", "))
+}
+
+// checkStateUnlocked returns an error if the state is locked by a running terraform command.
+func (c *CLI) checkStateUnlocked(ctx context.Context, stateKey types.NamespacedName) error {
+	lease := &coordinationv1.Lease{}
+	leaseKey := types.NamespacedName{Namespace: stateKey.Namespace, Name: "lock-" + stateKey.Name}
+	if err := c.client.Get(ctx, leaseKey, lease); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity != "" {
+		return fmt.Errorf("state %s is locked, retry when the current run is finished", stateKey.Name)
+	}
+	return nil
+}
+
+// backupCurrentState saves the current state as a snapshot, so that a restore can be reverted.
+func (c *CLI) backupCurrentState(ctx context.Context, terraform infrav1.Terraform, state []byte) (string, error) {
+	encoded, err := gzipEncode(state)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	serial, _ := stateSerial(state)
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("tfstate-backup-%s-%s-%s-%s", terraform.WorkspaceName(), terraform.Name, now.Format("20060102150405"), utilrand.String(5)),
+			Namespace: terraform.Namespace,
+			Labels: map[string]string{
+				infrav1.StateBackupLabel:        infrav1.StateBackupLabelValue(terraform.Name),
+				infrav1.StateBackupTriggerLabel: infrav1.StateBackupTriggerRestore,
+			},
+			Annotations: map[string]string{
+				"encoding":                             "gzip",
+				infrav1.StateBackupNameAnnotation:      terraform.Name,
+				infrav1.StateBackupWorkspaceAnnotation: terraform.WorkspaceName(),
+				infrav1.StateBackupSerialAnnotation:    strconv.FormatInt(serial, 10),
+				infrav1.StateBackupCreatedAtAnnotation: now.Format(time.RFC3339),
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{infrav1.StateBackupDataKey: encoded},
+	}
+	if err := c.client.Create(ctx, &secret); err != nil {
+		return "", fmt.Errorf("failed to back up the current state: %w", err)
+	}
+	return secret.Name, nil
+}
+
+type stateResource struct {
+	Module    string `json:"module"`
+	Mode      string `json:"mode"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Instances []struct {
+		IndexKey interface{} `json:"index_key"`
+	} `json:"instances"`
+}
+
+func stateSerial(state []byte) (int64, error) {
+	var s struct {
+		Serial int64 `json:"serial"`
+	}
+	if err := json.Unmarshal(state, &s); err != nil {
+		return 0, fmt.Errorf("unable to parse the state: %w", err)
+	}
+	return s.Serial, nil
+}
+
+// stateResourceAddresses returns the addresses of the resource instances of a state.
+func stateResourceAddresses(state []byte) (map[string]bool, error) {
+	var s struct {
+		Resources []stateResource `json:"resources"`
+	}
+	if err := json.Unmarshal(state, &s); err != nil {
+		return nil, fmt.Errorf("unable to parse the state: %w", err)
+	}
+
+	addresses := map[string]bool{}
+	for _, r := range s.Resources {
+		address := r.Type + "." + r.Name
+		if r.Mode == "data" {
+			address = "data." + address
+		}
+		if r.Module != "" {
+			address = r.Module + "." + address
+		}
+		for _, instance := range r.Instances {
+			switch key := instance.IndexKey.(type) {
+			case nil:
+				addresses[address] = true
+			case string:
+				addresses[fmt.Sprintf("%s[%q]", address, key)] = true
+			default:
+				addresses[fmt.Sprintf("%s[%v]", address, key)] = true
+			}
+		}
+	}
+	return addresses, nil
+}
+
+// diffStateResources returns the resources added and removed by restoring the snapshot over the current state.
+func diffStateResources(current, snapshot []byte) ([]string, []string, error) {
+	currentAddresses, err := stateResourceAddresses(current)
+	if err != nil {
+		return nil, nil, err
+	}
+	snapshotAddresses, err := stateResourceAddresses(snapshot)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	added := []string{}
+	for address := range snapshotAddresses {
+		if !currentAddresses[address] {
+			added = append(added, address)
+		}
+	}
+	removed := []string{}
+	for address := range currentAddresses {
+		if !snapshotAddresses[address] {
+			removed = append(removed, address)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed, nil
+}
+
+// bumpStateSerial sets the serial of the snapshot above the serial of the current state,
+// so that the restored state is seen as the latest one.
+func bumpStateSerial(snapshot, current []byte) ([]byte, error) {
+	serial, err := stateSerial(current)
+	if err != nil {
+		return nil, err
+	}
+
+	state := map[string]json.RawMessage{}
+	if err := json.Unmarshal(snapshot, &state); err != nil {
+		return nil, fmt.Errorf("unable to parse the state snapshot: %w", err)
+	}
+	state["serial"] = json.RawMessage(strconv.FormatInt(serial+1, 10))
+	return json.MarshalIndent(state, "", "  ")
+}
+
+func gzipEncode(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,119 @@
+package tfctl
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newStateSnapshotSecret(g *WithT, name, revision, createdAt, state string) *corev1.Secret {
+	encoded, err := gzipEncode([]byte(state))
+	g.Expect(err).ToNot(HaveOccurred())
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				infrav1.StateBackupLabel:        "my-resource",
+				infrav1.StateBackupTriggerLabel: infrav1.StateBackupTriggerApply,
+			},
+			Annotations: map[string]string{
+				infrav1.StateBackupWorkspaceAnnotation: "default",
+				infrav1.StateBackupRevisionAnnotation:  revision,
+				infrav1.StateBackupCreatedAtAnnotation: createdAt,
+			},
+		},
+		Data: map[string][]byte{infrav1.StateBackupDataKey: encoded},
+	}
+}
+
+func TestRestoreState(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(coordinationv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	terraform := &infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-resource", Namespace: "default"},
+	}
+
+	current, err := gzipEncode([]byte(`{"serial": 7, "resources": [
+		{"mode": "managed", "type": "null_resource", "name": "a", "instances": [{}]}
+	]}`))
+	g.Expect(err).ToNot(HaveOccurred())
+	stateSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tfstate-default-my-resource", Namespace: "default"},
+		Data:       map[string][]byte{"tfstate": current},
+	}
+
+	older := newStateSnapshotSecret(g, "tfstate-backup-default-my-resource-20240101000000", "main@sha1:1111111", "2024-01-01T00:00:00Z",
+		`{"serial": 3, "resources": [
+			{"mode": "managed", "type": "null_resource", "name": "a", "instances": [{}]},
+			{"module": "module.m", "mode": "managed", "type": "null_resource", "name": "b", "instances": [{"index_key": 0}, {"index_key": "x"}]}
+		]}`)
+	newer := newStateSnapshotSecret(g, "tfstate-backup-default-my-resource-20240201000000", "main@sha1:2222222", "2024-02-01T00:00:00Z",
+		`{"serial": 6, "resources": []}`)
+	oldest := newStateSnapshotSecret(g, "tfstate-backup-default-my-resource-20230601000000-abcde", "main@sha1:1111999", "2023-06-01T00:00:00Z",
+		`{"serial": 1, "resources": []}`)
+
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(terraform, stateSecret, older, newer, oldest).Build()
+	cli := &CLI{client: kubeClient, namespace: "default"}
+
+	// selecting snapshots by revision and by time
+	snapshot, err := cli.findStateSnapshot(context.TODO(), *terraform, "1111111")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(snapshot.secret.Name).To(Equal(older.Name))
+
+	snapshot, err = cli.findStateSnapshot(context.TODO(), *terraform, "2024-01-15T00:00:00Z")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(snapshot.secret.Name).To(Equal(older.Name))
+
+	_, err = cli.findStateSnapshot(context.TODO(), *terraform, "2023-01-01T00:00:00Z")
+	g.Expect(err).To(MatchError(ContainSubstring("no state snapshot")))
+
+	// matching revisions by a prefix of the sha only, and refusing ambiguous revisions
+	_, err = cli.findStateSnapshot(context.TODO(), *terraform, "main")
+	g.Expect(err).To(MatchError(ContainSubstring("no state snapshot")))
+
+	_, err = cli.findStateSnapshot(context.TODO(), *terraform, "1111")
+	g.Expect(err).To(MatchError(ContainSubstring("1111 matches 2 state snapshots of my-resource")))
+	g.Expect(err).To(MatchError(ContainSubstring(oldest.Name)))
+
+	// cancelling the restore when not confirmed
+	out := &bytes.Buffer{}
+	g.Expect(cli.RestoreState(context.TODO(), out, strings.NewReader("n\n"), "my-resource", "main@sha1:1111111", false)).To(Succeed())
+	g.Expect(out.String()).To(ContainSubstring(` + module.m.null_resource.b[0]`))
+	g.Expect(out.String()).To(ContainSubstring(` + module.m.null_resource.b["x"]`))
+	g.Expect(out.String()).To(ContainSubstring("Restore cancelled"))
+
+	// restoring the snapshot with a serial above the current one
+	out.Reset()
+	g.Expect(cli.RestoreState(context.TODO(), out, nil, "my-resource", "main@sha1:1111111", true)).To(Succeed())
+	g.Expect(out.String()).To(ContainSubstring("restored from snapshot " + older.Name))
+
+	restored := &corev1.Secret{}
+	g.Expect(kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "tfstate-default-my-resource"}, restored)).To(Succeed())
+	state, err := gzipDecode(restored.Data["tfstate"])
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(stateSerial(state)).To(Equal(int64(8)))
+	addresses, err := stateResourceAddresses(state)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(addresses).To(HaveLen(3))
+
+	// backing up the state which was replaced
+	snapshots := &corev1.SecretList{}
+	g.Expect(kubeClient.List(context.TODO(), snapshots)).To(Succeed())
+	g.Expect(snapshots.Items).To(HaveLen(5))
+}
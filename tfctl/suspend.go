@@ -18,9 +18,10 @@ func (c *CLI) Suspend(out io.Writer, resource string) error {
 			return fmt.Errorf("failed to suspend reconciliation for all Terraform resources: %w", err)
 		}
 
-		fmt.Fprint(out, " Reconciliation suspended for all Terraform resources\n")
-
-		return nil
+		return c.printResult(out, commandResult{
+			Action:  "suspend",
+			Message: "Reconciliation suspended for all Terraform resources",
+		})
 	}
 
 	key := types.NamespacedName{
@@ -33,9 +34,12 @@ func (c *CLI) Suspend(out io.Writer, resource string) error {
 		return err
 	}
 
-	fmt.Fprintf(out, " Reconciliation suspended for %s/%s\n", c.namespace, resource)
-
-	return nil
+	return c.printResult(out, commandResult{
+		Action:    "suspend",
+		Namespace: c.namespace,
+		Name:      resource,
+		Message:   fmt.Sprintf("Reconciliation suspended for %s/%s", c.namespace, resource),
+	})
 }
 
 func suspendAllReconciliation(ctx context.Context, kubeClient client.Client) error {
@@ -0,0 +1,5 @@
+resource "null_resource" "bucket" {
+  triggers = {
+    region = var.region
+  }
+}
@@ -0,0 +1,33 @@
+variable "region" {
+  type        = string
+  description = "The region of the bucket"
+}
+
+variable "replicas" {
+  type    = number
+  default = 2
+}
+
+variable "tags" {
+  type = map(string)
+  default = {
+    team = "platform"
+  }
+}
+
+variable "kms_key" {
+  type    = string
+  default = null
+}
+
+variable "settings" {
+  type = object({
+    versioning = optional(bool, true)
+  })
+  default = {}
+
+  validation {
+    condition     = var.settings != null
+    error_message = "The settings cannot be null."
+  }
+}
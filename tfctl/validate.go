@@ -0,0 +1,67 @@
+package tfctl
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	"sigs.k8s.io/yaml"
+)
+
+// terraformCRD is a copy of the Terraform CRD, kept in sync by make manifests,
+// to validate the Terraform resources without a cluster.
+//
+//go:embed crds/terraforms.yaml
+var terraformCRD []byte
+
+// validateTerraform validates a Terraform resource against the schema of the Terraform CRD, offline.
+func validateTerraform(terraform *infrav1.Terraform) error {
+	schema, err := crdSchema(terraformCRD, infrav1.GroupVersion.Version)
+	if err != nil {
+		return err
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(terraform)
+	if err != nil {
+		return err
+	}
+	delete(obj, "status")
+
+	result := validate.NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(obj)
+	if result.HasErrors() {
+		return fmt.Errorf("invalid Terraform resource %s/%s: %w", terraform.Namespace, terraform.Name, errors.Join(result.Errors...))
+	}
+	return nil
+}
+
+// crdSchema returns the OpenAPI schema of a version of a CRD.
+func crdSchema(data []byte, version string) (*spec.Schema, error) {
+	crd := apiextensionsv1.CustomResourceDefinition{}
+	if err := yaml.Unmarshal(data, &crd); err != nil {
+		return nil, fmt.Errorf("cannot read the CRD: %w", err)
+	}
+
+	for _, v := range crd.Spec.Versions {
+		if v.Name != version || v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		raw, err := json.Marshal(v.Schema.OpenAPIV3Schema)
+		if err != nil {
+			return nil, err
+		}
+		schema := &spec.Schema{}
+		if err := json.Unmarshal(raw, schema); err != nil {
+			return nil, fmt.Errorf("cannot read the schema of the CRD %s: %w", crd.Name, err)
+		}
+		return schema, nil
+	}
+
+	return nil, fmt.Errorf("version %s not found in the CRD %s", version, crd.Name)
+}
@@ -11,6 +11,22 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// versionInfo is the version printed with --output.
+type versionInfo struct {
+	TFController controllerVersion `json:"tfController"`
+	TFCtl        cliVersion        `json:"tfctl"`
+}
+
+type controllerVersion struct {
+	Manager string `json:"manager"`
+	Runner  string `json:"runner"`
+}
+
+type cliVersion struct {
+	Build   string `json:"build"`
+	Release string `json:"release"`
+}
+
 // Version prints the installed version of tf-controller and the tfctl cli
 func (c *CLI) Version(out io.Writer) error {
 	deployment := &appsv1.Deployment{}
@@ -44,6 +60,13 @@ func (c *CLI) Version(out io.Writer) error {
 		}
 	}
 
+	if c.machineReadable() {
+		return c.printObject(out, versionInfo{
+			TFController: controllerVersion{Manager: version, Runner: runnerVersion},
+			TFCtl:        cliVersion{Build: c.build, Release: c.release},
+		})
+	}
+
 	fmt.Fprintf(out, "tf-controller:\n  manager: %s\n  runner: %s\n", version, runnerVersion)
 	fmt.Fprintf(out, "tfctl:\n  build: %s\n  release: %s\n", c.build, c.release)
 	return nil
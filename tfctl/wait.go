@@ -0,0 +1,191 @@
+package tfctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/fluxcd/pkg/apis/meta"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	WaitForApplied   = "applied"
+	WaitForPlanned   = "planned"
+	WaitForReady     = "ready"
+	WaitForDriftFree = "drift-free"
+)
+
+var waitPollInterval = 2 * time.Second
+
+// waitFailureReasons are the reasons of the conditions failing a wait, as the object
+// does not reach the awaited state without a change. A failed post-apply webhook is not
+// one of them, as it leaves the object applied and ready.
+var waitFailureReasons = []string{
+	infrav1.TFExecInitFailedReason,
+	infrav1.TFExecPlanFailedReason,
+	infrav1.TFExecApplyFailedReason,
+	infrav1.RetryLimitReachedReason,
+	infrav1.HealthChecksFailedReason,
+	infrav1.VarsGenerationFailedReason,
+	infrav1.TemplateGenerationFailedReason,
+	infrav1.PostPlanningWebhookFailedReason,
+	infrav1.PreApplyWebhookFailedReason,
+}
+
+// Wait waits until the given Terraform resource is applied, planned, ready or drift-free, at the given
+// source revision if any, printing the changes of its conditions. It fails as soon as a condition reports
+// a failure at the awaited revision.
+func (c *CLI) Wait(ctx context.Context, out io.Writer, resource string, target string, revision string, timeout time.Duration) error {
+	switch target {
+	case WaitForApplied, WaitForPlanned, WaitForReady, WaitForDriftFree:
+	default:
+		return fmt.Errorf("invalid --for %q, must be one of: %s, %s, %s, %s",
+			target, WaitForApplied, WaitForPlanned, WaitForReady, WaitForDriftFree)
+	}
+
+	key := types.NamespacedName{
+		Name:      resource,
+		Namespace: c.namespace,
+	}
+
+	// the progress is written to stderr when the result is machine readable
+	messages := c.messages(out)
+	awaited := target
+	if revision != "" {
+		awaited += " at revision " + revision
+	}
+	fmt.Fprintf(messages, " Waiting for %s to be %s\n", key, awaited)
+
+	lastConditions := map[string]string{}
+	terraform := &infrav1.Terraform{}
+	err := wait.PollUntilContextTimeout(ctx, waitPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		if err := c.client.Get(ctx, key, terraform); err != nil {
+			// a missing or forbidden resource does not resolve by waiting, other errors may be transient
+			if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+				return false, err
+			}
+			return false, nil
+		}
+
+		for _, condition := range terraform.Status.Conditions {
+			line := fmt.Sprintf("%s %s (%s): %s", condition.Type, condition.Status, condition.Reason, condition.Message)
+			if lastConditions[condition.Type] != line {
+				fmt.Fprintf(messages, " ◎ %s\n", line)
+				lastConditions[condition.Type] = line
+			}
+		}
+
+		if terraform.Spec.Suspend {
+			return false, fmt.Errorf("%s is suspended", key)
+		}
+		if terraform.Status.ObservedGeneration != terraform.Generation {
+			return false, nil
+		}
+		if failure := waitFailure(*terraform, revision); failure != nil {
+			return false, fmt.Errorf("%s failed with %s: %s", key, failure.Reason, failure.Message)
+		}
+		return hasReached(*terraform, target, revision), nil
+	})
+	if err != nil {
+		if ctx.Err() == nil && wait.Interrupted(err) {
+			return fmt.Errorf("timed out waiting for %s to be %s", key, awaited)
+		}
+		return err
+	}
+
+	result := commandResult{
+		Action:    "wait",
+		Namespace: c.namespace,
+		Name:      resource,
+		Message:   fmt.Sprintf("%s is %s", key, target),
+	}
+	if r := reachedRevision(*terraform, target); r != "" {
+		result.Message += " at revision " + r
+	}
+	if c.machineReadable() {
+		return c.printObject(out, result)
+	}
+	fmt.Fprintf(out, " ✔ %s\n", result.Message)
+	return nil
+}
+
+// waitFailure returns the condition reporting a failure of the given Terraform resource at the revision,
+// or nil. A failure at a previous revision is ignored, as the awaited revision may not be attempted yet.
+func waitFailure(terraform infrav1.Terraform, revision string) *metav1.Condition {
+	if !revisionMatches(terraform.Status.LastAttemptedRevision, revision) {
+		return nil
+	}
+	for i, condition := range terraform.Status.Conditions {
+		if condition.Status != metav1.ConditionUnknown && slices.Contains(waitFailureReasons, condition.Reason) {
+			return &terraform.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// hasReached returns true if the given Terraform resource reached the awaited state at the revision.
+func hasReached(terraform infrav1.Terraform, target string, revision string) bool {
+	ready := apimeta.IsStatusConditionTrue(terraform.Status.Conditions, meta.ReadyCondition)
+	plan := apimeta.FindStatusCondition(terraform.Status.Conditions, infrav1.ConditionTypePlan)
+	plannedNoChanges := plan != nil && plan.Reason == infrav1.PlannedNoChangesReason &&
+		revisionMatches(terraform.Status.LastPlannedRevision, revision)
+
+	switch target {
+	case WaitForPlanned:
+		return plan != nil && (plan.Reason == infrav1.PlannedWithChangesReason || plan.Reason == infrav1.PlannedNoChangesReason) &&
+			revisionMatches(terraform.Status.LastPlannedRevision, revision)
+	case WaitForApplied:
+		if terraform.Status.Plan.Pending != "" {
+			return false
+		}
+		// a plan without changes has nothing to apply
+		return plannedNoChanges ||
+			apimeta.IsStatusConditionTrue(terraform.Status.Conditions, infrav1.ConditionTypeApply) &&
+				revisionMatches(terraform.Status.LastAppliedRevision, revision)
+	case WaitForReady:
+		return ready && revisionMatches(terraform.Status.LastAttemptedRevision, revision)
+	case WaitForDriftFree:
+		return ready && !isDrifted(terraform) && terraform.Status.Plan.Pending == "" &&
+			revisionMatches(terraform.Status.LastAttemptedRevision, revision)
+	}
+	return false
+}
+
+// reachedRevision returns the revision the given Terraform resource reached the awaited state at.
+func reachedRevision(terraform infrav1.Terraform, target string) string {
+	switch target {
+	case WaitForPlanned:
+		return terraform.Status.LastPlannedRevision
+	case WaitForApplied:
+		if terraform.Status.LastPlannedRevision != "" && terraform.Status.LastPlannedRevision != terraform.Status.LastAppliedRevision {
+			if plan := apimeta.FindStatusCondition(terraform.Status.Conditions, infrav1.ConditionTypePlan); plan != nil &&
+				plan.Reason == infrav1.PlannedNoChangesReason {
+				return terraform.Status.LastPlannedRevision
+			}
+		}
+		return terraform.Status.LastAppliedRevision
+	}
+	return terraform.Status.LastAttemptedRevision
+}
+
+// revisionMatches returns true if the source revision, e.g. main@sha1:<sha>, is the awaited revision,
+// given either in full or as a prefix of the commit sha.
+func revisionMatches(actual, awaited string) bool {
+	if awaited == "" || actual == awaited {
+		return true
+	}
+	digest := actual
+	if i := strings.LastIndexAny(actual, ":/"); i != -1 {
+		digest = actual[i+1:]
+	}
+	return digest != "" && strings.HasPrefix(digest, awaited)
+}
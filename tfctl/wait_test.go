@@ -0,0 +1,237 @@
+package tfctl
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/fluxcd/pkg/apis/meta"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestWaitHasReached(t *testing.T) {
+	condition := func(conditionType string, status metav1.ConditionStatus, reason string) metav1.Condition {
+		return metav1.Condition{Type: conditionType, Status: status, Reason: reason}
+	}
+
+	applied := infrav1.TerraformStatus{
+		Conditions: []metav1.Condition{
+			condition(meta.ReadyCondition, metav1.ConditionTrue, infrav1.TFExecApplySucceedReason),
+			condition(infrav1.ConditionTypePlan, metav1.ConditionTrue, infrav1.PlannedWithChangesReason),
+			condition(infrav1.ConditionTypeApply, metav1.ConditionTrue, infrav1.TFExecApplySucceedReason),
+		},
+		LastAttemptedRevision: "main@sha1:1234abcd",
+		LastPlannedRevision:   "main@sha1:1234abcd",
+		LastAppliedRevision:   "main@sha1:1234abcd",
+	}
+	pending := infrav1.TerraformStatus{
+		Conditions: []metav1.Condition{
+			condition(meta.ReadyCondition, metav1.ConditionUnknown, infrav1.PlannedWithChangesReason),
+			condition(infrav1.ConditionTypePlan, metav1.ConditionTrue, infrav1.PlannedWithChangesReason),
+			condition(infrav1.ConditionTypeApply, metav1.ConditionTrue, infrav1.TFExecApplySucceedReason),
+		},
+		LastAttemptedRevision: "main@sha1:5678abcd",
+		LastPlannedRevision:   "main@sha1:5678abcd",
+		LastAppliedRevision:   "main@sha1:1234abcd",
+		Plan:                  infrav1.PlanStatus{Pending: "plan-main-5678abcd"},
+	}
+	noChanges := infrav1.TerraformStatus{
+		Conditions: []metav1.Condition{
+			condition(meta.ReadyCondition, metav1.ConditionTrue, infrav1.PlannedNoChangesReason),
+			condition(infrav1.ConditionTypePlan, metav1.ConditionFalse, infrav1.PlannedNoChangesReason),
+			condition(infrav1.ConditionTypeApply, metav1.ConditionTrue, infrav1.TFExecApplySucceedReason),
+		},
+		LastAttemptedRevision: "main@sha1:5678abcd",
+		LastPlannedRevision:   "main@sha1:5678abcd",
+		LastAppliedRevision:   "main@sha1:1234abcd",
+	}
+	drifted := infrav1.TerraformStatus{
+		Conditions: []metav1.Condition{
+			condition(meta.ReadyCondition, metav1.ConditionFalse, infrav1.DriftDetectedReason),
+		},
+		LastAttemptedRevision: "main@sha1:1234abcd",
+	}
+
+	tests := []struct {
+		name     string
+		status   infrav1.TerraformStatus
+		target   string
+		revision string
+		reached  bool
+	}{
+		{name: "applied", status: applied, target: WaitForApplied, reached: true},
+		{name: "applied at revision", status: applied, target: WaitForApplied, revision: "1234", reached: true},
+		{name: "applied at full revision", status: applied, target: WaitForApplied, revision: "main@sha1:1234abcd", reached: true},
+		{name: "applied at another revision", status: applied, target: WaitForApplied, revision: "5678"},
+		{name: "applied with a pending plan", status: pending, target: WaitForApplied},
+		{name: "applied without changes", status: noChanges, target: WaitForApplied, revision: "5678", reached: true},
+		{name: "planned", status: pending, target: WaitForPlanned, revision: "5678", reached: true},
+		{name: "planned at another revision", status: pending, target: WaitForPlanned, revision: "9999"},
+		{name: "ready", status: applied, target: WaitForReady, revision: "1234", reached: true},
+		{name: "ready with a pending plan", status: pending, target: WaitForReady},
+		{name: "drift-free", status: applied, target: WaitForDriftFree, reached: true},
+		{name: "drift-free when drifted", status: drifted, target: WaitForDriftFree},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(hasReached(infrav1.Terraform{Status: tt.status}, tt.target, tt.revision)).To(Equal(tt.reached))
+		})
+	}
+}
+
+func TestWaitFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	terraform := infrav1.Terraform{
+		Status: infrav1.TerraformStatus{
+			Conditions: []metav1.Condition{
+				{Type: meta.ReadyCondition, Status: metav1.ConditionFalse, Reason: infrav1.TFExecPlanFailedReason, Message: "error running Plan"},
+			},
+			LastAttemptedRevision: "main@sha1:1234abcd",
+		},
+	}
+	g.Expect(waitFailure(terraform, "")).To(HaveField("Reason", infrav1.TFExecPlanFailedReason))
+	g.Expect(waitFailure(terraform, "1234")).ToNot(BeNil())
+	// a failure of a previous revision is not the failure of the awaited revision
+	g.Expect(waitFailure(terraform, "5678")).To(BeNil())
+
+	terraform.Status.Conditions = []metav1.Condition{
+		{Type: meta.ReadyCondition, Status: metav1.ConditionTrue, Reason: infrav1.TFExecApplySucceedReason},
+		{Type: meta.StalledCondition, Status: metav1.ConditionTrue, Reason: infrav1.RetryLimitReachedReason},
+	}
+	g.Expect(waitFailure(terraform, "")).To(HaveField("Reason", infrav1.RetryLimitReachedReason))
+
+	terraform.Status.Conditions = []metav1.Condition{
+		{Type: meta.ReadyCondition, Status: metav1.ConditionUnknown, Reason: meta.ProgressingReason},
+	}
+	g.Expect(waitFailure(terraform, "")).To(BeNil())
+
+	// a failed post-apply webhook leaves the object applied and ready
+	terraform.Status.Conditions = []metav1.Condition{
+		{Type: meta.ReadyCondition, Status: metav1.ConditionTrue, Reason: infrav1.TFExecApplySucceedReason},
+		{Type: infrav1.ConditionTypePostApplyWebhook, Status: metav1.ConditionFalse, Reason: infrav1.PostApplyWebhookFailedReason},
+	}
+	g.Expect(waitFailure(terraform, "")).To(BeNil())
+}
+
+func TestWait(t *testing.T) {
+	g := NewWithT(t)
+
+	defaultInterval := waitPollInterval
+	waitPollInterval = 10 * time.Millisecond
+	defer func() { waitPollInterval = defaultInterval }()
+
+	scheme := runtime.NewScheme()
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	terraform := &infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "dev", Generation: 1},
+		Status: infrav1.TerraformStatus{
+			ObservedGeneration: 1,
+			Conditions: []metav1.Condition{
+				{Type: meta.ReadyCondition, Status: metav1.ConditionTrue, Reason: infrav1.TFExecApplySucceedReason, Message: "Applied successfully"},
+				{Type: infrav1.ConditionTypeApply, Status: metav1.ConditionTrue, Reason: infrav1.TFExecApplySucceedReason, Message: "Applied successfully"},
+			},
+			LastAttemptedRevision: "main@sha1:1234abcd",
+			LastAppliedRevision:   "main@sha1:1234abcd",
+		},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(terraform).Build()
+	cli := &CLI{client: kubeClient, namespace: "dev"}
+
+	var out bytes.Buffer
+	g.Expect(cli.Wait(context.TODO(), &out, "app", WaitForApplied, "1234", time.Second)).To(Succeed())
+	g.Expect(out.String()).To(ContainSubstring("Waiting for dev/app to be applied at revision 1234"))
+	g.Expect(out.String()).To(ContainSubstring("Ready True (TerraformAppliedSucceed): Applied successfully"))
+	g.Expect(out.String()).To(ContainSubstring("dev/app is applied at revision main@sha1:1234abcd"))
+
+	out.Reset()
+	err := cli.Wait(context.TODO(), &out, "app", WaitForApplied, "5678", 100*time.Millisecond)
+	g.Expect(err).To(MatchError("timed out waiting for dev/app to be applied at revision 5678"))
+
+	out.Reset()
+	g.Expect(cli.Wait(context.TODO(), &out, "app", "healthy", "", time.Second)).To(MatchError(ContainSubstring(`invalid --for "healthy"`)))
+
+	// failing fast on a failed health check
+	g.Expect(kubeClient.Get(context.TODO(), client.ObjectKeyFromObject(terraform), terraform)).To(Succeed())
+	terraform.Status.Conditions = append(terraform.Status.Conditions, metav1.Condition{
+		Type: infrav1.ConditionTypeHealthCheck, Status: metav1.ConditionFalse, Reason: infrav1.HealthChecksFailedReason, Message: "health check failed",
+	})
+	g.Expect(kubeClient.Update(context.TODO(), terraform)).To(Succeed())
+	out.Reset()
+	err = cli.Wait(context.TODO(), &out, "app", WaitForReady, "", time.Minute)
+	g.Expect(err).To(MatchError("dev/app failed with HealthChecksFailed: health check failed"))
+
+	// printing the result as JSON
+	terraform.Status.Conditions = terraform.Status.Conditions[:2]
+	g.Expect(kubeClient.Update(context.TODO(), terraform)).To(Succeed())
+	out.Reset()
+	cli.output = OutputJSON
+	g.Expect(cli.Wait(context.TODO(), &out, "app", WaitForReady, "", time.Second)).To(Succeed())
+	g.Expect(out.String()).To(MatchJSON(`{"action":"wait","namespace":"dev","name":"app","message":"dev/app is ready at revision main@sha1:1234abcd"}`))
+}
+
+func TestWaitGetErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	defaultInterval := waitPollInterval
+	waitPollInterval = 10 * time.Millisecond
+	defer func() { waitPollInterval = defaultInterval }()
+
+	scheme := runtime.NewScheme()
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	terraform := &infrav1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "dev", Generation: 1},
+		Status: infrav1.TerraformStatus{
+			ObservedGeneration: 1,
+			Conditions: []metav1.Condition{
+				{Type: meta.ReadyCondition, Status: metav1.ConditionTrue, Reason: infrav1.TFExecApplySucceedReason, Message: "Applied successfully"},
+			},
+			LastAttemptedRevision: "main@sha1:1234abcd",
+		},
+	}
+	resource := schema.GroupResource{Group: infrav1.GroupVersion.Group, Resource: "terraforms"}
+
+	// failing fast on a missing resource
+	cli := &CLI{client: fake.NewClientBuilder().WithScheme(scheme).Build(), namespace: "dev"}
+	var out bytes.Buffer
+	err := cli.Wait(context.TODO(), &out, "app", WaitForReady, "", time.Minute)
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+	// failing fast on a forbidden resource
+	cli.client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(terraform.DeepCopy()).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			return apierrors.NewForbidden(resource, key.Name, nil)
+		},
+	}).Build()
+	out.Reset()
+	err = cli.Wait(context.TODO(), &out, "app", WaitForReady, "", time.Minute)
+	g.Expect(apierrors.IsForbidden(err)).To(BeTrue())
+
+	// retrying a transient error
+	failures := 2
+	cli.client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(terraform.DeepCopy()).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if failures > 0 {
+				failures--
+				return apierrors.NewServiceUnavailable("try again later")
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+	}).Build()
+	out.Reset()
+	g.Expect(cli.Wait(context.TODO(), &out, "app", WaitForReady, "", time.Second)).To(Succeed())
+	g.Expect(failures).To(BeZero())
+}
@@ -0,0 +1,502 @@
+package tfctl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/fluxcd/pkg/apis/meta"
+	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	watchRefreshInterval = 2 * time.Second
+	watchLogRetry        = 5 * time.Second
+)
+
+const (
+	// watchLogLines is the number of runner log lines kept for the selected resource.
+	watchLogLines = 200
+
+	watchHelp          = "↑/↓ select  r reconcile  s suspend  u resume  a approve  q quit"
+	watchDefaultWidth  = 120
+	watchDefaultHeight = 40
+	watchMinLogsHeight = 5
+	watchHeaderLines   = 3
+	watchFooterLines   = 2
+
+	ansiClearScreen    = "\x1b[H\x1b[2J"
+	ansiEnterAltScreen = "\x1b[?1049h\x1b[?25l"
+	ansiLeaveAltScreen = "\x1b[?25h\x1b[?1049l"
+	ansiReverse        = "\x1b[7m"
+	ansiBold           = "\x1b[1m"
+	ansiReset          = "\x1b[0m"
+
+	keyCtrlC = "\x03"
+	keyUp    = "\x1b[A"
+	keyDown  = "\x1b[B"
+
+	phaseSuspended        = "suspended"
+	phaseInitializing     = "initializing"
+	phasePlanning         = "planning"
+	phaseApplying         = "applying"
+	phaseAwaitingApproval = "awaiting approval"
+)
+
+// podLogStreamer streams the logs of a pod.
+type podLogStreamer func(ctx context.Context, namespace, name string) (io.ReadCloser, error)
+
+// watcher is the state of the watch dashboard.
+type watcher struct {
+	cli           *CLI
+	allNamespaces bool
+	streamLogs    podLogStreamer
+
+	mu        sync.Mutex
+	items     []infrav1.Terraform
+	selected  client.ObjectKey
+	logs      []string
+	status    string
+	listError error
+}
+
+// Watch shows a terminal dashboard of the Terraform resources in the namespace, or across all namespaces,
+// with the phase, pending plan, drift and last applied revision of each resource, and the runner logs of
+// the selected resource. The selected resource can be reconciled, suspended, resumed and approved.
+func (c *CLI) Watch(ctx context.Context, in *os.File, out io.Writer, allNamespaces bool) error {
+	if c.machineReadable() {
+		return fmt.Errorf("tfctl watch does not support --output")
+	}
+	if !term.IsTerminal(int(in.Fd())) {
+		return fmt.Errorf("tfctl watch requires a terminal")
+	}
+
+	clientset, err := kubernetes.NewForConfig(c.restConfig)
+	if err != nil {
+		return err
+	}
+
+	w := &watcher{
+		cli:           c,
+		allNamespaces: allNamespaces,
+		streamLogs: func(ctx context.Context, namespace, name string) (io.ReadCloser, error) {
+			tail := int64(watchLogLines)
+			return clientset.CoreV1().Pods(namespace).GetLogs(name, &corev1.PodLogOptions{Follow: true, TailLines: &tail}).Stream(ctx)
+		},
+	}
+
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(out, ansiEnterAltScreen)
+	defer func() {
+		fmt.Fprint(out, ansiLeaveAltScreen)
+		_ = term.Restore(int(in.Fd()), state)
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	keys := make(chan string)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := in.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			select {
+			case keys <- string(buf[:n]):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	redraw := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case redraw <- struct{}{}:
+		default:
+		}
+	}
+
+	var cancelLogs context.CancelFunc = func() {}
+	defer func() { cancelLogs() }()
+	followSelected := func() {
+		cancelLogs()
+		var logsCtx context.Context
+		logsCtx, cancelLogs = context.WithCancel(ctx)
+		w.mu.Lock()
+		w.logs = nil
+		selected := w.selected
+		w.mu.Unlock()
+		if selected.Name != "" {
+			go w.followRunnerLogs(logsCtx, selected, notify)
+		}
+	}
+
+	ticker := time.NewTicker(watchRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		previous := w.selectedKey()
+		w.refresh(ctx)
+		if w.selectedKey() != previous {
+			followSelected()
+		}
+
+		width, height, err := term.GetSize(int(in.Fd()))
+		if err != nil {
+			width, height = watchDefaultWidth, watchDefaultHeight
+		}
+		fmt.Fprint(out, strings.ReplaceAll(w.render(width, height), "\n", "\r\n"))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-redraw:
+		case key, ok := <-keys:
+			if !ok || key == "q" || key == keyCtrlC {
+				return nil
+			}
+			if key == "a" {
+				// leave the dashboard to review the plan, and confirm its approval
+				fmt.Fprint(out, ansiLeaveAltScreen)
+				_ = term.Restore(int(in.Fd()), state)
+				w.approve(ctx, out, &keyReader{keys: keys})
+				fmt.Fprint(out, "\n Press enter to return to the dashboard")
+				_, _ = bufio.NewReader(&keyReader{keys: keys}).ReadString('\n')
+				if _, err := term.MakeRaw(int(in.Fd())); err != nil {
+					return err
+				}
+				fmt.Fprint(out, ansiEnterAltScreen)
+				continue
+			}
+			w.handleKey(key)
+		}
+	}
+}
+
+// refresh lists the Terraform resources, and keeps the selection on the same resource.
+func (w *watcher) refresh(ctx context.Context) {
+	opts := []client.ListOption{}
+	if !w.allNamespaces {
+		opts = append(opts, client.InNamespace(w.cli.namespace))
+	}
+	terraformList := &infrav1.TerraformList{}
+	err := w.cli.client.List(ctx, terraformList, opts...)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.listError = err
+	if err != nil {
+		return
+	}
+
+	items := terraformList.Items
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Namespace != items[j].Namespace {
+			return items[i].Namespace < items[j].Namespace
+		}
+		return items[i].Name < items[j].Name
+	})
+	w.items = items
+
+	if w.indexOf(w.selected) == -1 {
+		w.selected = client.ObjectKey{}
+		if len(items) > 0 {
+			w.selected = client.ObjectKeyFromObject(&items[0])
+		}
+	}
+}
+
+func (w *watcher) indexOf(key client.ObjectKey) int {
+	for i := range w.items {
+		if client.ObjectKeyFromObject(&w.items[i]) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (w *watcher) selectedKey() client.ObjectKey {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.selected
+}
+
+// handleKey moves the selection, or runs the action of a shortcut on the selected resource.
+func (w *watcher) handleKey(key string) {
+	w.mu.Lock()
+	i := w.indexOf(w.selected)
+	switch key {
+	case keyUp, "k":
+		if i > 0 {
+			w.selected = client.ObjectKeyFromObject(&w.items[i-1])
+		}
+	case keyDown, "j":
+		if i >= 0 && i < len(w.items)-1 {
+			w.selected = client.ObjectKeyFromObject(&w.items[i+1])
+		}
+	}
+	selected := w.selected
+	w.mu.Unlock()
+
+	if selected.Name == "" {
+		return
+	}
+
+	// the CLI methods act on a resource of the namespace of the CLI
+	cli := *w.cli
+	cli.namespace = selected.Namespace
+
+	out := &bytes.Buffer{}
+	var err error
+	switch key {
+	case "r":
+		err = cli.Reconcile(out, selected.Name)
+	case "s":
+		err = cli.Suspend(out, selected.Name)
+	case "u":
+		err = cli.Resume(out, selected.Name)
+	default:
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.status = fmt.Sprintf(" %s/%s: %s", selected.Namespace, selected.Name, err)
+		return
+	}
+	w.status = strings.TrimRight(out.String(), "\n")
+}
+
+// approve reviews and approves the pending plan of the selected resource, in-cluster.
+func (w *watcher) approve(ctx context.Context, out io.Writer, in io.Reader) {
+	selected := w.selectedKey()
+	if selected.Name == "" {
+		return
+	}
+
+	cli := *w.cli
+	cli.namespace = selected.Namespace
+	fmt.Fprint(out, ansiClearScreen)
+	if err := cli.ApprovePlanInCluster(ctx, out, in, selected.Name); err != nil {
+		fmt.Fprintf(out, " %s\n", err)
+	}
+}
+
+// followRunnerLogs keeps the last lines of the logs of the runner of the selected resource,
+// waiting for the runner pod when it is not running.
+func (w *watcher) followRunnerLogs(ctx context.Context, key client.ObjectKey, notify func()) {
+	pod := fmt.Sprintf("%s-tf-runner", key.Name)
+	for ctx.Err() == nil {
+		stream, err := w.streamLogs(ctx, key.Namespace, pod)
+		if err == nil {
+			scanner := bufio.NewScanner(stream)
+			for scanner.Scan() {
+				w.appendLog(scanner.Text())
+				notify()
+			}
+			stream.Close()
+		} else if !apierrors.IsNotFound(err) && ctx.Err() == nil {
+			w.appendLog(fmt.Sprintf("unable to get the runner logs: %s", err))
+			notify()
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(watchLogRetry):
+		}
+	}
+}
+
+func (w *watcher) appendLog(line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.logs = append(w.logs, line)
+	if len(w.logs) > watchLogLines {
+		w.logs = w.logs[len(w.logs)-watchLogLines:]
+	}
+}
+
+// render draws the dashboard in a screen of the given size.
+func (w *watcher) render(width, height int) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString(ansiClearScreen)
+	scope := fmt.Sprintf("namespace %s", w.cli.namespace)
+	if w.allNamespaces {
+		scope = "all namespaces"
+	}
+	fmt.Fprintf(&b, "%s tfctl watch - %s - %s%s\n\n", ansiBold, scope, time.Now().Format(time.TimeOnly), ansiReset)
+
+	tableHeight := max(height-watchHeaderLines-watchFooterLines-watchMinLogsHeight-2, 1)
+	first := 0
+	if i := w.indexOf(w.selected); i >= tableHeight {
+		first = i - tableHeight + 1
+	}
+
+	rows := [][]string{{"NAMESPACE", "NAME", "PHASE", "PENDING PLAN", "DRIFT", "LAST APPLIED REVISION"}}
+	for _, terraform := range w.items {
+		pending := terraform.Status.Plan.Pending
+		if pending == "" {
+			pending = "-"
+		}
+		revision := terraform.Status.LastAppliedRevision
+		if revision == "" {
+			revision = "-"
+		}
+		rows = append(rows, []string{
+			terraform.Namespace,
+			terraform.Name,
+			terraformPhase(terraform),
+			pending,
+			fmt.Sprintf("%v", isDrifted(terraform)),
+			revision,
+		})
+	}
+	lines := formatColumns(rows)
+
+	fmt.Fprintln(&b, truncate(lines[0], width))
+	switch {
+	case w.listError != nil:
+		fmt.Fprintf(&b, " unable to list Terraform resources: %s\n", w.listError)
+	case len(w.items) == 0:
+		fmt.Fprintln(&b, " No resources found")
+	}
+	for i := first; i < len(w.items) && i < first+tableHeight; i++ {
+		line := truncate(lines[i+1], width)
+		if client.ObjectKeyFromObject(&w.items[i]) == w.selected {
+			line = ansiReverse + line + ansiReset
+		}
+		fmt.Fprintln(&b, line)
+	}
+
+	logsHeight := max(height-watchHeaderLines-watchFooterLines-tableHeight-2, watchMinLogsHeight)
+	fmt.Fprintf(&b, "\n%s Runner logs of %s%s\n", ansiBold, w.selected, ansiReset)
+	logs := w.logs
+	if len(logs) > logsHeight {
+		logs = logs[len(logs)-logsHeight:]
+	}
+	if len(logs) == 0 && w.selected.Name != "" {
+		fmt.Fprintln(&b, " runner pod is not running")
+	}
+	for _, line := range logs {
+		fmt.Fprintln(&b, truncate(line, width))
+	}
+
+	fmt.Fprintf(&b, "\n%s\n%s", truncate(w.status, width), truncate(watchHelp, width))
+	return b.String()
+}
+
+// terraformPhase returns the phase of a Terraform resource, from its readiness.
+func terraformPhase(terraform infrav1.Terraform) string {
+	if terraform.Spec.Suspend {
+		return phaseSuspended
+	}
+
+	ready := apimeta.FindStatusCondition(terraform.Status.Conditions, meta.ReadyCondition)
+	if ready == nil {
+		return phaseInitializing
+	}
+	if ready.Reason == meta.ProgressingReason {
+		switch ready.Message {
+		case "Initializing":
+			return phaseInitializing
+		case "Terraform Planning":
+			return phasePlanning
+		case "Applying":
+			return phaseApplying
+		}
+		return strings.ToLower(ready.Message)
+	}
+	if terraform.Status.Plan.Pending != "" && ready.Status != "True" {
+		return phaseAwaitingApproval
+	}
+	switch ready.Status {
+	case "True":
+		return "ready"
+	case "False":
+		return "failed: " + ready.Reason
+	}
+	return strings.ToLower(ready.Reason)
+}
+
+// isDrifted returns true if drift was detected on a Terraform resource, and is not corrected yet.
+func isDrifted(terraform infrav1.Terraform) bool {
+	if terraform.Status.DriftReportConfigMapName != "" {
+		return true
+	}
+	ready := apimeta.FindStatusCondition(terraform.Status.Conditions, meta.ReadyCondition)
+	return ready != nil && ready.Reason == infrav1.DriftDetectedReason
+}
+
+// formatColumns aligns the cells of the rows in columns.
+func formatColumns(rows [][]string) []string {
+	widths := []int{}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		var b strings.Builder
+		for i, cell := range row {
+			fmt.Fprintf(&b, " %-*s ", widths[i], cell)
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+	}
+	return lines
+}
+
+func truncate(line string, width int) string {
+	if width <= 0 || len([]rune(line)) <= width {
+		return line
+	}
+	return string([]rune(line)[:width])
+}
+
+// keyReader reads the keys typed while the dashboard is left, to answer prompts.
+type keyReader struct {
+	keys    <-chan string
+	pending []byte
+}
+
+func (r *keyReader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		key, ok := <-r.keys
+		if !ok {
+			return 0, io.EOF
+		}
+		r.pending = []byte(key)
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,165 @@
+package tfctl
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	infrav1 "github.com/flux-iac/tofu-controller/api/v1alpha2"
+	"github.com/fluxcd/pkg/apis/meta"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTerraformPhase(t *testing.T) {
+	ready := func(status metav1.ConditionStatus, reason, message string) []metav1.Condition {
+		return []metav1.Condition{{Type: meta.ReadyCondition, Status: status, Reason: reason, Message: message}}
+	}
+
+	tests := []struct {
+		name      string
+		terraform infrav1.Terraform
+		phase     string
+		drifted   bool
+	}{
+		{
+			name:  "new",
+			phase: phaseInitializing,
+		},
+		{
+			name: "suspended",
+			terraform: infrav1.Terraform{
+				Spec:   infrav1.TerraformSpec{Suspend: true},
+				Status: infrav1.TerraformStatus{Conditions: ready(metav1.ConditionUnknown, meta.ProgressingReason, "Applying")},
+			},
+			phase: phaseSuspended,
+		},
+		{
+			name:      "planning",
+			terraform: infrav1.Terraform{Status: infrav1.TerraformStatus{Conditions: ready(metav1.ConditionUnknown, meta.ProgressingReason, "Terraform Planning")}},
+			phase:     phasePlanning,
+		},
+		{
+			name:      "applying",
+			terraform: infrav1.Terraform{Status: infrav1.TerraformStatus{Conditions: ready(metav1.ConditionUnknown, meta.ProgressingReason, "Applying")}},
+			phase:     phaseApplying,
+		},
+		{
+			name: "awaiting approval",
+			terraform: infrav1.Terraform{Status: infrav1.TerraformStatus{
+				Conditions: ready(metav1.ConditionUnknown, "TerraformPlannedWithChanges", "Plan generated"),
+				Plan:       infrav1.PlanStatus{Pending: "plan-main-1234"},
+			}},
+			phase: phaseAwaitingApproval,
+		},
+		{
+			name:      "ready",
+			terraform: infrav1.Terraform{Status: infrav1.TerraformStatus{Conditions: ready(metav1.ConditionTrue, infrav1.TFExecApplySucceedReason, "Applied")}},
+			phase:     "ready",
+		},
+		{
+			name:      "drifted",
+			terraform: infrav1.Terraform{Status: infrav1.TerraformStatus{Conditions: ready(metav1.ConditionFalse, infrav1.DriftDetectedReason, "Drift detected")}},
+			phase:     "failed: " + infrav1.DriftDetectedReason,
+			drifted:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(terraformPhase(tt.terraform)).To(Equal(tt.phase))
+			g.Expect(isDrifted(tt.terraform)).To(Equal(tt.drifted))
+		})
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&infrav1.Terraform{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "dev"},
+			Status: infrav1.TerraformStatus{
+				LastAppliedRevision: "main@sha1:1234",
+				Plan:                infrav1.PlanStatus{Pending: "plan-main-5678"},
+			},
+		},
+		&infrav1.Terraform{
+			ObjectMeta: metav1.ObjectMeta{Name: "network", Namespace: "prod"},
+		},
+	).Build()
+
+	w := &watcher{
+		cli:           &CLI{client: kubeClient, namespace: "dev"},
+		allNamespaces: true,
+		streamLogs: func(ctx context.Context, namespace, name string) (io.ReadCloser, error) {
+			if name != "app-tf-runner" {
+				return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, name)
+			}
+			return io.NopCloser(strings.NewReader("starting the runner\nplanning\n")), nil
+		},
+	}
+
+	// listing the resources across namespaces, and selecting the first one
+	w.refresh(context.TODO())
+	g.Expect(w.selectedKey()).To(Equal(client.ObjectKey{Namespace: "dev", Name: "app"}))
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan struct{})
+	go func() {
+		w.followRunnerLogs(ctx, w.selectedKey(), func() {})
+		close(done)
+	}()
+	g.Eventually(func() []string {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return append([]string{}, w.logs...)
+	}, time.Second).Should(Equal([]string{"starting the runner", "planning"}))
+	cancel()
+	<-done
+
+	screen := w.render(200, 30)
+	g.Expect(screen).To(ContainSubstring("all namespaces"))
+	g.Expect(screen).To(MatchRegexp(`dev\s+app\s+initializing\s+plan-main-5678\s+false\s+main@sha1:1234`))
+	g.Expect(screen).To(MatchRegexp(`prod\s+network\s+initializing\s+-\s+false\s+-`))
+	g.Expect(screen).To(ContainSubstring("Runner logs of dev/app"))
+	g.Expect(screen).To(ContainSubstring("planning"))
+
+	// suspending the selected resource, in its own namespace
+	w.handleKey("j")
+	g.Expect(w.selectedKey()).To(Equal(client.ObjectKey{Namespace: "prod", Name: "network"}))
+	w.handleKey("j")
+	g.Expect(w.selectedKey()).To(Equal(client.ObjectKey{Namespace: "prod", Name: "network"}))
+	w.handleKey("s")
+	g.Expect(w.status).To(ContainSubstring("Reconciliation suspended for prod/network"))
+
+	suspended := &infrav1.Terraform{}
+	g.Expect(kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: "prod", Name: "network"}, suspended)).To(Succeed())
+	g.Expect(suspended.Spec.Suspend).To(BeTrue())
+
+	app := &infrav1.Terraform{}
+	g.Expect(kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: "dev", Name: "app"}, app)).To(Succeed())
+	g.Expect(app.Spec.Suspend).To(BeFalse())
+
+	w.refresh(context.TODO())
+	g.Expect(w.render(200, 30)).To(MatchRegexp(`prod\s+network\s+suspended`))
+
+	// keeping the selection in range
+	w.handleKey("k")
+	w.handleKey(keyUp)
+	g.Expect(w.selectedKey()).To(Equal(client.ObjectKey{Namespace: "dev", Name: "app"}))
+}